@@ -0,0 +1,129 @@
+// proto-mysql-drift compares tables in a database with the tables protoc-gen-mysql generates.
+//
+//	protoc -I. -o schema.pb --include_imports test.proto
+//	mysql -N -r -e "SHOW CREATE TABLE User" mydb > dump.sql
+//	proto-mysql-drift -descriptor_set schema.pb -dump dump.sql test.proto
+//
+// differences are printed one per line, and the exit status is 1 if there is any.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+
+	"github.com/Mojashi/proto-mysql/dep"
+	"github.com/Mojashi/proto-mysql/gensql"
+	descriptor "github.com/golang/protobuf/protoc-gen-go/descriptor"
+	plugin "github.com/golang/protobuf/protoc-gen-go/plugin"
+	"google.golang.org/protobuf/proto"
+)
+
+var (
+	descriptorSet = flag.String("descriptor_set", "", "FileDescriptorSet written by protoc -o --include_imports")
+	dump          = flag.String("dump", "", "text file of SHOW CREATE TABLE statements")
+	packageFlag   = flag.String("package", "", "package mapping, same as the package parameter of protoc-gen-mysql")
+	dialect       = flag.String("dialect", "", "mysql (default) or mariadb")
+	mysqlVersion  = flag.String("mysql_version", "", "target MySQL server version, same as the mysql_version parameter of protoc-gen-mysql")
+)
+
+func parseOptions() (gensql.Options, error) {
+	var opts gensql.Options
+	var err error
+	switch m := gensql.PackageMapping(*packageFlag); m {
+	case gensql.PackageMappingDatabase, gensql.PackageMappingPrefix, "":
+		opts.PackageMapping = m
+	default:
+		return opts, fmt.Errorf("unknown package mapping %s", *packageFlag)
+	}
+	if *dialect != "" {
+		if opts.Dialect, err = gensql.ParseDialect(*dialect); err != nil {
+			return opts, err
+		}
+	}
+	if *mysqlVersion != "" {
+		if opts.MySQLVersion, err = gensql.ParseMySQLVersion(*mysqlVersion); err != nil {
+			return opts, err
+		}
+	}
+	return opts, nil
+}
+
+// the set is analyzed in the same way as a request of protoc
+func readDescriptorSet(path string) (*plugin.CodeGeneratorRequest, error) {
+	buf, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var set descriptor.FileDescriptorSet
+	if err := proto.Unmarshal(buf, &set); err != nil {
+		return nil, fmt.Errorf("failed to parse descriptor set %s: %v", path, err)
+	}
+	return &plugin.CodeGeneratorRequest{ProtoFile: set.File}, nil
+}
+
+// tables generated for the files, as one schema
+func buildSchema(req *plugin.CodeGeneratorRequest, files []string, opts gensql.Options) (*gensql.Schema, error) {
+	ret := &gensql.Schema{}
+	for _, name := range files {
+		var f *descriptor.FileDescriptorProto
+		for _, pf := range req.ProtoFile {
+			if pf.GetName() == name {
+				f = pf
+			}
+		}
+		if f == nil {
+			return nil, fmt.Errorf("%s is not in the descriptor set", name)
+		}
+		s, err := gensql.BuildSchema(dep.AnalyzeDependency(req, f), f, opts)
+		if err != nil {
+			return nil, err
+		}
+		ret.Tables = append(ret.Tables, s.Tables...)
+	}
+	return ret, nil
+}
+
+func run() ([]gensql.Drift, error) {
+	flag.Parse()
+	if *descriptorSet == "" || *dump == "" || flag.NArg() == 0 {
+		flag.Usage()
+		return nil, fmt.Errorf("descriptor_set, dump and proto files are required")
+	}
+	opts, err := parseOptions()
+	if err != nil {
+		return nil, err
+	}
+	req, err := readDescriptorSet(*descriptorSet)
+	if err != nil {
+		return nil, err
+	}
+	expected, err := buildSchema(req, flag.Args(), opts)
+	if err != nil {
+		return nil, err
+	}
+	ddl, err := ioutil.ReadFile(*dump)
+	if err != nil {
+		return nil, err
+	}
+	actual, err := gensql.ParseCreateTables(string(ddl))
+	if err != nil {
+		return nil, err
+	}
+	return gensql.FindDrift(expected, actual, opts), nil
+}
+
+func main() {
+	drifts, err := run()
+	if err != nil {
+		log.Fatalln(err)
+	}
+	for _, d := range drifts {
+		fmt.Println(d)
+	}
+	if len(drifts) > 0 {
+		os.Exit(1)
+	}
+}
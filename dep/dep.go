@@ -7,6 +7,8 @@ import (
 	"github.com/golang/glog"
 	"github.com/golang/protobuf/protoc-gen-go/descriptor"
 	plugin "github.com/golang/protobuf/protoc-gen-go/plugin"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
 )
 
 type Path []string
@@ -22,7 +24,7 @@ type INameSpace interface {
 	PrintTree(depth int) string
 }
 
-//impl NameSpace
+// impl NameSpace
 type NameSpace struct {
 	childNameSpaces map[string]INameSpace
 	enums           map[string]Enum
@@ -81,7 +83,7 @@ func (ns *NameSpace) GetNameSpaces() map[string]INameSpace {
 	return ns.childNameSpaces
 }
 
-//package "foo.bar" -> getNameSpace([]string{"foo", "bar"})
+// package "foo.bar" -> getNameSpace([]string{"foo", "bar"})
 func (ns *NameSpace) GetNameSpace(name Path) *NameSpace {
 	if len(name) == 0 {
 		return ns
@@ -127,26 +129,65 @@ func (ns *NameSpace) PrintTree(depth int) string {
 	)
 }
 
-//impl NameSpace
+// impl NameSpace
 type Message struct {
 	NameSpace
-	message *descriptor.DescriptorProto
+	message       *descriptor.DescriptorProto
+	fieldComments map[string]string
 }
 
 func NewMessage(message *descriptor.DescriptorProto) Message {
+	return newMessage(message, nil, nil)
+}
+
+// path is the location of message in SourceCodeInfo. comments is keyed by pathKey
+func newMessage(message *descriptor.DescriptorProto, path []int32, comments map[string]string) Message {
 	ret := Message{
 		*NewNameSpace(),
 		message,
+		map[string]string{},
+	}
+	for i, field := range message.GetField() {
+		if c, ok := comments[pathKey(path, 2, int32(i))]; ok {
+			ret.fieldComments[field.GetName()] = c
+		}
 	}
 	for _, enum := range message.GetEnumType() {
 		ret.AddEnum(NewEnum(enum))
 	}
-	for _, message := range message.GetNestedType() {
-		ret.AddMessage(NewMessage(message))
+	for i, message := range message.GetNestedType() {
+		ret.AddMessage(newMessage(message, append(append([]int32{}, path...), 3, int32(i)), comments))
 	}
 	return ret
 }
 
+func (m Message) GetDescriptor() *descriptor.DescriptorProto { return m.message }
+
+// leading comment of the field, or trailing comment if there is none
+func (m Message) GetFieldComment(name string) string { return m.fieldComments[name] }
+
+func pathKey(path []int32, elems ...int32) string {
+	terms := []string{}
+	for _, p := range append(append([]int32{}, path...), elems...) {
+		terms = append(terms, fmt.Sprint(p))
+	}
+	return strings.Join(terms, ",")
+}
+
+func collectComments(f *descriptor.FileDescriptorProto) map[string]string {
+	comments := map[string]string{}
+	for _, loc := range f.GetSourceCodeInfo().GetLocation() {
+		c := strings.TrimSpace(loc.GetLeadingComments())
+		if c == "" {
+			c = strings.TrimSpace(loc.GetTrailingComments())
+		}
+		if c != "" {
+			comments[pathKey(loc.GetPath())] = c
+		}
+	}
+	return comments
+}
+
 type Enum struct {
 	enum *descriptor.EnumDescriptorProto
 }
@@ -158,6 +199,17 @@ func NewEnum(enum *descriptor.EnumDescriptorProto) Enum {
 }
 func (e Enum) GetEnum() *descriptor.EnumDescriptorProto { return e.enum }
 
+func analyzeFile(ns *NameSpace, f *descriptor.FileDescriptorProto) {
+	cns := ns.GetNameSpace(strings.Split(f.GetPackage(), "."))
+	comments := collectComments(f)
+	for i, message := range f.GetMessageType() {
+		cns.AddMessage(newMessage(message, []int32{4, int32(i)}, comments))
+	}
+	for _, enum := range f.GetEnumType() {
+		cns.AddEnum(NewEnum(enum))
+	}
+}
+
 func AnalyzeDependency(req *plugin.CodeGeneratorRequest, file *descriptor.FileDescriptorProto) INameSpace {
 	ns := NewNameSpace()
 
@@ -166,23 +218,47 @@ func AnalyzeDependency(req *plugin.CodeGeneratorRequest, file *descriptor.FileDe
 		files[f.GetName()] = f
 	}
 
-	analyzeFile := func(f *descriptor.FileDescriptorProto) {
-		cns := ns.GetNameSpace(strings.Split(f.GetPackage(), "."))
-		for _, message := range f.GetMessageType() {
-			cns.AddMessage(NewMessage(message))
-		}
-		for _, enum := range f.GetEnumType() {
-			cns.AddEnum(NewEnum(enum))
+	// imports of imports are analyzed too, as messages may refer to types declared in them
+	visited := map[string]bool{file.GetName(): true}
+	var analyzeImports func(file *descriptor.FileDescriptorProto)
+	analyzeImports = func(file *descriptor.FileDescriptorProto) {
+		for _, dep := range file.Dependency {
+			if visited[dep] {
+				continue
+			}
+			visited[dep] = true
+			if f, ok := files[dep]; !ok {
+				glog.Errorf("file %s not found", dep)
+			} else {
+				analyzeImports(f)
+				analyzeFile(ns, f)
+			}
 		}
 	}
+	analyzeImports(file)
+	analyzeFile(ns, file)
+	return ns
+}
 
-	for _, dep := range file.Dependency {
-		if f, ok := files[dep]; !ok {
-			glog.Errorf("filed %s not found", f)
-		} else {
-			analyzeFile(f)
+// same as AnalyzeDependency, but the file and its imports are taken from compiled-in descriptors.
+// e.g. protoregistry.GlobalFiles
+func AnalyzeFileDescriptor(file protoreflect.FileDescriptor) INameSpace {
+	ns := NewNameSpace()
+	visited := map[string]bool{file.Path(): true}
+	var analyzeImports func(file protoreflect.FileDescriptor)
+	analyzeImports = func(file protoreflect.FileDescriptor) {
+		imports := file.Imports()
+		for i := 0; i < imports.Len(); i++ {
+			f := imports.Get(i).FileDescriptor
+			if visited[f.Path()] {
+				continue
+			}
+			visited[f.Path()] = true
+			analyzeImports(f)
+			analyzeFile(ns, protodesc.ToFileDescriptorProto(f))
 		}
 	}
-	analyzeFile(file)
+	analyzeImports(file)
+	analyzeFile(ns, protodesc.ToFileDescriptorProto(file))
 	return ns
 }
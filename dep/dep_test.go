@@ -0,0 +1,91 @@
+package dep
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/golang/protobuf/protoc-gen-go/descriptor"
+	plugin "github.com/golang/protobuf/protoc-gen-go/plugin"
+	"google.golang.org/protobuf/encoding/prototext"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoregistry"
+)
+
+// user.proto imports status.proto, which imports gender.proto publicly
+var testFiles = []string{
+	`name: "gender.proto" package: "Baz" syntax: "proto3"
+	enum_type { name: "Gender" value { name: "MALE" number: 0 } }`,
+	`name: "status.proto" package: "Bar" syntax: "proto3" dependency: "gender.proto" public_dependency: 0
+	message_type { name: "Status" field { name: "gender" number: 1 type: TYPE_ENUM type_name: ".Baz.Gender" label: LABEL_OPTIONAL } }`,
+	`name: "user.proto" package: "Foo.Bar" syntax: "proto3" dependency: "status.proto"
+	message_type {
+		name: "User"
+		field { name: "status" number: 1 type: TYPE_MESSAGE type_name: ".Bar.Status" label: LABEL_OPTIONAL }
+		nested_type { name: "Nested" enum_type { name: "Kind" value { name: "A" number: 0 } } }
+	}`,
+	`name: "unused.proto" package: "Qux" syntax: "proto3"
+	message_type { name: "Unused" }`,
+}
+
+func parseTestFiles(t *testing.T) []*descriptor.FileDescriptorProto {
+	t.Helper()
+	ret := []*descriptor.FileDescriptorProto{}
+	for _, text := range testFiles {
+		f := &descriptor.FileDescriptorProto{}
+		if err := prototext.Unmarshal([]byte(text), f); err != nil {
+			t.Fatal(err)
+		}
+		ret = append(ret, f)
+	}
+	return ret
+}
+
+func TestAnalyze(t *testing.T) {
+	files := parseTestFiles(t)
+	registry := &protoregistry.Files{}
+	for _, f := range files {
+		fd, err := protodesc.NewFile(f, registry)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := registry.RegisterFile(fd); err != nil {
+			t.Fatal(err)
+		}
+	}
+	fd, err := registry.FindFileByPath("user.proto")
+	if err != nil {
+		t.Fatal(err)
+	}
+	namespaces := map[string]INameSpace{
+		"AnalyzeDependency":     AnalyzeDependency(&plugin.CodeGeneratorRequest{ProtoFile: files}, files[2]),
+		"AnalyzeFileDescriptor": AnalyzeFileDescriptor(fd),
+	}
+	tests := []struct {
+		name    string
+		message string
+		enum    string
+		found   bool
+	}{
+		{name: "message of the file", message: ".Foo.Bar.User", found: true},
+		{name: "nested enum", enum: ".Foo.Bar.User.Nested.Kind", found: true},
+		{name: "message of import", message: ".Bar.Status", found: true},
+		{name: "enum of import of import", enum: ".Baz.Gender", found: true},
+		{name: "file not imported", message: ".Qux.Unused"},
+		{name: "enum looked up as message", message: ".Baz.Gender"},
+	}
+	for analyze, ns := range namespaces {
+		for _, tt := range tests {
+			t.Run(analyze+"/"+tt.name, func(t *testing.T) {
+				var found bool
+				if tt.message != "" {
+					_, found = ns.GetMessage(strings.Split(tt.message, "."))
+				} else {
+					_, found = ns.GetEnum(strings.Split(tt.enum, "."))
+				}
+				if found != tt.found {
+					t.Errorf("found = %v, want %v", found, tt.found)
+				}
+			})
+		}
+	}
+}
@@ -0,0 +1,128 @@
+package gensql
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/Mojashi/proto-mysql/dep"
+	"github.com/golang/protobuf/protoc-gen-go/descriptor"
+)
+
+// field of BigQuery table schema JSON. https://cloud.google.com/bigquery/docs/schemas
+type BigQueryField struct {
+	Name        string          `json:"name"`
+	Type        string          `json:"type"`
+	Mode        string          `json:"mode"`
+	Description string          `json:"description,omitempty"`
+	Fields      []BigQueryField `json:"fields,omitempty"`
+}
+
+// INT64 is signed, so uint64 is widened to NUMERIC
+var bigQueryDataTypeMap = map[descriptor.FieldDescriptorProto_Type]string{
+	descriptor.FieldDescriptorProto_TYPE_DOUBLE:   "FLOAT",
+	descriptor.FieldDescriptorProto_TYPE_FLOAT:    "FLOAT",
+	descriptor.FieldDescriptorProto_TYPE_INT64:    "INTEGER",
+	descriptor.FieldDescriptorProto_TYPE_UINT64:   "NUMERIC",
+	descriptor.FieldDescriptorProto_TYPE_INT32:    "INTEGER",
+	descriptor.FieldDescriptorProto_TYPE_FIXED64:  "NUMERIC",
+	descriptor.FieldDescriptorProto_TYPE_FIXED32:  "INTEGER",
+	descriptor.FieldDescriptorProto_TYPE_BOOL:     "BOOLEAN",
+	descriptor.FieldDescriptorProto_TYPE_STRING:   "STRING",
+	descriptor.FieldDescriptorProto_TYPE_BYTES:    "BYTES",
+	descriptor.FieldDescriptorProto_TYPE_UINT32:   "INTEGER",
+	descriptor.FieldDescriptorProto_TYPE_ENUM:     "STRING",
+	descriptor.FieldDescriptorProto_TYPE_SFIXED32: "INTEGER",
+	descriptor.FieldDescriptorProto_TYPE_SFIXED64: "INTEGER",
+	descriptor.FieldDescriptorProto_TYPE_SINT32:   "INTEGER",
+	descriptor.FieldDescriptorProto_TYPE_SINT64:   "INTEGER",
+}
+
+const timestampTypeName = ".google.protobuf.Timestamp"
+
+func bigQueryFields(dep dep.INameSpace, typeName string, visiting map[string]bool) ([]BigQueryField, error) {
+	if visiting[typeName] {
+		return nil, fmt.Errorf("recursive message %s cannot be mapped to RECORD", typeName)
+	}
+	msg, ok := dep.GetMessage(strings.Split(typeName, "."))
+	if !ok {
+		return nil, fmt.Errorf("failed to find message %s", typeName)
+	}
+	visiting[typeName] = true
+	defer delete(visiting, typeName)
+
+	fields := make([]BigQueryField, 0, len(msg.GetDescriptor().GetField()))
+	for _, field := range msg.GetDescriptor().GetField() {
+		f := BigQueryField{
+			Name:        field.GetName(),
+			Mode:        "REQUIRED",
+			Description: msg.GetFieldComment(field.GetName()),
+		}
+		switch field.GetType() {
+		case descriptor.FieldDescriptorProto_TYPE_MESSAGE, descriptor.FieldDescriptorProto_TYPE_GROUP:
+			// singular message may be absent
+			f.Mode = "NULLABLE"
+			if field.GetTypeName() == timestampTypeName {
+				f.Type = "TIMESTAMP"
+				break
+			}
+			nested, err := bigQueryFields(dep, field.GetTypeName(), visiting)
+			if err != nil {
+				return nil, err
+			}
+			f.Type = "RECORD"
+			f.Fields = nested
+		default:
+			t, ok := bigQueryDataTypeMap[field.GetType()]
+			if !ok {
+				return nil, fmt.Errorf("failed to find type of %s", field.GetName())
+			}
+			f.Type = t
+		}
+		if field.GetProto3Optional() {
+			f.Mode = "NULLABLE"
+		}
+		if field.GetLabel() == descriptor.FieldDescriptorProto_LABEL_REPEATED {
+			f.Mode = "REPEATED"
+		}
+		fields = append(fields, f)
+	}
+	return fields, nil
+}
+
+// schema of the table generated for mt, including audit, soft-delete and PROTO_BINARY columns
+func GenBigQuerySchema(dep dep.INameSpace, f *descriptor.FileDescriptorProto, mt *descriptor.DescriptorProto) ([]BigQueryField, error) {
+	fields, err := bigQueryFields(dep, "."+strings.TrimPrefix(f.GetPackage()+"."+mt.GetName(), "."), map[string]bool{})
+	if err != nil {
+		return nil, err
+	}
+	if audit, ok := GetAuditColumns(mt); ok {
+		fields = append(fields,
+			BigQueryField{Name: audit.CreatedAt, Type: "TIMESTAMP", Mode: "REQUIRED"},
+			BigQueryField{Name: audit.UpdatedAt, Type: "TIMESTAMP", Mode: "REQUIRED"},
+			BigQueryField{Name: audit.RowVersion, Type: "INTEGER", Mode: "REQUIRED"},
+		)
+	}
+	if sd, ok := GetSoftDelete(mt); ok {
+		fields = append(fields, BigQueryField{Name: sd.Column, Type: "TIMESTAMP", Mode: "NULLABLE"})
+	}
+	fields = append(fields, BigQueryField{Name: "PROTO_BINARY", Type: "BYTES", Mode: "REQUIRED"})
+	return fields, nil
+}
+
+// return schema JSON for each table keyed by output file name. e.g. "test/test.proto.User.json"
+func GenBigQuerySchemas(dep dep.INameSpace, f *descriptor.FileDescriptorProto, opts Options) (map[string]string, error) {
+	ret := map[string]string{}
+	for _, mt := range f.MessageType {
+		fields, err := GenBigQuerySchema(dep, f, mt)
+		if err != nil {
+			return nil, err
+		}
+		buf, err := json.MarshalIndent(fields, "", "  ")
+		if err != nil {
+			return nil, err
+		}
+		ret[fmt.Sprintf("%s.%s.json", f.GetName(), GetTableName(f, mt, opts).Name)] = string(buf) + "\n"
+	}
+	return ret, nil
+}
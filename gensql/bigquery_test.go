@@ -0,0 +1,81 @@
+package gensql
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestGenBigQuerySchema(t *testing.T) {
+	friend := []BigQueryField{{Name: "name", Type: "STRING", Mode: "REQUIRED"}}
+	tests := []struct {
+		name   string
+		user   string
+		fields []BigQueryField
+		err    bool
+	}{
+		{
+			name: "scalars",
+			user: `
+				field { name: "id" number: 1 type: TYPE_UINT64 label: LABEL_OPTIONAL }
+				field { name: "page_number" number: 2 type: TYPE_INT32 label: LABEL_OPTIONAL proto3_optional: true oneof_index: 0 }
+				field { name: "gender" number: 3 type: TYPE_ENUM type_name: ".Foo.Gender" label: LABEL_OPTIONAL }
+				field { name: "tags" number: 4 type: TYPE_STRING label: LABEL_REPEATED }
+				oneof_decl { name: "_page_number" }`,
+			fields: []BigQueryField{
+				{Name: "id", Type: "NUMERIC", Mode: "REQUIRED"},
+				{Name: "page_number", Type: "INTEGER", Mode: "NULLABLE"},
+				{Name: "gender", Type: "STRING", Mode: "REQUIRED"},
+				{Name: "tags", Type: "STRING", Mode: "REPEATED"},
+				{Name: "PROTO_BINARY", Type: "BYTES", Mode: "REQUIRED"},
+			},
+		},
+		{
+			name: "messages",
+			user: `
+				field { name: "friend" number: 1 type: TYPE_MESSAGE type_name: ".Foo.Friend" label: LABEL_OPTIONAL }
+				field { name: "friends" number: 2 type: TYPE_MESSAGE type_name: ".Foo.Friend" label: LABEL_REPEATED }
+				field { name: "born_at" number: 3 type: TYPE_MESSAGE type_name: ".google.protobuf.Timestamp" label: LABEL_OPTIONAL }`,
+			fields: []BigQueryField{
+				{Name: "friend", Type: "RECORD", Mode: "NULLABLE", Fields: friend},
+				{Name: "friends", Type: "RECORD", Mode: "REPEATED", Fields: friend},
+				{Name: "born_at", Type: "TIMESTAMP", Mode: "NULLABLE"},
+				{Name: "PROTO_BINARY", Type: "BYTES", Mode: "REQUIRED"},
+			},
+		},
+		{
+			name: "audit and soft delete columns",
+			user: `
+				field { name: "id" number: 1 type: TYPE_INT64 label: LABEL_OPTIONAL }
+				options { [mySQLTable] { auditColumns {} softDelete { column: "removed_at" } } }`,
+			fields: []BigQueryField{
+				{Name: "id", Type: "INTEGER", Mode: "REQUIRED"},
+				{Name: "created_at", Type: "TIMESTAMP", Mode: "REQUIRED"},
+				{Name: "updated_at", Type: "TIMESTAMP", Mode: "REQUIRED"},
+				{Name: "row_version", Type: "INTEGER", Mode: "REQUIRED"},
+				{Name: "removed_at", Type: "TIMESTAMP", Mode: "NULLABLE"},
+				{Name: "PROTO_BINARY", Type: "BYTES", Mode: "REQUIRED"},
+			},
+		},
+		{
+			name: "recursive message",
+			user: `field { name: "parent" number: 1 type: TYPE_MESSAGE type_name: ".Foo.Node" label: LABEL_OPTIONAL }`,
+			err:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dep, f := parseMessages(t, `
+				message_type { name: "User" `+tt.user+` }
+				message_type { name: "Friend" field { name: "name" number: 1 type: TYPE_STRING label: LABEL_OPTIONAL } }
+				message_type { name: "Node" field { name: "parent" number: 1 type: TYPE_MESSAGE type_name: ".Foo.Node" label: LABEL_OPTIONAL } }
+				`+genderEnum)
+			fields, err := GenBigQuerySchema(dep, f, f.MessageType[0])
+			if (err != nil) != tt.err {
+				t.Fatalf("err = %v, want error %v", err, tt.err)
+			}
+			if !reflect.DeepEqual(fields, tt.fields) {
+				t.Errorf("fields = %#v, want %#v", fields, tt.fields)
+			}
+		})
+	}
+}
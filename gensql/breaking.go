@@ -0,0 +1,187 @@
+package gensql
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/Mojashi/proto-mysql/dep"
+	"github.com/golang/protobuf/protoc-gen-go/descriptor"
+)
+
+// change which corrupts or rejects rows stored with the baseline
+type BreakingChange struct {
+	Table  TableName
+	Column string
+	// full name of the proto field. empty for tables and columns without field
+	Field  string
+	Reason string
+}
+
+func (b BreakingChange) String() string {
+	where := b.Table.String()
+	if b.Column != "" {
+		where += "." + b.Column
+	}
+	if b.Field != "" {
+		where = fmt.Sprintf("%s (%s)", b.Field, where)
+	}
+	return fmt.Sprintf("%s: %s", where, b.Reason)
+}
+
+var integerBytes = map[string]int{
+	"TINYINT":   1,
+	"SMALLINT":  2,
+	"MEDIUMINT": 3,
+	"INT":       4,
+	"INTEGER":   4,
+	"BIGINT":    8,
+}
+
+// maximum length of string and binary types
+var lengthTypes = map[string]int{
+	"TINYTEXT":   255,
+	"TEXT":       65535,
+	"MEDIUMTEXT": 16777215,
+	"LONGTEXT":   4294967295,
+	"TINYBLOB":   255,
+	"BLOB":       65535,
+	"MEDIUMBLOB": 16777215,
+	"LONGBLOB":   4294967295,
+}
+
+func integerType(t MySQLDataTypeWithArgs) (int, bool, bool) {
+	name := strings.ToUpper(string(t.GetType()))
+	unsigned := strings.HasSuffix(name, " UNSIGNED")
+	bytes, ok := integerBytes[strings.TrimSuffix(name, " UNSIGNED")]
+	return bytes, unsigned, ok
+}
+
+func isBinaryType(name string) bool {
+	return strings.Contains(name, "BLOB") || strings.Contains(name, "BINARY")
+}
+
+// return maximum length and whether the type holds binary
+func lengthType(t MySQLDataTypeWithArgs) (int, bool, bool) {
+	name := strings.ToUpper(string(t.GetType()))
+	switch name {
+	case "CHAR", "VARCHAR", "BINARY", "VARBINARY":
+		if len(t.GetArgs()) != 1 {
+			return 0, false, false
+		}
+		n, err := strconv.Atoi(t.GetArgs()[0])
+		return n, isBinaryType(name), err == nil
+	}
+	n, ok := lengthTypes[name]
+	return n, isBinaryType(name), ok
+}
+
+// return why values of type o cannot be kept in type n. empty if they can
+func typeNarrowing(o, n MySQLDataTypeWithArgs) string {
+	if o.ToString() == n.ToString() {
+		return ""
+	}
+	if (o.GetType() == JSON) != (n.GetType() == JSON) {
+		return fmt.Sprintf("storage changes between scalar and JSON (%s -> %s), so stored values are reinterpreted", o.ToString(), n.ToString())
+	}
+	if o.GetType() == ENUM && n.GetType() == ENUM {
+		oldValues, newValues := o.GetArgs(), n.GetArgs()
+		for i, v := range oldValues {
+			if i >= len(newValues) || newValues[i] != v {
+				return fmt.Sprintf("ENUM values are removed or reordered (%s -> %s). rows holding removed values are rejected, and stored indexes change meaning",
+					strings.Join(oldValues, ","), strings.Join(newValues, ","))
+			}
+		}
+		return ""
+	}
+	if ob, ou, ok := integerType(o); ok {
+		if nb, nu, ok := integerType(n); ok {
+			switch {
+			case !ou && nu:
+				return fmt.Sprintf("%s -> %s. negative values are out of range", o.ToString(), n.ToString())
+			case nb < ob || (ou && !nu && nb == ob):
+				return fmt.Sprintf("%s -> %s narrows the range. out of range values are rejected", o.ToString(), n.ToString())
+			}
+			return ""
+		}
+	}
+	if o.GetType() == DOUBLE && n.GetType() == FLOAT {
+		return "DOUBLE -> FLOAT loses precision of stored values"
+	}
+	if o.GetType() == FLOAT && n.GetType() == DOUBLE {
+		return ""
+	}
+	if ol, obin, ok := lengthType(o); ok {
+		if nl, nbin, ok := lengthType(n); ok && obin == nbin {
+			if nl < ol {
+				return fmt.Sprintf("%s -> %s. values longer than %d are truncated or rejected", o.ToString(), n.ToString(), nl)
+			}
+			return ""
+		}
+	}
+	return fmt.Sprintf("type changes from %s to %s, and stored values are converted", o.ToString(), n.ToString())
+}
+
+func columnBreakingChange(c *Change) (string, bool) {
+	o, n := c.OldColumn, c.NewColumn
+	switch c.Kind {
+	case ChangeDropColumn:
+		if o.Generated != "" {
+			return "", false
+		}
+		return "the column is dropped with its values", true
+	case ChangeModifyColumn, ChangeChangeColumn:
+		// values of proto enums are compared by number, as renamed values are updated
+		if o.Enum != nil && n.Enum != nil && o.Type.GetType() == ENUM && n.Type.GetType() == ENUM {
+			if removed := removedEnumValues(o, n); len(removed) > 0 {
+				return fmt.Sprintf("ENUM values %s are removed, so rows holding them are rejected", strings.Join(removed, ",")), true
+			}
+			if renumbered := renumberedEnumValues(o, n); len(renumbered) > 0 {
+				return fmt.Sprintf("ENUM values %s are renumbered, so stored messages read them as other values", strings.Join(renumbered, ",")), true
+			}
+			if !enumOrderKept(o, n) {
+				return fmt.Sprintf("ENUM values are reordered (%s -> %s), so stored indexes change meaning",
+					strings.Join(o.Type.GetArgs(), ","), strings.Join(n.Type.GetArgs(), ",")), true
+			}
+		} else if reason := typeNarrowing(o.Type, n.Type); reason != "" {
+			return reason, true
+		}
+		if o.Nullable && !n.Nullable && n.Default == "" && n.Generated == "" {
+			return "the column becomes NOT NULL without default, so rows holding NULL are rejected", true
+		}
+	}
+	return "", false
+}
+
+// changes from old to new which corrupt or reject stored rows
+func FindBreakingChanges(old, new *Schema, opts Options) []BreakingChange {
+	ret := []BreakingChange{}
+	for _, c := range DiffSchema(old, new, opts) {
+		if c.Kind == ChangeDropTable {
+			ret = append(ret, BreakingChange{Table: c.Table, Reason: "the table is dropped with its rows"})
+			continue
+		}
+		if c.OldColumn == nil {
+			continue
+		}
+		reason, ok := columnBreakingChange(c)
+		if !ok {
+			continue
+		}
+		b := BreakingChange{Table: c.Table, Column: c.OldColumn.Name, Reason: reason}
+		if c.OldColumn.Field != nil {
+			b.Field = c.OldColumn.Field.FullName
+		}
+		ret = append(ret, b)
+	}
+	return ret
+}
+
+// breaking changes from baseline to f. baseline is nil when the file is new, and f is nil when the file is removed
+func CheckBreakingChanges(baselineDep dep.INameSpace, baseline *descriptor.FileDescriptorProto, dep dep.INameSpace, f *descriptor.FileDescriptorProto, opts Options) ([]BreakingChange, error) {
+	old, new, err := buildMigrationSchemas(baselineDep, baseline, dep, f, opts)
+	if err != nil {
+		return nil, err
+	}
+	return FindBreakingChanges(old, new, opts), nil
+}
@@ -0,0 +1,107 @@
+package gensql
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestFindBreakingChanges(t *testing.T) {
+	tests := []struct {
+		name     string
+		old      string
+		new      string
+		breaking []string
+	}{
+		{
+			name:     "renamed field",
+			old:      `message_type { name: "User" field { name: "age" number: 1 type: TYPE_INT32 label: LABEL_OPTIONAL } }`,
+			new:      `message_type { name: "User" field { name: "years" number: 1 type: TYPE_INT32 label: LABEL_OPTIONAL } }`,
+			breaking: []string{},
+		},
+		{
+			name:     "renamed field of another type",
+			old:      `message_type { name: "User" field { name: "age" number: 1 type: TYPE_INT32 label: LABEL_OPTIONAL } }`,
+			new:      `message_type { name: "User" field { name: "birthday" number: 1 type: TYPE_STRING label: LABEL_OPTIONAL } }`,
+			breaking: []string{"Foo.User.age (User.age): the column is dropped with its values"},
+		},
+		{
+			name:     "narrowed field",
+			old:      `message_type { name: "User" field { name: "age" number: 1 type: TYPE_INT64 label: LABEL_OPTIONAL } }`,
+			new:      `message_type { name: "User" field { name: "age" number: 1 type: TYPE_INT32 label: LABEL_OPTIONAL } }`,
+			breaking: []string{"Foo.User.age (User.age): BIGINT -> INT narrows the range. out of range values are rejected"},
+		},
+		{
+			name:     "dropped message",
+			old:      `message_type { name: "User" field { name: "age" number: 1 type: TYPE_INT32 label: LABEL_OPTIONAL } }`,
+			new:      ``,
+			breaking: []string{"User: the table is dropped with its rows"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			breaking := []string{}
+			for _, b := range FindBreakingChanges(buildSchema(t, tt.old), buildSchema(t, tt.new), Options{}) {
+				breaking = append(breaking, b.String())
+			}
+			if got, want := strings.Join(breaking, "\n"), strings.Join(tt.breaking, "\n"); got != want {
+				t.Errorf("breaking changes = \n%s\nwant\n%s", got, want)
+			}
+		})
+	}
+}
+
+func TestFindBreakingChangesEnum(t *testing.T) {
+	const gender = `enum_type { name: "Gender" value { name: "MALE" number: 0 } value { name: "FEMALE" number: 2 } }`
+	tests := []struct {
+		name     string
+		enum     string
+		breaking []string
+	}{
+		{
+			name:     "appended enum value",
+			enum:     `enum_type { name: "Gender" value { name: "MALE" number: 0 } value { name: "FEMALE" number: 2 } value { name: "OTHER" number: 3 } }`,
+			breaking: []string{},
+		},
+		{
+			name:     "renamed enum value",
+			enum:     `enum_type { name: "Gender" value { name: "MAN" number: 0 } value { name: "FEMALE" number: 2 } }`,
+			breaking: []string{},
+		},
+		{
+			name:     "removed enum value",
+			enum:     `enum_type { name: "Gender" value { name: "MALE" number: 0 } }`,
+			breaking: []string{"Foo.User.gender (User.gender): ENUM values FEMALE are removed, so rows holding them are rejected"},
+		},
+		{
+			name:     "renumbered enum values",
+			enum:     `enum_type { name: "Gender" value { name: "MALE" number: 2 } value { name: "FEMALE" number: 0 } }`,
+			breaking: []string{"Foo.User.gender (User.gender): ENUM values MALE,FEMALE are renumbered, so stored messages read them as other values"},
+		},
+		{
+			name:     "enum value inserted before others",
+			enum:     `enum_type { name: "Gender" value { name: "MALE" number: 0 } value { name: "OTHER" number: 1 } value { name: "FEMALE" number: 2 } }`,
+			breaking: []string{"Foo.User.gender (User.gender): ENUM values are reordered ('MALE','FEMALE' -> 'MALE','OTHER','FEMALE'), so stored indexes change meaning"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dep, f := parseFile(t, `name: "user.proto" package: "Foo" syntax: "proto3" message_type { name: "User" field { name: "gender" number: 1 type: TYPE_ENUM type_name: ".Foo.Gender" label: LABEL_OPTIONAL } } `+gender)
+			old, err := BuildSchema(dep, f, Options{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			dep, f = parseFile(t, `name: "user.proto" package: "Foo" syntax: "proto3" message_type { name: "User" field { name: "gender" number: 1 type: TYPE_ENUM type_name: ".Foo.Gender" label: LABEL_OPTIONAL } } `+tt.enum)
+			new, err := BuildSchema(dep, f, Options{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			breaking := []string{}
+			for _, b := range FindBreakingChanges(old, new, Options{}) {
+				breaking = append(breaking, b.String())
+			}
+			if got, want := strings.Join(breaking, "\n"), strings.Join(tt.breaking, "\n"); got != want {
+				t.Errorf("breaking changes = \n%s\nwant\n%s", got, want)
+			}
+		})
+	}
+}
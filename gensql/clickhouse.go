@@ -0,0 +1,212 @@
+package gensql
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Mojashi/proto-mysql/dep"
+	"github.com/golang/glog"
+	"github.com/golang/protobuf/protoc-gen-go/descriptor"
+)
+
+var clickHouseDataTypeMap = map[descriptor.FieldDescriptorProto_Type]string{
+	descriptor.FieldDescriptorProto_TYPE_DOUBLE:   "Float64",
+	descriptor.FieldDescriptorProto_TYPE_FLOAT:    "Float32",
+	descriptor.FieldDescriptorProto_TYPE_INT64:    "Int64",
+	descriptor.FieldDescriptorProto_TYPE_UINT64:   "UInt64",
+	descriptor.FieldDescriptorProto_TYPE_INT32:    "Int32",
+	descriptor.FieldDescriptorProto_TYPE_FIXED64:  "UInt64",
+	descriptor.FieldDescriptorProto_TYPE_FIXED32:  "UInt32",
+	descriptor.FieldDescriptorProto_TYPE_BOOL:     "Bool",
+	descriptor.FieldDescriptorProto_TYPE_STRING:   "String",
+	descriptor.FieldDescriptorProto_TYPE_BYTES:    "String",
+	descriptor.FieldDescriptorProto_TYPE_UINT32:   "UInt32",
+	descriptor.FieldDescriptorProto_TYPE_SFIXED32: "Int32",
+	descriptor.FieldDescriptorProto_TYPE_SFIXED64: "Int64",
+	descriptor.FieldDescriptorProto_TYPE_SINT32:   "Int32",
+	descriptor.FieldDescriptorProto_TYPE_SINT64:   "Int64",
+}
+
+// Enum8 if all numbers fit in Int8, and Enum16 if they fit in Int16
+func genClickHouseEnum(e *descriptor.EnumDescriptorProto) (string, error) {
+	kind := "Enum8"
+	values := make([]string, 0, len(e.GetValue()))
+	for _, v := range e.GetValue() {
+		if v.GetNumber() < -32768 || v.GetNumber() > 32767 {
+			return "", fmt.Errorf("value %s = %d of ENUM %s does not fit in Enum16", v.GetName(), v.GetNumber(), e.GetName())
+		}
+		if v.GetNumber() < -128 || v.GetNumber() > 127 {
+			kind = "Enum16"
+		}
+		values = append(values, fmt.Sprintf("'%s' = %d", v.GetName(), v.GetNumber()))
+	}
+	return fmt.Sprintf("%s(%s)", kind, strings.Join(values, ", ")), nil
+}
+
+// embedded messages are mapped to named Tuple.
+// visiting holds messages on the path, as recursive messages cannot be expressed.
+func clickHouseElementType(dep dep.INameSpace, field *descriptor.FieldDescriptorProto, visiting map[string]bool) (string, error) {
+	switch field.GetType() {
+	case descriptor.FieldDescriptorProto_TYPE_ENUM:
+		enum, ok := dep.GetEnum(strings.Split(field.GetTypeName(), "."))
+		if !ok {
+			return "", fmt.Errorf("failed to find ENUM %s", field.GetTypeName())
+		}
+		return genClickHouseEnum(enum.GetEnum())
+	case descriptor.FieldDescriptorProto_TYPE_MESSAGE, descriptor.FieldDescriptorProto_TYPE_GROUP:
+		if visiting[field.GetTypeName()] {
+			return "", fmt.Errorf("recursive message %s cannot be mapped to Tuple", field.GetTypeName())
+		}
+		msg, ok := dep.GetMessage(strings.Split(field.GetTypeName(), "."))
+		if !ok {
+			return "", fmt.Errorf("failed to find message %s", field.GetTypeName())
+		}
+		visiting[field.GetTypeName()] = true
+		defer delete(visiting, field.GetTypeName())
+
+		elems := make([]string, 0, len(msg.GetDescriptor().GetField()))
+		for _, f := range msg.GetDescriptor().GetField() {
+			t, err := clickHouseType(dep, f, visiting)
+			if err != nil {
+				return "", err
+			}
+			elems = append(elems, fmt.Sprintf("%s %s", QuoteIdent(DialectClickHouse, f.GetName()), t))
+		}
+		return fmt.Sprintf("Tuple(%s)", strings.Join(elems, ", ")), nil
+	}
+	if t, ok := clickHouseDataTypeMap[field.GetType()]; ok {
+		return t, nil
+	}
+	return "", fmt.Errorf("failed to find type")
+}
+
+func clickHouseType(dep dep.INameSpace, field *descriptor.FieldDescriptorProto, visiting map[string]bool) (string, error) {
+	if cand, ok := CheckSpecifiedType(dep, field); ok {
+		return cand.ToString(), nil
+	}
+	if err := checkSemantic(field); err != nil {
+		return "", err
+	}
+	t, err := clickHouseElementType(dep, field, visiting)
+	if err != nil {
+		return "", err
+	}
+	switch GetSemantic(field) {
+	case MySQLColumn_UUID:
+		t = "UUID"
+	case MySQLColumn_INET6:
+		t = "IPv6"
+	}
+	if field.GetLabel() == descriptor.FieldDescriptorProto_LABEL_REPEATED {
+		return fmt.Sprintf("Array(%s)", t), nil
+	}
+	// Tuple cannot be inside Nullable, so unset messages are stored as their default values
+	if field.GetProto3Optional() && !strings.HasPrefix(t, "Tuple(") {
+		return fmt.Sprintf("Nullable(%s)", t), nil
+	}
+	return t, nil
+}
+
+func GenClickHouseDataType(dep dep.INameSpace, field *descriptor.FieldDescriptorProto) (string, error) {
+	return clickHouseType(dep, field, map[string]bool{})
+}
+
+// ClickHouse has no unique keys, secondary indexes, foreign keys or ON UPDATE, which are logged and ignored.
+// ORDER BY of MergeTree is taken from clickHouse option or primaryKey.
+// column errors fail the generation, as the table cannot be created without their types
+func genClickHouseCreateTable(dep dep.INameSpace, tableName TableName, mt *descriptor.DescriptorProto) (string, error) {
+	q := func(name string) string { return QuoteIdent(DialectClickHouse, name) }
+	createDefinitions := make([]string, 0, len(mt.Field))
+	statements := []string{}
+	tableOpts, _ := CheckTableOptions(mt)
+	if len(tableOpts.GetForeignKeys()) > 0 {
+		glog.Errorf("foreign keys of message %s are ignored, as ClickHouse has no foreign keys", mt.GetName())
+	}
+
+	for _, field := range mt.Field {
+		if IsAutoIncrement(field) {
+			glog.Errorf("AUTO_INCREMENT of %s in message %s is not supported by ClickHouse", field.GetName(), mt.GetName())
+		}
+		dataType, err := GenClickHouseDataType(dep, field)
+		if err != nil {
+			return "", fmt.Errorf("failed to process field %s in message %s: %v", field.GetName(), mt.GetName(), err)
+		}
+		column, _ := CheckColumnOptions(field)
+		if column.GetInvisible() || column.GetCheck() != "" {
+			glog.Errorf("invisible and check of %s in message %s are not supported by ClickHouse", field.GetName(), mt.GetName())
+		}
+		if column.GetDefaultExpression() != "" {
+			dataType += " DEFAULT " + column.GetDefaultExpression()
+		}
+		createDefinitions = append(createDefinitions, fmt.Sprintf("\t%s %s", q(field.GetName()), dataType))
+	}
+
+	if audit, ok := GetAuditColumns(mt); ok {
+		createDefinitions = append(createDefinitions,
+			fmt.Sprintf("\t%s DateTime64(6) DEFAULT now64(6)", q(audit.CreatedAt)),
+			fmt.Sprintf("\t%s DateTime64(6) DEFAULT now64(6)", q(audit.UpdatedAt)),
+			fmt.Sprintf("\t%s UInt64 DEFAULT 0", q(audit.RowVersion)),
+		)
+	}
+
+	sd, softDelete := GetSoftDelete(mt)
+	if softDelete {
+		createDefinitions = append(createDefinitions,
+			fmt.Sprintf("\t%s Nullable(DateTime64(6)) DEFAULT NULL", q(sd.Column)))
+	}
+
+	createDefinitions = append(createDefinitions, "\t"+q("PROTO_BINARY")+" String")
+
+	if len(tableOpts.GetIndexes()) > 0 {
+		glog.Errorf("indexes of message %s are ignored, as ClickHouse has no secondary indexes", mt.GetName())
+	}
+	if tableOpts.GetPartition() != nil && tableOpts.GetClickHouse().GetPartitionBy() == "" {
+		glog.Errorf("partition of message %s is ignored. use clickHouse.partitionBy", mt.GetName())
+	}
+
+	ch := tableOpts.GetClickHouse()
+	engine := ch.GetEngine()
+	if engine == "" {
+		engine = "MergeTree"
+	}
+	orderBy := "tuple()"
+	if len(ch.GetOrderBy()) > 0 {
+		orderBy = "(" + strings.Join(ch.GetOrderBy(), ", ") + ")"
+	} else if len(tableOpts.GetPrimaryKey()) > 0 {
+		orderBy = "(" + strings.Join(quoteIdents(DialectClickHouse, tableOpts.GetPrimaryKey()), ", ") + ")"
+	}
+	tableOptions := fmt.Sprintf("ENGINE = %s\nORDER BY %s", engine, orderBy)
+	if ch.GetPartitionBy() != "" {
+		tableOptions += "\nPARTITION BY " + ch.GetPartitionBy()
+	}
+
+	if softDelete && sd.LiveView {
+		statements = append(statements, fmt.Sprintf("CREATE VIEW %s AS SELECT * FROM %s WHERE %s IS NULL;",
+			LiveViewName(tableName).Format(DialectClickHouse),
+			tableName.Format(DialectClickHouse),
+			q(sd.Column),
+		))
+	}
+
+	createTable := fmt.Sprintf("CREATE TABLE %s (\n%s\n)\n%s;",
+		tableName.Format(DialectClickHouse),
+		strings.Join(createDefinitions, ",\n"),
+		tableOptions,
+	)
+	return strings.Join(append([]string{createTable}, statements...), "\n\n"), nil
+}
+
+func genClickHouseSQL(dep dep.INameSpace, f *descriptor.FileDescriptorProto, opts Options) (string, error) {
+	statements := []string{}
+	if opts.PackageMapping == PackageMappingDatabase && f.GetPackage() != "" {
+		statements = append(statements, fmt.Sprintf("CREATE DATABASE IF NOT EXISTS %s;", QuoteIdent(DialectClickHouse, packageIdent(f))))
+	}
+	for _, mt := range f.MessageType {
+		createTable, err := genClickHouseCreateTable(dep, GetTableName(f, mt, opts), mt)
+		if err != nil {
+			return "", err
+		}
+		statements = append(statements, createTable)
+	}
+	return strings.Join(statements, "\n\n"), nil
+}
@@ -0,0 +1,88 @@
+package gensql
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestGenClickHouseDataType(t *testing.T) {
+	tests := []struct {
+		name  string
+		field string
+		enum  string
+		typ   string
+		err   string
+	}{
+		{
+			name:  "optional scalar",
+			field: `name: "age" number: 1 type: TYPE_INT32 label: LABEL_OPTIONAL proto3_optional: true`,
+			typ:   "Nullable(Int32)",
+		},
+		{
+			name:  "optional message",
+			field: `name: "search" number: 1 type: TYPE_MESSAGE type_name: ".Foo.Search" label: LABEL_OPTIONAL proto3_optional: true`,
+			typ:   "Tuple(`query` String, `page` Nullable(Int32))",
+		},
+		{
+			name:  "repeated message",
+			field: `name: "searches" number: 1 type: TYPE_MESSAGE type_name: ".Foo.Search" label: LABEL_REPEATED`,
+			typ:   "Array(Tuple(`query` String, `page` Nullable(Int32)))",
+		},
+		{
+			name:  "Enum8",
+			field: `name: "gender" number: 1 type: TYPE_ENUM type_name: ".Foo.Gender" label: LABEL_OPTIONAL`,
+			enum:  `value { name: "MALE" number: 0 } value { name: "FEMALE" number: 127 }`,
+			typ:   "Enum8('MALE' = 0, 'FEMALE' = 127)",
+		},
+		{
+			name:  "Enum16",
+			field: `name: "gender" number: 1 type: TYPE_ENUM type_name: ".Foo.Gender" label: LABEL_OPTIONAL`,
+			enum:  `value { name: "MALE" number: -32768 } value { name: "FEMALE" number: 32767 }`,
+			typ:   "Enum16('MALE' = -32768, 'FEMALE' = 32767)",
+		},
+		{
+			name:  "enum beyond Int16",
+			field: `name: "gender" number: 1 type: TYPE_ENUM type_name: ".Foo.Gender" label: LABEL_OPTIONAL`,
+			enum:  `value { name: "MALE" number: 0 } value { name: "FEMALE" number: 32768 }`,
+			err:   "value FEMALE = 32768 of ENUM Gender does not fit in Enum16",
+		},
+		{
+			name:  "recursive message",
+			field: `name: "parent" number: 1 type: TYPE_MESSAGE type_name: ".Foo.Node" label: LABEL_OPTIONAL`,
+			err:   "recursive message .Foo.Node cannot be mapped to Tuple",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			enum := tt.enum
+			if enum == "" {
+				enum = `value { name: "MALE" number: 0 }`
+			}
+			dep, f := parseMessages(t, `
+				message_type {
+					name: "Search"
+					field { name: "query" number: 1 type: TYPE_STRING label: LABEL_OPTIONAL }
+					field { name: "page" number: 2 type: TYPE_INT32 label: LABEL_OPTIONAL proto3_optional: true }
+				}
+				message_type {
+					name: "Node"
+					field { name: "parent" number: 1 type: TYPE_MESSAGE type_name: ".Foo.Node" label: LABEL_OPTIONAL }
+				}
+				message_type { name: "Event" field { `+tt.field+` } }
+				enum_type { name: "Gender" `+enum+` }`)
+			typ, err := GenClickHouseDataType(dep, f.MessageType[2].Field[0])
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Fatalf("error = %v, want %q", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if typ != tt.typ {
+				t.Errorf("type = %s, want %s", typ, tt.typ)
+			}
+		})
+	}
+}
@@ -0,0 +1,73 @@
+package gensql
+
+import (
+	"fmt"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/golang/protobuf/protoc-gen-go/descriptor"
+)
+
+func CheckColumnOptions(field *descriptor.FieldDescriptorProto) (*MySQLColumn, bool) {
+	opts := field.GetOptions()
+	if opts == nil {
+		return nil, false
+	}
+
+	ext, err := proto.GetExtension(opts, E_MySQLColumn)
+	if err != nil {
+		return nil, false
+	}
+	return ext.(*MySQLColumn), true
+}
+
+func IsAutoIncrement(field *descriptor.FieldDescriptorProto) bool {
+	c, ok := CheckColumnOptions(field)
+	return ok && c.GetAutoIncrement()
+}
+
+func GetSemantic(field *descriptor.FieldDescriptorProto) MySQLColumn_Semantic {
+	c, _ := CheckColumnOptions(field)
+	return c.GetSemantic()
+}
+
+func checkSemantic(field *descriptor.FieldDescriptorProto) error {
+	if GetSemantic(field) == MySQLColumn_NONE {
+		return nil
+	}
+	if field.GetType() != descriptor.FieldDescriptorProto_TYPE_STRING || field.GetLabel() == descriptor.FieldDescriptorProto_LABEL_REPEATED {
+		return fmt.Errorf("%s semantic requires a singular string field, but %s is not", GetSemantic(field), field.GetName())
+	}
+	return nil
+}
+
+var multiValuedCastTypes = map[descriptor.FieldDescriptorProto_Type]string{
+	descriptor.FieldDescriptorProto_TYPE_INT64:    "SIGNED",
+	descriptor.FieldDescriptorProto_TYPE_INT32:    "SIGNED",
+	descriptor.FieldDescriptorProto_TYPE_SINT64:   "SIGNED",
+	descriptor.FieldDescriptorProto_TYPE_SINT32:   "SIGNED",
+	descriptor.FieldDescriptorProto_TYPE_SFIXED64: "SIGNED",
+	descriptor.FieldDescriptorProto_TYPE_SFIXED32: "SIGNED",
+	descriptor.FieldDescriptorProto_TYPE_UINT64:   "UNSIGNED",
+	descriptor.FieldDescriptorProto_TYPE_UINT32:   "UNSIGNED",
+	descriptor.FieldDescriptorProto_TYPE_FIXED64:  "UNSIGNED",
+	descriptor.FieldDescriptorProto_TYPE_FIXED32:  "UNSIGNED",
+	descriptor.FieldDescriptorProto_TYPE_STRING:   "CHAR(255)",
+}
+
+// multi-valued index has a single repeated scalar field, which is stored as JSON array
+func getMultiValuedField(mt *descriptor.DescriptorProto, index *MySQLIndex) (*descriptor.FieldDescriptorProto, string, error) {
+	if len(index.GetColumns()) != 1 {
+		return nil, "", fmt.Errorf("multi-valued index %s must have exactly one column", index.GetName())
+	}
+	for _, field := range mt.Field {
+		if field.GetName() != index.GetColumns()[0] {
+			continue
+		}
+		castType, ok := multiValuedCastTypes[field.GetType()]
+		if field.GetLabel() != descriptor.FieldDescriptorProto_LABEL_REPEATED || !ok {
+			return nil, "", fmt.Errorf("multi-valued index %s requires repeated integer or string field", index.GetName())
+		}
+		return field, castType, nil
+	}
+	return nil, "", fmt.Errorf("column %s is not found in message %s", index.GetColumns()[0], mt.GetName())
+}
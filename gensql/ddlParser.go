@@ -0,0 +1,595 @@
+package gensql
+
+import (
+	"fmt"
+	"strings"
+)
+
+type ddlTokenKind int
+
+const (
+	ddlWord ddlTokenKind = iota
+	// `quoted` identifier
+	ddlIdent
+	ddlString
+	ddlPunct
+)
+
+type ddlToken struct {
+	kind ddlTokenKind
+	// unquoted text of words and identifiers. strings and puncts are as written
+	text string
+	// offsets of the token in the source
+	start, end int
+}
+
+func (t ddlToken) is(word string) bool {
+	return t.kind == ddlWord && strings.EqualFold(t.text, word)
+}
+
+func (t ddlToken) isPunct(p string) bool {
+	return t.kind == ddlPunct && t.text == p
+}
+
+func isWordByte(c byte) bool {
+	return c == '_' || c == '$' || c == '@' || '0' <= c && c <= '9' || 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || c >= 0x80
+}
+
+// split MySQL DDL into tokens. comments are skipped, and versioned comments such as /*!80023 INVISIBLE */ are unwrapped
+func tokenizeDDL(src string) ([]ddlToken, error) {
+	tokens := []ddlToken{}
+	inVersioned := false
+	for i := 0; i < len(src); {
+		c := src[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		case strings.HasPrefix(src[i:], "/*!"):
+			i += 3
+			for i < len(src) && '0' <= src[i] && src[i] <= '9' {
+				i++
+			}
+			inVersioned = true
+		case inVersioned && strings.HasPrefix(src[i:], "*/"):
+			i += 2
+			inVersioned = false
+		case strings.HasPrefix(src[i:], "/*"):
+			end := strings.Index(src[i+2:], "*/")
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated comment at offset %d", i)
+			}
+			i += end + 4
+		case strings.HasPrefix(src[i:], "-- ") || strings.HasPrefix(src[i:], "--\n") || c == '#':
+			for i < len(src) && src[i] != '\n' {
+				i++
+			}
+		case c == '`' || c == '\'' || c == '"':
+			start := i
+			text := strings.Builder{}
+			for i++; ; i++ {
+				if i >= len(src) {
+					return nil, fmt.Errorf("unterminated quote at offset %d", start)
+				}
+				if src[i] == '\\' && c != '`' && i+1 < len(src) {
+					text.WriteByte(src[i])
+					text.WriteByte(src[i+1])
+					i++
+					continue
+				}
+				if src[i] == c {
+					// doubled quote is an escaped quote
+					if i+1 < len(src) && src[i+1] == c {
+						text.WriteByte(c)
+						i++
+						continue
+					}
+					break
+				}
+				text.WriteByte(src[i])
+			}
+			i++
+			if c == '`' {
+				tokens = append(tokens, ddlToken{kind: ddlIdent, text: text.String(), start: start, end: i})
+			} else {
+				tokens = append(tokens, ddlToken{kind: ddlString, text: src[start:i], start: start, end: i})
+			}
+		case isWordByte(c):
+			start := i
+			for i < len(src) && (isWordByte(src[i]) || src[i] == '.' && i+1 < len(src) && '0' <= src[i+1] && src[i+1] <= '9') {
+				i++
+			}
+			tokens = append(tokens, ddlToken{kind: ddlWord, text: src[start:i], start: start, end: i})
+		default:
+			tokens = append(tokens, ddlToken{kind: ddlPunct, text: string(c), start: i, end: i + 1})
+			i++
+		}
+	}
+	return tokens, nil
+}
+
+// return index of the ")" closing tokens[open]
+func closingParen(tokens []ddlToken, open int) (int, error) {
+	depth := 0
+	for i := open; i < len(tokens); i++ {
+		switch {
+		case tokens[i].isPunct("("):
+			depth++
+		case tokens[i].isPunct(")"):
+			depth--
+			if depth == 0 {
+				return i, nil
+			}
+		}
+	}
+	return 0, fmt.Errorf("unbalanced parenthesis")
+}
+
+// split tokens by sep outside of parentheses
+func splitTokens(tokens []ddlToken, sep string) [][]ddlToken {
+	ret := [][]ddlToken{}
+	depth, start := 0, 0
+	for i, t := range tokens {
+		switch {
+		case t.isPunct("("):
+			depth++
+		case t.isPunct(")"):
+			depth--
+		case t.isPunct(sep) && depth == 0:
+			ret = append(ret, tokens[start:i])
+			start = i + 1
+		}
+	}
+	return append(ret, tokens[start:])
+}
+
+// split tokens into statements which start with CREATE. SHOW CREATE TABLE output has no ";",
+// and mysql -N prefixes each statement with the table name
+func splitStatements(tokens []ddlToken) [][]ddlToken {
+	ret := [][]ddlToken{}
+	depth, start := 0, -1
+	for i, t := range tokens {
+		switch {
+		case t.isPunct("("):
+			depth++
+		case t.isPunct(")"):
+			depth--
+		case depth == 0 && (t.isPunct(";") || t.is("CREATE")):
+			if start >= 0 {
+				ret = append(ret, tokens[start:i])
+				start = -1
+			}
+			if t.is("CREATE") {
+				start = i
+			}
+		}
+	}
+	if start >= 0 {
+		ret = append(ret, tokens[start:])
+	}
+	return ret
+}
+
+type ddlParser struct {
+	src    string
+	tokens []ddlToken
+	pos    int
+}
+
+func (p *ddlParser) done() bool {
+	return p.pos >= len(p.tokens)
+}
+
+func (p *ddlParser) peek() ddlToken {
+	if p.done() {
+		return ddlToken{kind: ddlPunct}
+	}
+	return p.tokens[p.pos]
+}
+
+func (p *ddlParser) next() ddlToken {
+	t := p.peek()
+	p.pos++
+	return t
+}
+
+// consume the words if they come next
+func (p *ddlParser) accept(words ...string) bool {
+	for i, w := range words {
+		if p.pos+i >= len(p.tokens) || !p.tokens[p.pos+i].is(w) {
+			return false
+		}
+	}
+	p.pos += len(words)
+	return true
+}
+
+func (p *ddlParser) name() (string, error) {
+	t := p.next()
+	if t.kind != ddlWord && t.kind != ddlIdent {
+		return "", fmt.Errorf("expected name at %q", t.text)
+	}
+	return t.text, nil
+}
+
+// source text of the parenthesized group at pos, including the parentheses
+func (p *ddlParser) group() (string, []ddlToken, error) {
+	if !p.peek().isPunct("(") {
+		return "", nil, fmt.Errorf("expected ( at %q", p.peek().text)
+	}
+	end, err := closingParen(p.tokens, p.pos)
+	if err != nil {
+		return "", nil, err
+	}
+	text := p.src[p.tokens[p.pos].start:p.tokens[end].end]
+	inner := p.tokens[p.pos+1 : end]
+	p.pos = end + 1
+	return text, inner, nil
+}
+
+// value such as 'a', -1, NULL, CURRENT_TIMESTAMP(6) or (expr)
+func (p *ddlParser) value() (string, error) {
+	if p.peek().isPunct("(") {
+		text, _, err := p.group()
+		return text, err
+	}
+	start := p.peek().start
+	t := p.next()
+	if t.isPunct("-") || t.isPunct("+") {
+		t = p.next()
+	}
+	// charset introducer such as _utf8mb4'x'
+	if t.kind == ddlWord && strings.HasPrefix(t.text, "_") && p.peek().kind == ddlString {
+		t = p.next()
+	}
+	end := t.end
+	if t.kind == ddlWord && p.peek().isPunct("(") {
+		if _, _, err := p.group(); err != nil {
+			return "", err
+		}
+		end = p.tokens[p.pos-1].end
+	}
+	return p.src[start:end], nil
+}
+
+// key parts of an index. expressions are kept as written with parentheses
+func (p *ddlParser) keyParts() ([]string, error) {
+	_, inner, err := p.group()
+	if err != nil {
+		return nil, err
+	}
+	ret := []string{}
+	for _, part := range splitTokens(inner, ",") {
+		if len(part) == 0 {
+			return nil, fmt.Errorf("empty key part")
+		}
+		if part[0].isPunct("(") {
+			end, err := closingParen(part, 0)
+			if err != nil {
+				return nil, err
+			}
+			ret = append(ret, p.src[part[0].start:part[end].end])
+			continue
+		}
+		// prefix length and ASC/DESC are ignored
+		ret = append(ret, part[0].text)
+	}
+	return ret, nil
+}
+
+func (p *ddlParser) tableName() (TableName, error) {
+	name, err := p.name()
+	if err != nil {
+		return TableName{}, err
+	}
+	if p.peek().isPunct(".") {
+		p.next()
+		table, err := p.name()
+		return TableName{Database: name, Name: table}, err
+	}
+	return TableName{Name: name}, nil
+}
+
+func (p *ddlParser) referentialAction() string {
+	switch {
+	case p.accept("SET", "NULL"):
+		return "SET NULL"
+	case p.accept("SET", "DEFAULT"):
+		return "SET DEFAULT"
+	case p.accept("NO", "ACTION"):
+		return "NO ACTION"
+	default:
+		return strings.ToUpper(p.next().text)
+	}
+}
+
+func (p *ddlParser) foreignKey(t *Table, name string) error {
+	p.accept("KEY")
+	if !p.peek().isPunct("(") {
+		if _, err := p.name(); err != nil {
+			return err
+		}
+	}
+	columns, err := p.keyParts()
+	if err != nil {
+		return err
+	}
+	if !p.accept("REFERENCES") {
+		return fmt.Errorf("expected REFERENCES at %q", p.peek().text)
+	}
+	ref, err := p.tableName()
+	if err != nil {
+		return err
+	}
+	referencedColumns, err := p.keyParts()
+	if err != nil {
+		return err
+	}
+	fk := &ForeignKey{Name: name, Columns: columns, ReferencedTable: ref, ReferencedColumns: referencedColumns}
+	for !p.done() {
+		switch {
+		case p.accept("ON", "DELETE"):
+			fk.OnDelete = p.referentialAction()
+		case p.accept("ON", "UPDATE"):
+			fk.OnUpdate = p.referentialAction()
+		default:
+			// MATCH FULL and so on
+			p.next()
+		}
+	}
+	t.ForeignKeys = append(t.ForeignKeys, fk)
+	return nil
+}
+
+func (p *ddlParser) check(t *Table, name string, column string) error {
+	_, inner, err := p.group()
+	if err != nil {
+		return err
+	}
+	expression := ""
+	if len(inner) > 0 {
+		expression = p.src[inner[0].start:inner[len(inner)-1].end]
+	}
+	p.accept("NOT")
+	p.accept("ENFORCED")
+	t.Checks = append(t.Checks, &Check{Name: name, Column: column, Expression: expression})
+	return nil
+}
+
+func (p *ddlParser) index(t *Table, unique bool) error {
+	p.accept("KEY")
+	p.accept("INDEX")
+	index := &Index{Unique: unique}
+	if !p.peek().isPunct("(") && !p.peek().is("USING") {
+		name, err := p.name()
+		if err != nil {
+			return err
+		}
+		index.Name = name
+	}
+	if p.accept("USING") {
+		p.next()
+	}
+	columns, err := p.keyParts()
+	if err != nil {
+		return err
+	}
+	index.Columns = columns
+	t.Indexes = append(t.Indexes, index)
+	return nil
+}
+
+// PRIMARY KEY, index, foreign key or check of CREATE TABLE. false if the definition is a column
+func (p *ddlParser) constraint(t *Table) (bool, error) {
+	name := ""
+	if p.accept("CONSTRAINT") {
+		if !p.peek().is("PRIMARY") && !p.peek().is("UNIQUE") && !p.peek().is("FOREIGN") && !p.peek().is("CHECK") {
+			var err error
+			if name, err = p.name(); err != nil {
+				return true, err
+			}
+		}
+	}
+	switch {
+	case p.accept("PRIMARY", "KEY"):
+		if p.accept("USING") {
+			p.next()
+		}
+		columns, err := p.keyParts()
+		t.PrimaryKey = columns
+		return true, err
+	case p.accept("UNIQUE"):
+		return true, p.index(t, true)
+	case p.peek().is("KEY") || p.peek().is("INDEX"):
+		return true, p.index(t, false)
+	case p.accept("FULLTEXT") || p.accept("SPATIAL"):
+		return true, p.index(t, false)
+	case p.accept("FOREIGN"):
+		return true, p.foreignKey(t, name)
+	case p.accept("CHECK"):
+		return true, p.check(t, name, "")
+	}
+	if name != "" {
+		return true, fmt.Errorf("unknown constraint %s", name)
+	}
+	return false, nil
+}
+
+func (p *ddlParser) column(t *Table) error {
+	name, err := p.name()
+	if err != nil {
+		return err
+	}
+	c := &Column{Name: name, Nullable: true}
+	typeName := strings.ToUpper(p.next().text)
+	var args []string
+	if p.peek().isPunct("(") {
+		_, inner, err := p.group()
+		if err != nil {
+			return err
+		}
+		for _, arg := range splitTokens(inner, ",") {
+			if len(arg) > 0 {
+				args = append(args, p.src[arg[0].start:arg[len(arg)-1].end])
+			}
+		}
+	}
+	for {
+		if p.accept("UNSIGNED") {
+			typeName += " UNSIGNED"
+		} else if !p.accept("SIGNED") && !p.accept("ZEROFILL") {
+			break
+		}
+	}
+	c.Type = MySQLDataTypeWithArgs{dataType: MySQLDataType(typeName), args: args}
+
+	for !p.done() {
+		switch {
+		case p.accept("NOT", "NULL"):
+			c.Nullable = false
+		case p.accept("NULL"):
+			c.Nullable = true
+		case p.accept("DEFAULT"):
+			if c.Default, err = p.value(); err != nil {
+				return err
+			}
+		case p.accept("ON", "UPDATE"):
+			if c.OnUpdate, err = p.value(); err != nil {
+				return err
+			}
+		case p.accept("AUTO_INCREMENT"):
+			c.AutoIncrement = true
+		case p.accept("INVISIBLE"):
+			c.Invisible = true
+		case p.accept("GENERATED", "ALWAYS", "AS") || p.accept("AS"):
+			text, _, err := p.group()
+			if err != nil {
+				return err
+			}
+			c.Generated = strings.TrimSuffix(strings.TrimPrefix(text, "("), ")")
+		case p.accept("PRIMARY", "KEY") || p.accept("KEY"):
+			t.PrimaryKey = []string{c.Name}
+		case p.accept("UNIQUE"):
+			p.accept("KEY")
+			t.Indexes = append(t.Indexes, &Index{Columns: []string{c.Name}, Unique: true})
+		case p.accept("CONSTRAINT"):
+			checkName, err := p.name()
+			if err != nil {
+				return err
+			}
+			if !p.accept("CHECK") {
+				return fmt.Errorf("expected CHECK at %q", p.peek().text)
+			}
+			if err := p.check(t, checkName, c.Name); err != nil {
+				return err
+			}
+		case p.accept("CHECK"):
+			if err := p.check(t, "", c.Name); err != nil {
+				return err
+			}
+		case p.accept("CHARACTER", "SET") || p.accept("CHARSET") || p.accept("COLLATE") ||
+			p.accept("COMMENT") || p.accept("COLUMN_FORMAT") || p.accept("STORAGE") || p.accept("SRID"):
+			p.next()
+		default:
+			// VISIBLE, VIRTUAL, STORED and so on
+			p.next()
+		}
+	}
+	t.Columns = append(t.Columns, c)
+	return nil
+}
+
+// table options after the definitions. only charset, collation and partitioning are kept
+func (p *ddlParser) tableOptions(t *Table) {
+	for !p.done() {
+		switch {
+		case p.peek().is("PARTITION"):
+			t.Partition = strings.TrimSpace(p.src[p.peek().start:p.tokens[len(p.tokens)-1].end])
+			return
+		case p.accept("DEFAULT"):
+		case p.peek().isPunct(","):
+			p.next()
+		case p.accept("CHARACTER", "SET") || p.accept("CHARSET"):
+			if p.peek().isPunct("=") {
+				p.next()
+			}
+			t.Charset = p.next().text
+		case p.accept("COLLATE"):
+			if p.peek().isPunct("=") {
+				p.next()
+			}
+			t.Collation = p.next().text
+		default:
+			p.next()
+			if p.peek().isPunct("=") {
+				p.next()
+			}
+			p.next()
+		}
+	}
+}
+
+func (p *ddlParser) createTable() (*Table, error) {
+	p.accept("IF", "NOT", "EXISTS")
+	name, err := p.tableName()
+	if err != nil {
+		return nil, err
+	}
+	t := &Table{Name: name}
+	if !p.peek().isPunct("(") {
+		return nil, fmt.Errorf("expected definitions of table %s", name)
+	}
+	_, inner, err := p.group()
+	if err != nil {
+		return nil, err
+	}
+	rest := p.tokens[p.pos:]
+	for _, def := range splitTokens(inner, ",") {
+		dp := &ddlParser{src: p.src, tokens: def}
+		ok, err := dp.constraint(t)
+		if err != nil {
+			return nil, fmt.Errorf("table %s: %v", name, err)
+		}
+		if ok {
+			continue
+		}
+		if err := dp.column(t); err != nil {
+			return nil, fmt.Errorf("table %s: %v", name, err)
+		}
+	}
+	(&ddlParser{src: p.src, tokens: rest}).tableOptions(t)
+	return t, nil
+}
+
+// parse CREATE TABLE statements, e.g. output of SHOW CREATE TABLE or mysqldump --no-data.
+// other statements are skipped. types, defaults and expressions are kept as written.
+// a dump without CREATE TABLE is an error, as it is empty or not DDL at all
+func ParseCreateTables(ddl string) ([]*Table, error) {
+	tokens, err := tokenizeDDL(ddl)
+	if err != nil {
+		return nil, err
+	}
+	statements := splitStatements(tokens)
+	if len(statements) == 0 {
+		return nil, fmt.Errorf("no CREATE statement is found")
+	}
+	tables := []*Table{}
+	for _, statement := range statements {
+		p := &ddlParser{src: ddl, tokens: statement}
+		if !p.accept("CREATE") {
+			continue
+		}
+		p.accept("TEMPORARY")
+		if !p.accept("TABLE") {
+			continue
+		}
+		t, err := p.createTable()
+		if err != nil {
+			return nil, err
+		}
+		tables = append(tables, t)
+	}
+	if len(tables) == 0 {
+		return nil, fmt.Errorf("no CREATE TABLE statement is found")
+	}
+	return tables, nil
+}
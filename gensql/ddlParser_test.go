@@ -0,0 +1,160 @@
+package gensql
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestParseCreateTables(t *testing.T) {
+	tests := []struct {
+		name   string
+		ddl    string
+		tables []*Table
+		err    string
+	}{
+		{
+			name: "show create table",
+			ddl: "CREATE TABLE `User` (\n" +
+				"  `id` int NOT NULL AUTO_INCREMENT,\n" +
+				"  `username` varchar(64) COLLATE utf8mb4_bin DEFAULT NULL,\n" +
+				"  `org_id` bigint unsigned NOT NULL,\n" +
+				"  `gender` enum('MALE','FEMALE') NOT NULL DEFAULT 'MALE',\n" +
+				"  `token` char(36) NOT NULL DEFAULT (uuid()) /*!80023 INVISIBLE */,\n" +
+				"  `updated_at` datetime(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6) ON UPDATE CURRENT_TIMESTAMP(6),\n" +
+				"  `stamps` json NOT NULL,\n" +
+				"  PRIMARY KEY (`id`),\n" +
+				"  UNIQUE KEY `username_uniq` (`username`),\n" +
+				"  KEY `stamps_idx` ((cast(json_extract(`stamps`,_utf8mb4'$') as signed array))),\n" +
+				"  CONSTRAINT `user_org_fk` FOREIGN KEY (`org_id`) REFERENCES `Org` (`id`) ON DELETE CASCADE,\n" +
+				"  CONSTRAINT `User_chk_1` CHECK ((`org_id` > 0))\n" +
+				") ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_0900_ai_ci",
+			tables: []*Table{{
+				Name: TableName{Name: "User"},
+				Columns: []*Column{
+					{Name: "id", Type: MySQLDataTypeWithArgs{"INT", nil}, AutoIncrement: true},
+					{Name: "username", Type: MySQLDataTypeWithArgs{"VARCHAR", []string{"64"}}, Nullable: true, Default: "NULL"},
+					{Name: "org_id", Type: MySQLDataTypeWithArgs{"BIGINT UNSIGNED", nil}},
+					{Name: "gender", Type: MySQLDataTypeWithArgs{"ENUM", []string{"'MALE'", "'FEMALE'"}}, Default: "'MALE'"},
+					{Name: "token", Type: MySQLDataTypeWithArgs{"CHAR", []string{"36"}}, Default: "(uuid())", Invisible: true},
+					{Name: "updated_at", Type: MySQLDataTypeWithArgs{"DATETIME", []string{"6"}}, Default: "CURRENT_TIMESTAMP(6)", OnUpdate: "CURRENT_TIMESTAMP(6)"},
+					{Name: "stamps", Type: MySQLDataTypeWithArgs{"JSON", nil}},
+				},
+				PrimaryKey: []string{"id"},
+				Indexes: []*Index{
+					{Name: "username_uniq", Columns: []string{"username"}, Unique: true},
+					{Name: "stamps_idx", Columns: []string{"(cast(json_extract(`stamps`,_utf8mb4'$') as signed array))"}},
+				},
+				ForeignKeys: []*ForeignKey{
+					{Name: "user_org_fk", Columns: []string{"org_id"}, ReferencedTable: TableName{Name: "Org"}, ReferencedColumns: []string{"id"}, OnDelete: "CASCADE"},
+				},
+				Checks:    []*Check{{Name: "User_chk_1", Expression: "(`org_id` > 0)"}},
+				Charset:   "utf8mb4",
+				Collation: "utf8mb4_0900_ai_ci",
+			}},
+		},
+		{
+			name: "mysqldump",
+			ddl: "/*!40101 SET NAMES utf8mb4 */;\n" +
+				"DROP TABLE IF EXISTS `Event`;\n" +
+				"-- table of events\n" +
+				"CREATE TABLE IF NOT EXISTS `foo`.`Event` (\n" +
+				"  `id` bigint NOT NULL,\n" +
+				"  `note` text COMMENT 'it''s ok',\n" +
+				"  PRIMARY KEY (`id`)\n" +
+				") ENGINE=InnoDB\n" +
+				"/*!50100 PARTITION BY HASH (`id`)\nPARTITIONS 4 */;\n" +
+				"CREATE VIEW `Event_live` AS SELECT 1;\n",
+			tables: []*Table{{
+				Name: TableName{Database: "foo", Name: "Event"},
+				Columns: []*Column{
+					{Name: "id", Type: MySQLDataTypeWithArgs{"BIGINT", nil}},
+					{Name: "note", Type: MySQLDataTypeWithArgs{"TEXT", nil}, Nullable: true},
+				},
+				PrimaryKey: []string{"id"},
+				Partition:  "PARTITION BY HASH (`id`)\nPARTITIONS 4",
+			}},
+		},
+		{
+			name: "mysql -N",
+			ddl:  "A\tCREATE TABLE `A` (\n  `id` int NOT NULL\n) ENGINE=InnoDB\nB\tCREATE TABLE `B` (\n  `id` int NOT NULL\n) ENGINE=InnoDB\n",
+			tables: []*Table{
+				{Name: TableName{Name: "A"}, Columns: []*Column{{Name: "id", Type: MySQLDataTypeWithArgs{"INT", nil}}}},
+				{Name: TableName{Name: "B"}, Columns: []*Column{{Name: "id", Type: MySQLDataTypeWithArgs{"INT", nil}}}},
+			},
+		},
+		{
+			name: "empty",
+			ddl:  "",
+			err:  "no CREATE statement is found",
+		},
+		{
+			name: "comments only",
+			ddl:  "-- MySQL dump 10.13\n/*!40101 SET NAMES utf8mb4 */;\n",
+			err:  "no CREATE statement is found",
+		},
+		{
+			name: "garbage",
+			ddl:  "ERROR 1146 (42S02): Table foo.User does not exist",
+			err:  "no CREATE statement is found",
+		},
+		{
+			name: "views only",
+			ddl:  "CREATE VIEW `User_live` AS SELECT 1;",
+			err:  "no CREATE TABLE statement is found",
+		},
+		{
+			name: "unterminated quote",
+			ddl:  "CREATE TABLE `User (id int)",
+			err:  "unterminated quote",
+		},
+		{
+			name: "missing definitions",
+			ddl:  "CREATE TABLE `User`;",
+			err:  "expected definitions of table User",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tables, err := ParseCreateTables(tt.ddl)
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Fatalf("error = %v, want %q", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			// Table has unexported fields in its types, so they are compared as their fields
+			if got, want := dumpTables(t, tables), dumpTables(t, tt.tables); got != want {
+				t.Errorf("tables = \n%s\nwant\n%s", got, want)
+			}
+		})
+	}
+}
+
+func dumpTables(t *testing.T, tables []*Table) string {
+	t.Helper()
+	type column struct {
+		*Column
+		Type string
+	}
+	type table struct {
+		*Table
+		Columns []column
+	}
+	dump := []table{}
+	for _, tb := range tables {
+		columns := []column{}
+		for _, c := range tb.Columns {
+			columns = append(columns, column{c, c.Type.ToString()})
+		}
+		dump = append(dump, table{tb, columns})
+	}
+	buf, err := json.MarshalIndent(dump, "", "  ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(buf)
+}
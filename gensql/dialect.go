@@ -0,0 +1,69 @@
+package gensql
+
+import (
+	"fmt"
+	"strings"
+)
+
+type Dialect string
+
+const (
+	DialectMySQL      Dialect = "mysql"
+	DialectPostgreSQL Dialect = "postgres"
+	DialectSQLite     Dialect = "sqlite"
+	DialectMariaDB    Dialect = "mariadb"
+	DialectClickHouse Dialect = "clickhouse"
+)
+
+func ParseDialect(name string) (Dialect, error) {
+	switch d := Dialect(name); d {
+	case DialectMySQL, DialectPostgreSQL, DialectSQLite, DialectMariaDB, DialectClickHouse:
+		return d, nil
+	default:
+		return "", fmt.Errorf("unknown dialect %s", name)
+	}
+}
+
+// MySQL identifiers are left unquoted
+func QuoteIdent(d Dialect, name string) string {
+	switch d {
+	case DialectPostgreSQL, DialectSQLite:
+		return `"` + strings.Replace(name, `"`, `""`, -1) + `"`
+	case DialectClickHouse:
+		return "`" + strings.Replace(name, "`", "\\`", -1) + "`"
+	default:
+		return name
+	}
+}
+
+func (t TableName) Format(d Dialect) string {
+	switch d {
+	case DialectPostgreSQL, DialectSQLite, DialectClickHouse:
+		if t.Database == "" {
+			return QuoteIdent(d, t.Name)
+		}
+		return QuoteIdent(d, t.Database) + "." + QuoteIdent(d, t.Name)
+	default:
+		return t.String()
+	}
+}
+
+func quoteIdents(d Dialect, names []string) []string {
+	ret := make([]string, 0, len(names))
+	for _, name := range names {
+		ret = append(ret, QuoteIdent(d, name))
+	}
+	return ret
+}
+
+// expression of the current time, used for audit and soft-delete columns
+func CurrentTimestamp(d Dialect) string {
+	switch d {
+	case DialectSQLite:
+		return sqliteCurrentTimestamp
+	case DialectClickHouse:
+		return "now64(6)"
+	default:
+		return "CURRENT_TIMESTAMP(6)"
+	}
+}
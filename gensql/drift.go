@@ -0,0 +1,380 @@
+package gensql
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+type DriftKind string
+
+const (
+	// in the generated schema but not in the database
+	DriftMissing DriftKind = "missing"
+	// in the database but not in the generated schema
+	DriftExtra DriftKind = "extra"
+	// in both, but defined differently
+	DriftMismatch DriftKind = "mismatched"
+)
+
+// difference between the generated schema and a table in the database
+type Drift struct {
+	Kind  DriftKind
+	Table TableName
+	// "table", "column", "primary key", "index", "foreign key" or "table option"
+	Object string
+	// empty for tables and primary keys
+	Name string
+	// normalized definitions. empty for the side which lacks the object
+	Expected string
+	Actual   string
+}
+
+func (d Drift) String() string {
+	where := d.Table.String()
+	if d.Name != "" {
+		where += "." + d.Name
+	}
+	switch d.Kind {
+	case DriftMissing:
+		return fmt.Sprintf("missing %s %s: %s", d.Object, where, d.Expected)
+	case DriftExtra:
+		return fmt.Sprintf("extra %s %s: %s", d.Object, where, d.Actual)
+	default:
+		return fmt.Sprintf("mismatched %s %s: expected %s, actual %s", d.Object, where, d.Expected, d.Actual)
+	}
+}
+
+var integerDisplayWidth = regexp.MustCompile(`^(TINYINT|SMALLINT|MEDIUMINT|INT|INTEGER|BIGINT)\(\d+\)`)
+
+// types are compared as MySQL shows them. e.g. BOOLEAN is TINYINT(1), and display widths of integers are ignored
+func normalizeDriftType(t MySQLDataTypeWithArgs) string {
+	name := strings.ToUpper(t.ToString())
+	name = strings.Replace(name, ", ", ",", -1)
+	switch {
+	case name == "BOOL" || name == "BOOLEAN":
+		return "TINYINT"
+	case strings.HasPrefix(name, "INTEGER"):
+		name = "INT" + strings.TrimPrefix(name, "INTEGER")
+	}
+	return integerDisplayWidth.ReplaceAllString(name, "$1")
+}
+
+// e.g. '0' and 0 are the same default, as MySQL quotes numbers
+func normalizeDriftDefault(value string) string {
+	if strings.EqualFold(value, "NULL") {
+		return ""
+	}
+	if len(value) >= 2 && value[0] == '\'' && value[len(value)-1] == '\'' {
+		if _, err := strconv.ParseFloat(value[1:len(value)-1], 64); err == nil {
+			return value[1 : len(value)-1]
+		}
+		return value
+	}
+	return strings.ToUpper(strings.Replace(value, "`", "", -1))
+}
+
+// MariaDB shows a JSON column as LONGTEXT checked by json_valid
+func isMariaDBJSON(t *Table, c *Column) bool {
+	if normalizeDriftType(c.Type) != "LONGTEXT" {
+		return false
+	}
+	for _, check := range t.Checks {
+		expression := strings.ToLower(strings.Replace(check.Expression, "`", "", -1))
+		if strings.EqualFold(check.Column, c.Name) && expression == "json_valid("+strings.ToLower(c.Name)+")" {
+			return true
+		}
+	}
+	return false
+}
+
+// definition of column c of table t without expressions, which MySQL rewrites
+func driftColumnDefinition(t *Table, c *Column, dialect Dialect) string {
+	dataType := normalizeDriftType(c.Type)
+	if dialect == DialectMariaDB && isMariaDBJSON(t, c) {
+		dataType = "JSON"
+	}
+	terms := []string{dataType}
+	switch {
+	case c.Generated != "":
+		terms = append(terms, "GENERATED")
+	case c.Nullable:
+		terms = append(terms, "NULL")
+	default:
+		terms = append(terms, "NOT NULL")
+	}
+	if d := normalizeDriftDefault(c.Default); d != "" {
+		terms = append(terms, "DEFAULT "+d)
+	}
+	if c.OnUpdate != "" {
+		terms = append(terms, "ON UPDATE "+strings.ToUpper(c.OnUpdate))
+	}
+	if c.Invisible {
+		terms = append(terms, "INVISIBLE")
+	}
+	if c.AutoIncrement {
+		terms = append(terms, "AUTO_INCREMENT")
+	}
+	return strings.Join(terms, " ")
+}
+
+// key parts with expressions replaced, as MySQL rewrites them
+func driftKeyParts(columns []string) string {
+	parts := make([]string, 0, len(columns))
+	for _, c := range columns {
+		if strings.HasPrefix(c, "(") {
+			c = "(expression)"
+		}
+		parts = append(parts, strings.ToLower(c))
+	}
+	return "(" + strings.Join(parts, ",") + ")"
+}
+
+func driftIndexDefinition(index *Index) string {
+	if index.Unique {
+		return "UNIQUE " + driftKeyParts(index.Columns)
+	}
+	return "INDEX " + driftKeyParts(index.Columns)
+}
+
+// RESTRICT and NO ACTION are the default, which SHOW CREATE TABLE omits
+func normalizeReferentialAction(action string) string {
+	switch a := strings.ToUpper(action); a {
+	case "RESTRICT", "NO ACTION":
+		return ""
+	default:
+		return a
+	}
+}
+
+func driftForeignKeyDefinition(fk *ForeignKey) string {
+	ret := fmt.Sprintf("%s REFERENCES %s %s", driftKeyParts(fk.Columns), strings.ToLower(fk.ReferencedTable.Name), driftKeyParts(fk.ReferencedColumns))
+	if a := normalizeReferentialAction(fk.OnDelete); a != "" {
+		ret += " ON DELETE " + a
+	}
+	if a := normalizeReferentialAction(fk.OnUpdate); a != "" {
+		ret += " ON UPDATE " + a
+	}
+	return ret
+}
+
+// method, expression and partition names of a PARTITION BY clause
+func driftPartition(partition string) string {
+	tokens, err := tokenizeDDL(partition)
+	if err != nil || len(tokens) == 0 {
+		return partition
+	}
+	terms := []string{}
+	names := []string{}
+	head := true
+	for i := 0; i < len(tokens); i++ {
+		t := tokens[i]
+		switch {
+		case head && t.isPunct("("):
+			end, err := closingParen(tokens, i)
+			if err != nil {
+				return partition
+			}
+			expression := []string{}
+			for _, e := range tokens[i+1 : end] {
+				expression = append(expression, strings.ToLower(e.text))
+			}
+			terms = append(terms, "("+strings.Join(expression, "")+")")
+			head = false
+			i = end
+		case head:
+			terms = append(terms, strings.ToUpper(t.text))
+		case t.is("PARTITIONS") && i+1 < len(tokens):
+			terms = append(terms, "PARTITIONS "+tokens[i+1].text)
+			i++
+		case t.is("PARTITION") && i+1 < len(tokens):
+			names = append(names, tokens[i+1].text)
+			i++
+		}
+	}
+	if len(names) > 0 {
+		terms = append(terms, strings.Join(names, ","))
+	}
+	return strings.Join(terms, " ")
+}
+
+// pair objects of expected and actual by key, and report objects missing in either side
+type driftPairs struct {
+	table  TableName
+	object string
+	drifts []Drift
+}
+
+func (d *driftPairs) compare(name string, expected, actual string) {
+	if expected != actual {
+		d.drifts = append(d.drifts, Drift{Kind: DriftMismatch, Table: d.table, Object: d.object, Name: name, Expected: expected, Actual: actual})
+	}
+}
+
+func (d *driftPairs) missing(name string, expected string) {
+	d.drifts = append(d.drifts, Drift{Kind: DriftMissing, Table: d.table, Object: d.object, Name: name, Expected: expected})
+}
+
+func (d *driftPairs) extra(name string, actual string) {
+	d.drifts = append(d.drifts, Drift{Kind: DriftExtra, Table: d.table, Object: d.object, Name: name, Actual: actual})
+}
+
+func driftColumns(t, a *Table, dialect Dialect) []Drift {
+	d := &driftPairs{table: t.Name, object: "column"}
+	for _, c := range t.Columns {
+		if ac, ok := findColumnFold(a, c.Name); ok {
+			d.compare(c.Name, driftColumnDefinition(t, c, dialect), driftColumnDefinition(a, ac, dialect))
+		} else {
+			d.missing(c.Name, driftColumnDefinition(t, c, dialect))
+		}
+	}
+	for _, ac := range a.Columns {
+		if _, ok := findColumnFold(t, ac.Name); !ok {
+			d.extra(ac.Name, driftColumnDefinition(a, ac, dialect))
+		}
+	}
+	return d.drifts
+}
+
+func findColumnFold(t *Table, name string) (*Column, bool) {
+	for _, c := range t.Columns {
+		if strings.EqualFold(c.Name, name) {
+			return c, true
+		}
+	}
+	return nil, false
+}
+
+// indexes are paired by name, and then by definition, as MySQL names unnamed indexes after their first column
+func driftIndexes(t, a *Table) []Drift {
+	d := &driftPairs{table: t.Name, object: "index"}
+	paired := map[*Index]bool{}
+	unpaired := []*Index{}
+	for _, index := range t.Indexes {
+		found := false
+		for _, ai := range a.Indexes {
+			if index.Name != "" && !paired[ai] && strings.EqualFold(index.Name, ai.Name) {
+				d.compare(index.Name, driftIndexDefinition(index), driftIndexDefinition(ai))
+				paired[ai] = true
+				found = true
+				break
+			}
+		}
+		if !found {
+			unpaired = append(unpaired, index)
+		}
+	}
+	for _, index := range unpaired {
+		found := false
+		for _, ai := range a.Indexes {
+			if !paired[ai] && driftIndexDefinition(index) == driftIndexDefinition(ai) {
+				paired[ai] = true
+				found = true
+				break
+			}
+		}
+		if !found {
+			d.missing(index.Name, driftIndexDefinition(index))
+		}
+	}
+	for _, ai := range a.Indexes {
+		if paired[ai] {
+			continue
+		}
+		// InnoDB creates an index for foreign key columns without one
+		isForeignKeyIndex := false
+		for _, fk := range a.ForeignKeys {
+			if strings.EqualFold(fk.Name, ai.Name) || !ai.Unique && driftKeyParts(fk.Columns) == driftKeyParts(ai.Columns) {
+				isForeignKeyIndex = true
+			}
+		}
+		if !isForeignKeyIndex {
+			d.extra(ai.Name, driftIndexDefinition(ai))
+		}
+	}
+	return d.drifts
+}
+
+// foreign keys are paired by columns, as MySQL names unnamed ones <table>_ibfk_<n>
+func driftForeignKeys(t, a *Table) []Drift {
+	d := &driftPairs{table: t.Name, object: "foreign key"}
+	paired := map[*ForeignKey]bool{}
+	for _, fk := range t.ForeignKeys {
+		found := false
+		for _, afk := range a.ForeignKeys {
+			if !paired[afk] && driftKeyParts(fk.Columns) == driftKeyParts(afk.Columns) {
+				d.compare(fk.Name, driftForeignKeyDefinition(fk), driftForeignKeyDefinition(afk))
+				paired[afk] = true
+				found = true
+				break
+			}
+		}
+		if !found {
+			d.missing(fk.Name, driftForeignKeyDefinition(fk))
+		}
+	}
+	for _, afk := range a.ForeignKeys {
+		if !paired[afk] {
+			d.extra(afk.Name, driftForeignKeyDefinition(afk))
+		}
+	}
+	return d.drifts
+}
+
+// charset and collation are compared only when the schema specifies them
+func driftTableOptions(t, a *Table) []Drift {
+	d := &driftPairs{table: t.Name, object: "table option"}
+	if t.Charset != "" {
+		d.compare("charset", strings.ToLower(t.Charset), strings.ToLower(a.Charset))
+	}
+	if t.Collation != "" {
+		d.compare("collation", strings.ToLower(t.Collation), strings.ToLower(a.Collation))
+	}
+	if t.Partition != "" || a.Partition != "" {
+		d.compare("partition", driftPartition(t.Partition), driftPartition(a.Partition))
+	}
+	return d.drifts
+}
+
+// tables of the dump are matched by name. the database is compared only when the dump qualifies the name
+func findActualTable(t *Table, actual []*Table) (*Table, bool) {
+	for _, a := range actual {
+		if !strings.EqualFold(t.Name.Name, a.Name.Name) {
+			continue
+		}
+		if a.Name.Database == "" || strings.EqualFold(t.Name.Database, a.Name.Database) {
+			return a, true
+		}
+	}
+	return nil, false
+}
+
+// differences between the generated schema and tables in the database, e.g. parsed from SHOW CREATE TABLE.
+// expressions of defaults, generated columns and checks are not compared, as MySQL rewrites them.
+// opts.Dialect is the dialect expected was built with
+func FindDrift(expected *Schema, actual []*Table, opts Options) []Drift {
+	ret := []Drift{}
+	matched := map[*Table]bool{}
+	for _, t := range expected.Tables {
+		a, ok := findActualTable(t, actual)
+		if !ok {
+			ret = append(ret, Drift{Kind: DriftMissing, Table: t.Name, Object: "table", Expected: fmt.Sprintf("%d columns", len(t.Columns))})
+			continue
+		}
+		matched[a] = true
+		ret = append(ret, driftColumns(t, a, opts.Dialect)...)
+		d := &driftPairs{table: t.Name, object: "primary key"}
+		d.compare("", driftKeyParts(t.PrimaryKey), driftKeyParts(a.PrimaryKey))
+		ret = append(ret, d.drifts...)
+		ret = append(ret, driftIndexes(t, a)...)
+		ret = append(ret, driftForeignKeys(t, a)...)
+		ret = append(ret, driftTableOptions(t, a)...)
+	}
+	for _, a := range actual {
+		if !matched[a] && !strings.EqualFold(a.Name.Name, FingerprintTable) {
+			ret = append(ret, Drift{Kind: DriftExtra, Table: a.Name, Object: "table", Actual: fmt.Sprintf("%d columns", len(a.Columns))})
+		}
+	}
+	return ret
+}
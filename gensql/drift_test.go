@@ -0,0 +1,144 @@
+package gensql
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestFindDrift(t *testing.T) {
+	dep, f := parseMessages(t, `
+		message_type {
+			name: "User"
+			field { name: "id" number: 1 type: TYPE_INT64 label: LABEL_OPTIONAL options { [mySQLColumn] { autoIncrement: true } } }
+			field { name: "name" number: 2 type: TYPE_STRING label: LABEL_OPTIONAL options { [mySQLType] { typeName: "VARCHAR" args: ["64"] } } }
+			field { name: "age" number: 3 type: TYPE_INT32 label: LABEL_OPTIONAL proto3_optional: true }
+			options { [mySQLTable] { primaryKey: ["id"] indexes { name: "name_idx" columns: ["name"] } } }
+		}`)
+	expected, err := BuildSchema(dep, f, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		ddl    string
+		drifts []string
+	}{
+		{
+			name: "as generated",
+			ddl:  genSchemaSQL(expected, Options{}),
+		},
+		{
+			name: "as shown by MySQL",
+			ddl: "CREATE TABLE `User` (\n" +
+				"  `id` bigint NOT NULL AUTO_INCREMENT,\n" +
+				"  `name` varchar(64) NOT NULL,\n" +
+				"  `age` int(11) DEFAULT NULL,\n" +
+				"  `PROTO_BINARY` blob NOT NULL,\n" +
+				"  PRIMARY KEY (`id`),\n" +
+				"  KEY `name_idx` (`name`)\n" +
+				") ENGINE=InnoDB DEFAULT CHARSET=utf8mb4",
+		},
+		{
+			name: "changed",
+			ddl:  "CREATE TABLE User (id BIGINT NOT NULL AUTO_INCREMENT, name varchar(32) NOT NULL, age bigint, extra int, PROTO_BINARY blob NOT NULL, PRIMARY KEY (id), KEY other (age))",
+			drifts: []string{
+				"mismatched column User.name: expected VARCHAR(64) NOT NULL, actual VARCHAR(32) NOT NULL",
+				"mismatched column User.age: expected INT NULL, actual BIGINT NULL",
+				"extra column User.extra: INT NULL",
+				"missing index User.name_idx: INDEX (name)",
+				"extra index User.other: INDEX (age)",
+			},
+		},
+		{
+			name: "other table",
+			ddl:  "CREATE TABLE Other (id int)",
+			drifts: []string{
+				"missing table User: 4 columns",
+				"extra table Other: 1 columns",
+			},
+		},
+		{
+			name: "fingerprint table",
+			ddl:  genSchemaSQL(expected, Options{}) + "\n" + genFingerprintTableSQL(""),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			actual, err := ParseCreateTables(tt.ddl)
+			if err != nil {
+				t.Fatal(err)
+			}
+			drifts := []string{}
+			for _, d := range FindDrift(expected, actual, Options{}) {
+				drifts = append(drifts, d.String())
+			}
+			if tt.drifts == nil {
+				tt.drifts = []string{}
+			}
+			if !reflect.DeepEqual(drifts, tt.drifts) {
+				t.Errorf("drifts = %q, want %q", drifts, tt.drifts)
+			}
+		})
+	}
+}
+
+func TestFindDriftMariaDBJSON(t *testing.T) {
+	dep, f := parseMessages(t, `
+		message_type {
+			name: "User"
+			field { name: "id" number: 1 type: TYPE_INT64 label: LABEL_OPTIONAL }
+			field { name: "tags" number: 2 type: TYPE_STRING label: LABEL_REPEATED }
+			options { [mySQLTable] { primaryKey: ["id"] } }
+		}`)
+	// SHOW CREATE TABLE of the generated DDL on MariaDB 10.6
+	dump := "CREATE TABLE `User` (\n" +
+		"  `id` bigint(20) NOT NULL,\n" +
+		"  `tags` longtext CHARACTER SET utf8mb4 COLLATE utf8mb4_bin NOT NULL CHECK (json_valid(`tags`)),\n" +
+		"  `PROTO_BINARY` blob NOT NULL,\n" +
+		"  PRIMARY KEY (`id`)\n" +
+		") ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_general_ci"
+	tests := []struct {
+		name    string
+		dialect Dialect
+		ddl     string
+		drifts  []string
+	}{
+		{name: "as shown by MariaDB", dialect: DialectMariaDB, ddl: dump},
+		{
+			name:    "LONGTEXT without json_valid",
+			dialect: DialectMariaDB,
+			ddl:     strings.Replace(dump, " CHECK (json_valid(`tags`))", "", 1),
+			drifts:  []string{"mismatched column User.tags: expected JSON NOT NULL, actual LONGTEXT NOT NULL"},
+		},
+		{
+			name:   "LONGTEXT on MySQL",
+			ddl:    dump,
+			drifts: []string{"mismatched column User.tags: expected JSON NOT NULL, actual LONGTEXT NOT NULL"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := Options{Dialect: tt.dialect}
+			expected, err := BuildSchema(dep, f, opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			actual, err := ParseCreateTables(tt.ddl)
+			if err != nil {
+				t.Fatal(err)
+			}
+			drifts := []string{}
+			for _, d := range FindDrift(expected, actual, opts) {
+				drifts = append(drifts, d.String())
+			}
+			if tt.drifts == nil {
+				tt.drifts = []string{}
+			}
+			if !reflect.DeepEqual(drifts, tt.drifts) {
+				t.Errorf("drifts = %q, want %q", drifts, tt.drifts)
+			}
+		})
+	}
+}
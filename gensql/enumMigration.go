@@ -0,0 +1,145 @@
+package gensql
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ENUM value renamed with its proto number kept
+type enumRename struct {
+	Old string
+	New string
+}
+
+func hasEnumValue(values []EnumValue, name string) bool {
+	for _, v := range values {
+		if v.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+func hasEnumNumber(values []EnumValue, number int32) bool {
+	for _, v := range values {
+		if v.Number == number {
+			return true
+		}
+	}
+	return false
+}
+
+// compare ENUM values of o and n by proto number.
+// values whose number disappeared are removed, and those whose number got a new name are renamed
+func diffEnumValues(o, n *Column) (renames []enumRename, removed []string) {
+	for _, v := range o.Enum {
+		if hasEnumValue(n.Enum, v.Name) {
+			continue
+		}
+		if !hasEnumNumber(n.Enum, v.Number) {
+			removed = append(removed, v.Name)
+			continue
+		}
+		for _, nv := range n.Enum {
+			if nv.Number == v.Number && !hasEnumValue(o.Enum, nv.Name) {
+				renames = append(renames, enumRename{Old: v.Name, New: nv.Name})
+				break
+			}
+		}
+	}
+	return renames, removed
+}
+
+// column between the two ALTERs of renaming ENUM values, which has both old and new names.
+// the new names are appended, so that the first ALTER is done in place. nil if nothing is renamed
+func enumRenameStep(o, n *Column) (*Column, []enumRename) {
+	if o.Enum == nil || n.Enum == nil {
+		return nil, nil
+	}
+	renames, _ := diffEnumValues(o, n)
+	if len(renames) == 0 {
+		return nil, nil
+	}
+	step := *n
+	step.Enum = append([]EnumValue{}, o.Enum...)
+	for _, v := range n.Enum {
+		if !hasEnumValue(step.Enum, v.Name) {
+			step.Enum = append(step.Enum, v)
+		}
+	}
+	names := make([]string, 0, len(step.Enum))
+	for _, v := range step.Enum {
+		names = append(names, v.Name)
+	}
+	step.Type = MySQLDataTypeWithArgs{ENUM, quoteEnumValues(names)}
+	return &step, renames
+}
+
+// caveats of changing ENUM values from o to n. values can be changed in place only by appending them at the end
+func enumChangeWarnings(table TableName, o, n *Column) []string {
+	if o.Enum == nil || n.Enum == nil {
+		return nil
+	}
+	ret := []string{}
+	_, removed := diffEnumValues(o, n)
+	if len(removed) > 0 {
+		ret = append(ret, fmt.Sprintf("values %s of ENUM column %s.%s are removed, so rows holding them are rejected", strings.Join(removed, ","), table, n.Name))
+	}
+	appended := len(o.Enum) <= len(n.Enum)
+	for i := 0; appended && i < len(o.Enum); i++ {
+		appended = o.Enum[i].Name == n.Enum[i].Name
+	}
+	if !appended {
+		ret = append(ret, fmt.Sprintf("values of ENUM column %s.%s are not appended at the end, so the table is copied", table, n.Name))
+	}
+	return ret
+}
+
+// values of o missing in n, even after renames
+func removedEnumValues(o, n *Column) []string {
+	_, removed := diffEnumValues(o, n)
+	return removed
+}
+
+// values kept by name in n whose proto number changed. stored messages read them as other values
+func renumberedEnumValues(o, n *Column) []string {
+	ret := []string{}
+	for _, v := range o.Enum {
+		for _, nv := range n.Enum {
+			if nv.Name == v.Name && nv.Number != v.Number {
+				ret = append(ret, v.Name)
+			}
+		}
+	}
+	return ret
+}
+
+// whether values of o are kept at the head of n in the same order, compared by number
+// as renamed values keep it. otherwise stored indexes of the ENUM change meaning
+func enumOrderKept(o, n *Column) bool {
+	// a value renamed in two steps appears twice in the middle column
+	numbers := []int32{}
+	for _, v := range o.Enum {
+		if !hasNumber(numbers, v.Number) {
+			numbers = append(numbers, v.Number)
+		}
+	}
+	if len(numbers) > len(n.Enum) {
+		return false
+	}
+	for i, number := range numbers {
+		if n.Enum[i].Number != number {
+			return false
+		}
+	}
+	return true
+}
+
+func hasNumber(numbers []int32, number int32) bool {
+	for _, n := range numbers {
+		if n == number {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,80 @@
+package gensql
+
+import (
+	"reflect"
+	"testing"
+)
+
+// schema of a user whose gender column is an ENUM of the given values
+func buildEnumSchema(t *testing.T, values string) *Schema {
+	t.Helper()
+	dep, f := parseMessages(t, `
+		message_type {
+			name: "User"
+			field { name: "gender" number: 1 type: TYPE_ENUM type_name: ".Foo.Gender" label: LABEL_OPTIONAL }
+			options { [mySQLTable] {} }
+		}
+		enum_type { name: "Gender" `+values+` }`)
+	s, err := BuildSchema(dep, f, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func TestEnumMigration(t *testing.T) {
+	const base = `value { name: "UNKNOWN" number: 0 } value { name: "MALE" number: 1 } value { name: "FEMALE" number: 2 }`
+	tests := []struct {
+		name     string
+		new      string
+		sql      string
+		warnings []string
+	}{
+		{
+			name:     "append",
+			new:      base + ` value { name: "OTHER" number: 3 }`,
+			sql:      "ALTER TABLE User\n\tMODIFY COLUMN gender ENUM('UNKNOWN','MALE','FEMALE','OTHER') NOT NULL;",
+			warnings: []string{},
+		},
+		// renamed in two steps, so that rows keep the value
+		{
+			name: "rename",
+			new:  `value { name: "UNKNOWN" number: 0 } value { name: "MAN" number: 1 } value { name: "FEMALE" number: 2 }`,
+			sql: "ALTER TABLE User\n\tMODIFY COLUMN gender ENUM('UNKNOWN','MALE','FEMALE','MAN') NOT NULL;\n\n" +
+				"UPDATE User SET gender = 'MAN' WHERE gender = 'MALE';\n\n" +
+				"-- values of ENUM column User.gender are not appended at the end, so the table is copied\n" +
+				"ALTER TABLE User\n\tMODIFY COLUMN gender ENUM('UNKNOWN','MAN','FEMALE') NOT NULL;",
+			warnings: []string{"values of ENUM column User.gender are not appended at the end, so the table is copied"},
+		},
+		{
+			name: "remove",
+			new:  `value { name: "UNKNOWN" number: 0 } value { name: "MALE" number: 1 }`,
+			sql: "-- values FEMALE of ENUM column User.gender are removed, so rows holding them are rejected\n" +
+				"-- values of ENUM column User.gender are not appended at the end, so the table is copied\n" +
+				"ALTER TABLE User\n\tMODIFY COLUMN gender ENUM('UNKNOWN','MALE') NOT NULL;",
+			warnings: []string{"values FEMALE of ENUM column User.gender are removed, so rows holding them are rejected", "values of ENUM column User.gender are not appended at the end, so the table is copied"},
+		},
+		{
+			name: "insert",
+			new:  `value { name: "UNKNOWN" number: 0 } value { name: "MALE" number: 1 } value { name: "OTHER" number: 2 } value { name: "FEMALE" number: 3 }`,
+			sql: "-- values of ENUM column User.gender are not appended at the end, so the table is copied\n" +
+				"ALTER TABLE User\n\tMODIFY COLUMN gender ENUM('UNKNOWN','MALE','OTHER','FEMALE') NOT NULL;",
+			warnings: []string{"values of ENUM column User.gender are not appended at the end, so the table is copied"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			changes := DiffSchema(buildEnumSchema(t, base), buildEnumSchema(t, tt.new), Options{})
+			if sql := GenMigrationSQL(changes); sql != tt.sql {
+				t.Errorf("sql = \n%s\nwant\n%s", sql, tt.sql)
+			}
+			warnings := []string{}
+			for _, c := range changes {
+				warnings = append(warnings, c.Warnings...)
+			}
+			if !reflect.DeepEqual(warnings, tt.warnings) {
+				t.Errorf("warnings = %q, want %q", warnings, tt.warnings)
+			}
+		})
+	}
+}
@@ -0,0 +1,185 @@
+package gensql
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/Mojashi/proto-mysql/dep"
+	"github.com/golang/protobuf/protoc-gen-go/descriptor"
+	"google.golang.org/protobuf/proto"
+)
+
+// table which records the descriptor each table was generated from
+const FingerprintTable = "proto_mysql_schema"
+
+// descriptor in deterministic wire format.
+// compiled-in descriptors keep options as protoc wrote them, so they are parsed again
+// with the registered extensions, which are then written in the order of field numbers
+func canonicalDescriptor(m proto.Message) ([]byte, error) {
+	buf, err := proto.Marshal(m)
+	if err != nil {
+		return nil, err
+	}
+	canonical := m.ProtoReflect().New().Interface()
+	if err := proto.Unmarshal(buf, canonical); err != nil {
+		return nil, err
+	}
+	return proto.MarshalOptions{Deterministic: true}.Marshal(canonical)
+}
+
+// top-level message or enum which declares the type, and its full name
+func topLevelType(dep dep.INameSpace, typeName string) (string, proto.Message, bool) {
+	path := strings.Split(typeName, ".")
+	for i := 1; i <= len(path); i++ {
+		if msg, ok := dep.GetMessage(path[:i]); ok {
+			return strings.Join(path[:i], "."), msg.GetDescriptor(), true
+		}
+	}
+	if enum, ok := dep.GetEnum(path); ok {
+		return typeName, enum.GetEnum(), true
+	}
+	return "", nil, false
+}
+
+// top-level messages and enums which fields of mt refer to, directly or through other messages
+func referencedTypes(dep dep.INameSpace, mt *descriptor.DescriptorProto, types map[string]proto.Message) error {
+	for _, field := range mt.Field {
+		if field.GetTypeName() == "" {
+			continue
+		}
+		name, d, ok := topLevelType(dep, field.GetTypeName())
+		if !ok {
+			return fmt.Errorf("failed to find type %s of field %s", field.GetTypeName(), field.GetName())
+		}
+		if _, ok := types[name]; ok {
+			continue
+		}
+		types[name] = d
+		if msg, ok := d.(*descriptor.DescriptorProto); ok {
+			if err := referencedTypes(dep, msg, types); err != nil {
+				return err
+			}
+		}
+	}
+	for _, nested := range mt.NestedType {
+		if err := referencedTypes(dep, nested, types); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// sha256 of the message descriptor and the types it refers to in deterministic wire format, as hex.
+// changes of enums and messages stored in the columns change the fingerprint.
+// the same message compiled into an application has the same fingerprint
+func MessageFingerprint(dep dep.INameSpace, mt *descriptor.DescriptorProto) (string, error) {
+	buf, err := canonicalDescriptor(mt)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal message %s: %v", mt.GetName(), err)
+	}
+	types := map[string]proto.Message{}
+	if err := referencedTypes(dep, mt, types); err != nil {
+		return "", fmt.Errorf("failed to fingerprint message %s: %v", mt.GetName(), err)
+	}
+	names := make([]string, 0, len(types))
+	for name := range types {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	h := sha256.New()
+	h.Write(buf)
+	for _, name := range names {
+		buf, err := canonicalDescriptor(types[name])
+		if err != nil {
+			return "", fmt.Errorf("failed to marshal %s: %v", name, err)
+		}
+		// the name and the length keep the boundaries of descriptors
+		fmt.Fprintf(h, "\n%s %d\n", name, len(buf))
+		h.Write(buf)
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// fingerprint table in the database of t
+func fingerprintTableName(t TableName) TableName {
+	return TableName{Database: t.Database, Name: FingerprintTable}
+}
+
+// SELECT of the fingerprint recorded for table t
+func FingerprintSelectSQL(t TableName) string {
+	return fmt.Sprintf("SELECT fingerprint FROM %s WHERE table_name = '%s'", fingerprintTableName(t), t.Name)
+}
+
+func genFingerprintTableSQL(database string) string {
+	return fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (
+	table_name VARCHAR(64) NOT NULL,
+	message VARCHAR(255) NOT NULL,
+	fingerprint CHAR(64) NOT NULL,
+	updated_at DATETIME(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6) ON UPDATE CURRENT_TIMESTAMP(6),
+	PRIMARY KEY (table_name)
+);`, fingerprintTableName(TableName{Database: database}))
+}
+
+// REPLACE of the fingerprints of tables. tables must be in the same database
+func genFingerprintReplaceSQL(database string, tables []*Table) string {
+	rows := make([]string, 0, len(tables))
+	for _, t := range tables {
+		rows = append(rows, fmt.Sprintf("\t('%s', '%s', '%s')", t.Name.Name, t.Message, t.Fingerprint))
+	}
+	return fmt.Sprintf("REPLACE INTO %s (table_name, message, fingerprint) VALUES\n%s;",
+		fingerprintTableName(TableName{Database: database}), strings.Join(rows, ",\n"))
+}
+
+// CREATE and REPLACE of the fingerprint table for the tables of s
+func genFingerprintSQL(s *Schema) string {
+	return genFingerprintTableSQL(s.Database) + "\n\n" + genFingerprintReplaceSQL(s.Database, s.Tables)
+}
+
+// statements which record the fingerprints of new, and delete those of tables gone since old.
+// nothing is changed when the fingerprints are the same
+func diffFingerprints(old, new *Schema) []*Change {
+	database := new.Database
+	if database == "" {
+		database = old.Database
+	}
+	oldFingerprints := map[string]string{}
+	for _, o := range old.Tables {
+		oldFingerprints[strings.ToLower(o.Name.Name)] = o.Fingerprint
+	}
+	newTables := map[string]bool{}
+	changed := []*Table{}
+	for _, t := range new.Tables {
+		newTables[strings.ToLower(t.Name.Name)] = true
+		if oldFingerprints[strings.ToLower(t.Name.Name)] != t.Fingerprint {
+			changed = append(changed, t)
+		}
+	}
+	gone := []string{}
+	for _, o := range old.Tables {
+		if !newTables[strings.ToLower(o.Name.Name)] {
+			gone = append(gone, "'"+o.Name.Name+"'")
+		}
+	}
+	sort.Strings(gone)
+	if len(changed) == 0 && len(gone) == 0 {
+		return nil
+	}
+
+	name := fingerprintTableName(TableName{Database: database})
+	changes := []*Change{{Kind: ChangeFingerprint, Table: name, SQL: genFingerprintTableSQL(database)}}
+	if len(changed) > 0 {
+		changes = append(changes, &Change{Kind: ChangeFingerprint, Table: name, SQL: genFingerprintReplaceSQL(database, changed)})
+	}
+	if len(gone) > 0 {
+		changes = append(changes, &Change{
+			Kind:  ChangeFingerprint,
+			Table: name,
+			SQL:   fmt.Sprintf("DELETE FROM %s WHERE table_name IN (%s);", name, strings.Join(gone, ", ")),
+		})
+	}
+	return changes
+}
@@ -0,0 +1,62 @@
+package gensql
+
+import "testing"
+
+func TestMessageFingerprint(t *testing.T) {
+	const (
+		user    = `message_type { name: "User" field { name: "gender" number: 1 type: TYPE_ENUM type_name: ".Foo.Gender" label: LABEL_OPTIONAL } field { name: "profile" number: 2 type: TYPE_MESSAGE type_name: ".Foo.Profile" label: LABEL_OPTIONAL } }`
+		profile = `message_type { name: "Profile" field { name: "address" number: 1 type: TYPE_MESSAGE type_name: ".Foo.Profile.Address" label: LABEL_OPTIONAL } nested_type { name: "Address" field { name: "country" number: 1 type: TYPE_ENUM type_name: ".Foo.Country" label: LABEL_OPTIONAL } } }`
+		gender  = `enum_type { name: "Gender" value { name: "MALE" number: 0 } }`
+		country = `enum_type { name: "Country" value { name: "JP" number: 0 } }`
+		other   = `message_type { name: "Other" field { name: "id" number: 1 type: TYPE_INT64 label: LABEL_OPTIONAL } }`
+	)
+	fingerprint := func(t *testing.T, types string) string {
+		t.Helper()
+		dep, f := parseMessages(t, types)
+		fp, err := MessageFingerprint(dep, f.MessageType[0])
+		if err != nil {
+			t.Fatal(err)
+		}
+		return fp
+	}
+	base := fingerprint(t, user+profile+gender+country+other)
+	tests := []struct {
+		name    string
+		types   string
+		changed bool
+	}{
+		{name: "same", types: user + profile + gender + country + other},
+		{name: "declared in another order", types: user + other + country + gender + profile},
+		{name: "unrelated message changed", types: user + profile + gender + country},
+		{
+			name:    "referenced enum changed",
+			types:   user + profile + `enum_type { name: "Gender" value { name: "MALE" number: 0 } value { name: "FEMALE" number: 1 } }` + country + other,
+			changed: true,
+		},
+		{
+			name:    "enum of nested message changed",
+			types:   user + profile + gender + `enum_type { name: "Country" value { name: "US" number: 0 } }` + other,
+			changed: true,
+		},
+		{
+			name:    "message changed",
+			types:   `message_type { name: "User" field { name: "gender" number: 1 type: TYPE_ENUM type_name: ".Foo.Gender" label: LABEL_OPTIONAL } }` + gender + other,
+			changed: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if changed := fingerprint(t, tt.types) != base; changed != tt.changed {
+				t.Errorf("fingerprint changed = %v, want %v", changed, tt.changed)
+			}
+		})
+	}
+}
+
+func TestMessageFingerprintUnknownType(t *testing.T) {
+	dep, f := parseMessages(t, `
+		message_type { name: "User" field { name: "gender" number: 1 type: TYPE_ENUM type_name: ".Foo.Gender" label: LABEL_OPTIONAL } }`)
+	if _, err := MessageFingerprint(dep, f.MessageType[0]); err == nil {
+		t.Error("fingerprint of message referring to unknown type is computed")
+	}
+}
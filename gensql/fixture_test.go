@@ -0,0 +1,57 @@
+package gensql
+
+import (
+	"testing"
+
+	"github.com/Mojashi/proto-mysql/dep"
+	"github.com/golang/protobuf/protoc-gen-go/descriptor"
+	plugin "github.com/golang/protobuf/protoc-gen-go/plugin"
+	"google.golang.org/protobuf/encoding/prototext"
+)
+
+// file descriptor written in text format. options of mySQLOptions.proto are written as extensions,
+// e.g. options { [mySQLTable] { primaryKey: "id" } }
+func parseFile(t *testing.T, text string) (dep.INameSpace, *descriptor.FileDescriptorProto) {
+	t.Helper()
+	deps, files := parseFiles(t, text)
+	return deps[0], files[0]
+}
+
+// files of one request, which may import each other, and their dependencies
+func parseFiles(t *testing.T, texts ...string) ([]dep.INameSpace, []*descriptor.FileDescriptorProto) {
+	t.Helper()
+	req := &plugin.CodeGeneratorRequest{}
+	for _, text := range texts {
+		f := &descriptor.FileDescriptorProto{}
+		if err := prototext.Unmarshal([]byte(text), f); err != nil {
+			t.Fatalf("failed to parse file descriptor: %v", err)
+		}
+		req.ProtoFile = append(req.ProtoFile, f)
+	}
+	deps := make([]dep.INameSpace, 0, len(req.ProtoFile))
+	for _, f := range req.ProtoFile {
+		deps = append(deps, dep.AnalyzeDependency(req, f))
+	}
+	return deps, req.ProtoFile
+}
+
+// user.proto of package Foo, which declares messages and enums written in text format.
+// e.g. message_type { name: "User" } enum_type { name: "Gender" }
+func parseMessages(t *testing.T, decls string) (dep.INameSpace, *descriptor.FileDescriptorProto) {
+	t.Helper()
+	return parseFile(t, `name: "user.proto" package: "Foo" syntax: "proto3" `+decls)
+}
+
+// enum which fixtures of parseMessages refer to as .Foo.Gender
+const genderEnum = `enum_type { name: "Gender" value { name: "MALE" number: 0 } value { name: "FEMALE" number: 1 } }`
+
+// schema of parseMessages, whose messages may refer to genderEnum
+func buildSchema(t *testing.T, messages string) *Schema {
+	t.Helper()
+	dep, f := parseMessages(t, messages+genderEnum)
+	s, err := BuildSchema(dep, f, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
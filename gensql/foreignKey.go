@@ -0,0 +1,70 @@
+package gensql
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Mojashi/proto-mysql/dep"
+	"github.com/golang/protobuf/protoc-gen-go/descriptor"
+)
+
+// references is relative to the package of f, or fully qualified with leading ".".
+// only top-level messages have tables, so the package is the full name without the last term.
+func resolveReference(dep dep.INameSpace, f *descriptor.FileDescriptorProto, references string) (*descriptor.DescriptorProto, string, error) {
+	fullName := strings.TrimPrefix(references, ".")
+	if !strings.HasPrefix(references, ".") && f.GetPackage() != "" {
+		fullName = f.GetPackage() + "." + references
+	}
+	msg, ok := dep.GetMessage(strings.Split(fullName, "."))
+	if !ok {
+		return nil, "", fmt.Errorf("referenced message %s is not found", references)
+	}
+	pkg := ""
+	if i := strings.LastIndex(fullName, "."); i >= 0 {
+		pkg = fullName[:i]
+	}
+	return msg.GetDescriptor(), pkg, nil
+}
+
+func buildForeignKey(dep dep.INameSpace, f *descriptor.FileDescriptorProto, fk *MySQLForeignKey, opts Options) (*ForeignKey, error) {
+	if len(fk.GetColumns()) == 0 {
+		return nil, fmt.Errorf("foreign key %s has no columns", fk.GetName())
+	}
+	ref, pkg, err := resolveReference(dep, f, fk.GetReferences())
+	if err != nil {
+		return nil, err
+	}
+	referencedColumns := fk.GetReferencedColumns()
+	if len(referencedColumns) == 0 {
+		refOpts, _ := CheckTableOptions(ref)
+		referencedColumns = refOpts.GetPrimaryKey()
+	}
+	if len(referencedColumns) != len(fk.GetColumns()) {
+		return nil, fmt.Errorf("foreign key %s has %d columns, but %d columns of %s are referenced",
+			fk.GetName(), len(fk.GetColumns()), len(referencedColumns), fk.GetReferences())
+	}
+	return &ForeignKey{
+		Name:              fk.GetName(),
+		Columns:           fk.GetColumns(),
+		ReferencedTable:   messageTableName(pkg, ref.GetName(), opts),
+		ReferencedColumns: referencedColumns,
+		OnDelete:          fk.GetOnDelete(),
+		OnUpdate:          fk.GetOnUpdate(),
+	}, nil
+}
+
+// e.g. "CONSTRAINT user_fk FOREIGN KEY (user_id) REFERENCES User (id) ON DELETE CASCADE"
+func genForeignKeyDefinition(fk *ForeignKey) string {
+	ret := fmt.Sprintf("FOREIGN KEY (%s) REFERENCES %s (%s)",
+		strings.Join(fk.Columns, ","), fk.ReferencedTable, strings.Join(fk.ReferencedColumns, ","))
+	if fk.Name != "" {
+		ret = fmt.Sprintf("CONSTRAINT %s %s", fk.Name, ret)
+	}
+	if fk.OnDelete != "" {
+		ret += " ON DELETE " + fk.OnDelete
+	}
+	if fk.OnUpdate != "" {
+		ret += " ON UPDATE " + fk.OnUpdate
+	}
+	return ret
+}
@@ -0,0 +1,83 @@
+package gensql
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestBuildForeignKey(t *testing.T) {
+	dep, f := parseMessages(t, `
+		message_type {
+			name: "Customer"
+			field { name: "id" number: 1 type: TYPE_INT64 label: LABEL_OPTIONAL }
+			options { [mySQLTable] { primaryKey: ["id"] } }
+		}`)
+	tests := []struct {
+		name string
+		fk   *MySQLForeignKey
+		opts Options
+		want *ForeignKey
+		sql  string
+		err  string
+	}{
+		{
+			name: "relative to the package",
+			fk:   &MySQLForeignKey{Name: "order_customer_fk", Columns: []string{"customer_id"}, References: "Customer", OnDelete: "CASCADE"},
+			want: &ForeignKey{Name: "order_customer_fk", Columns: []string{"customer_id"}, ReferencedTable: TableName{Name: "Customer"}, ReferencedColumns: []string{"id"}, OnDelete: "CASCADE"},
+			sql:  "CONSTRAINT order_customer_fk FOREIGN KEY (customer_id) REFERENCES Customer (id) ON DELETE CASCADE",
+		},
+		{
+			name: "fully qualified in database of package",
+			fk:   &MySQLForeignKey{Columns: []string{"customer_id"}, References: ".Foo.Customer", ReferencedColumns: []string{"id"}, OnUpdate: "SET NULL"},
+			opts: Options{PackageMapping: PackageMappingDatabase},
+			want: &ForeignKey{Columns: []string{"customer_id"}, ReferencedTable: TableName{Database: "foo", Name: "Customer"}, ReferencedColumns: []string{"id"}, OnUpdate: "SET NULL"},
+			sql:  "FOREIGN KEY (customer_id) REFERENCES `foo`.`Customer` (id) ON UPDATE SET NULL",
+		},
+		{
+			name: "unknown message",
+			fk:   &MySQLForeignKey{Name: "fk", Columns: []string{"customer_id"}, References: "Client"},
+			err:  "referenced message Client is not found",
+		},
+		{
+			name: "columns mismatch",
+			fk:   &MySQLForeignKey{Name: "fk", Columns: []string{"customer_id", "shop_id"}, References: "Customer"},
+			err:  "foreign key fk has 2 columns, but 1 columns of Customer are referenced",
+		},
+		{
+			name: "no columns",
+			fk:   &MySQLForeignKey{Name: "fk", References: "Customer"},
+			err:  "foreign key fk has no columns",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fk, err := buildForeignKey(dep, f, tt.fk, tt.opts)
+			if (err == nil) != (tt.err == "") || (err != nil && err.Error() != tt.err) {
+				t.Fatalf("err = %v, want %q", err, tt.err)
+			}
+			if err != nil {
+				return
+			}
+			if !reflect.DeepEqual(fk, tt.want) {
+				t.Errorf("foreign key = %+v, want %+v", fk, tt.want)
+			}
+			if sql := genForeignKeyDefinition(fk); sql != tt.sql {
+				t.Errorf("sql = %s, want %s", sql, tt.sql)
+			}
+		})
+	}
+}
+
+func TestBuildSchemaInvalidForeignKey(t *testing.T) {
+	dep, f := parseMessages(t, `
+		message_type {
+			name: "Purchase"
+			field { name: "customer_id" number: 1 type: TYPE_INT64 label: LABEL_OPTIONAL }
+			options { [mySQLTable] { foreignKeys { name: "fk" columns: ["customer_id"] references: "Client" } } }
+		}`)
+	_, err := BuildSchema(dep, f, Options{})
+	if want := "failed to process foreign key in message Purchase: referenced message Client is not found"; err == nil || !strings.Contains(err.Error(), want) {
+		t.Fatalf("error = %v, want %q", err, want)
+	}
+}
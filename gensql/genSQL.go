@@ -2,6 +2,7 @@ package gensql
 
 import (
 	"fmt"
+	"sort"
 	"strings"
 
 	"github.com/Mojashi/proto-mysql/dep"
@@ -30,6 +31,9 @@ func (t *MySQLDataTypeWithArgs) setArgs(args []string) {
 func (t MySQLDataTypeWithArgs) GetType() MySQLDataType {
 	return t.dataType
 }
+func (t MySQLDataTypeWithArgs) GetArgs() []string {
+	return t.args
+}
 
 const (
 	DOUBLE  MySQLDataType = "DOUBLE"
@@ -45,6 +49,11 @@ const (
 	JSON    MySQLDataType = "JSON"
 	CHAR    MySQLDataType = "CHAR"
 	VARCHAR MySQLDataType = "VARCHAR"
+	UUID    MySQLDataType = "UUID"
+	INET6   MySQLDataType = "INET6"
+	// bookkeeping columns
+	DATETIME MySQLDataType = "DATETIME"
+	TINYINT  MySQLDataType = "TINYINT"
 )
 
 var MySQLDataTypeMap = map[descriptor.FieldDescriptorProto_Type]MySQLDataType{
@@ -66,15 +75,31 @@ var MySQLDataTypeMap = map[descriptor.FieldDescriptorProto_Type]MySQLDataType{
 	descriptor.FieldDescriptorProto_TYPE_SINT64:   BIGINT,
 }
 
+// values ordered by number, so that new values are appended to ENUM and stored indexes are kept
+func enumValues(e *descriptor.EnumDescriptorProto) []EnumValue {
+	values := make([]EnumValue, 0, len(e.GetValue()))
+	for _, v := range e.GetValue() {
+		values = append(values, EnumValue{Name: v.GetName(), Number: v.GetNumber()})
+	}
+	sort.SliceStable(values, func(i, j int) bool { return values[i].Number < values[j].Number })
+	return values
+}
+
 func enumEnum(e *descriptor.EnumDescriptorProto) (names []string) {
-	vs := e.GetValue()
-	names = make([]string, 0, len(vs))
-	for i := 0; len(vs) > i; i++ {
-		names = append(names, string(vs[i].GetName()))
+	for _, v := range enumValues(e) {
+		names = append(names, v.Name)
 	}
 	return names
 }
 
+func quoteEnumValues(names []string) []string {
+	ret := make([]string, 0, len(names))
+	for _, name := range names {
+		ret = append(ret, "'"+name+"'")
+	}
+	return ret
+}
+
 func CheckSpecifiedType(dep dep.INameSpace, field *descriptor.FieldDescriptorProto) (MySQLDataTypeWithArgs, bool) {
 	opts := field.GetOptions()
 	if opts == nil {
@@ -100,17 +125,27 @@ func GenMySQLDataType(dep dep.INameSpace, field *descriptor.FieldDescriptorProto
 		return cand, nil
 	}
 
+	if err := checkSemantic(field); err != nil {
+		return ret, err
+	}
+	switch GetSemantic(field) {
+	case MySQLColumn_UUID:
+		return MySQLDataTypeWithArgs{CHAR, []string{"36"}}, nil
+	case MySQLColumn_INET6:
+		return MySQLDataTypeWithArgs{VARCHAR, []string{"39"}}, nil
+	}
+
 	if field.Type != nil {
 		var mType MySQLDataType
 		var ok bool
 		if mType, ok = MySQLDataTypeMap[field.GetType()]; !ok {
 			// Message type
-			ret = MySQLDataTypeWithArgs{JSON, nil}
+			mType = JSON
 		}
 		switch mType {
 		case ENUM:
 			if enum, ok := dep.GetEnum(strings.Split(field.GetTypeName(), ".")); ok {
-				ret = MySQLDataTypeWithArgs{mType, enumEnum(enum.GetEnum())}
+				ret = MySQLDataTypeWithArgs{mType, quoteEnumValues(enumEnum(enum.GetEnum()))}
 			} else {
 				glog.Errorf("failed to find ENUM %s", field.GetTypeName())
 				return MySQLDataTypeWithArgs{mType, nil}, fmt.Errorf("failed to find ENUM")
@@ -133,56 +168,269 @@ func GenMySQLDataType(dep dep.INameSpace, field *descriptor.FieldDescriptorProto
 	return ret, nil
 }
 
-func genColumnDefinition(dep dep.INameSpace, field *descriptor.FieldDescriptorProto) (string, error) {
-	dataType, err := GenMySQLDataType(dep, field)
-	nullable := "NOT NULL"
-	if field.GetProto3Optional() {
-		nullable = "NULL"
-	}
-	defaultValule := ""
-	if field.DefaultValue != nil {
-		defaultValule = fmt.Sprintf("DEFAULT %s", field.GetDefaultValue())
+// MariaDB has native UUID (10.7+) and INET6 (10.5+) types
+func GenMariaDBDataType(dep dep.INameSpace, field *descriptor.FieldDescriptorProto) (MySQLDataTypeWithArgs, error) {
+	if _, ok := CheckSpecifiedType(dep, field); !ok && checkSemantic(field) == nil {
+		switch GetSemantic(field) {
+		case MySQLColumn_UUID:
+			return MySQLDataTypeWithArgs{UUID, nil}, nil
+		case MySQLColumn_INET6:
+			return MySQLDataTypeWithArgs{INET6, nil}, nil
+		}
 	}
-
-	return fmt.Sprintf("%s %s %s", dataType.ToString(), nullable, defaultValule), err
+	return GenMySQLDataType(dep, field)
 }
 
-// return column definition. e.g. "id INTEGER NOT NULL"
-func genCreateDefinition(dep dep.INameSpace, field *descriptor.FieldDescriptorProto) (string, error) {
-	columnDefinition, err := genColumnDefinition(dep, field)
+// column holding field. errors are returned with the best-effort column
+func buildColumn(dep dep.INameSpace, mt *descriptor.DescriptorProto, field *descriptor.FieldDescriptorProto, fullName string, opts Options) (*Column, []*Check, error) {
+	var dataType MySQLDataTypeWithArgs
+	var err error
+	if opts.Dialect == DialectMariaDB {
+		dataType, err = GenMariaDBDataType(dep, field)
+	} else {
+		dataType, err = GenMySQLDataType(dep, field)
+	}
 	if field.GetName() == "" {
 		err = errors.Wrap(err, "field name is empty")
 	}
-	return fmt.Sprintf("%s %s", field.GetName(), columnDefinition), err
+	c := &Column{
+		Name:     field.GetName(),
+		Type:     dataType,
+		Nullable: field.GetProto3Optional(),
+		Field: &FieldRef{
+			Name:     field.GetName(),
+			Number:   field.GetNumber(),
+			FullName: fullName + "." + field.GetName(),
+		},
+	}
+	if field.DefaultValue != nil {
+		c.Default = field.GetDefaultValue()
+	}
+	column, _ := CheckColumnOptions(field)
+	if column.GetDefaultExpression() != "" {
+		c.Default = fmt.Sprintf("(%s)", column.GetDefaultExpression())
+	}
+	c.Invisible = column.GetInvisible()
+	if _, specified := CheckSpecifiedType(dep, field); !specified && dataType.GetType() == ENUM {
+		if enum, ok := dep.GetEnum(strings.Split(field.GetTypeName(), ".")); ok {
+			c.Enum = enumValues(enum.GetEnum())
+		}
+	}
+	if IsAutoIncrement(field) {
+		c.AutoIncrement = true
+		c.Default = ""
+	}
+
+	checks := []*Check{}
+	// JSON is an alias of LONGTEXT on MariaDB, which does not validate documents
+	if opts.Dialect == DialectMariaDB && dataType.GetType() == JSON {
+		checks = append(checks, &Check{Column: c.Name, Expression: fmt.Sprintf("JSON_VALID(%s)", c.Name)})
+	}
+	if column.GetCheck() != "" {
+		checks = append(checks, &Check{Column: c.Name, Expression: column.GetCheck()})
+	}
+	return c, checks, err
 }
 
-func genCreateTable(dep dep.INameSpace, mt *descriptor.DescriptorProto) string {
+// fields must not take the names of the columns added by table options. MySQL compares column names case-insensitively
+func checkDuplicateColumns(mt *descriptor.DescriptorProto, columns []*Column) error {
+	seen := map[string]bool{}
+	for _, c := range columns {
+		name := strings.ToLower(c.Name)
+		if seen[name] {
+			if name == SoftDeleteMarkerColumn {
+				return fmt.Errorf("column %s of message %s collides with the soft-delete marker of includeSoftDelete indexes. rename the field", c.Name, mt.GetName())
+			}
+			return fmt.Errorf("column %s of message %s is defined twice. rename the field, or the audit or soft-delete column", c.Name, mt.GetName())
+		}
+		seen[name] = true
+	}
+	return nil
+}
 
-	createDefinitions := make([]string, 0, len(mt.Field))
+// build the table of mt for MySQL and MariaDB.
+// errors of each column are logged and skipped, as the SQL has always been generated.
+// keys, indexes, foreign keys and partitioning must be valid, since the table cannot be created or misses them otherwise
+func buildTable(dep dep.INameSpace, f *descriptor.FileDescriptorProto, mt *descriptor.DescriptorProto, opts Options) (*Table, error) {
+	if err := checkMySQLVersion(dep, mt, opts); err != nil {
+		return nil, err
+	}
+	fullName := strings.TrimPrefix(f.GetPackage()+"."+mt.GetName(), ".")
+	fingerprint, err := MessageFingerprint(dep, mt)
+	if err != nil {
+		return nil, err
+	}
+	t := &Table{
+		Name:        GetTableName(f, mt, opts),
+		Message:     fullName,
+		Fingerprint: fingerprint,
+	}
+	tableOpts, _ := CheckTableOptions(mt)
+	for _, name := range tableOpts.GetPreviousNames() {
+		t.PreviousNames = append(t.PreviousNames, messageTableName(f.GetPackage(), name, opts))
+	}
 
 	for _, field := range mt.Field {
-		createDefinition, err := genCreateDefinition(dep, field)
+		c, checks, err := buildColumn(dep, mt, field, fullName, opts)
 		if err != nil {
 			glog.Error(err)
 			glog.Errorf("failed to process field %s in message %s", field.GetName(), mt.GetName())
 		}
-		createDefinitions = append(createDefinitions, "\t"+createDefinition)
+		t.Columns = append(t.Columns, c)
+		t.Checks = append(t.Checks, checks...)
+	}
+
+	if audit, ok := GetAuditColumns(mt); ok {
+		t.Columns = append(t.Columns, auditColumns(audit)...)
+	}
+
+	if sd, ok := GetSoftDelete(mt); ok {
+		t.SoftDelete = &sd
+		// the marker is hidden from SELECT * only when the target is known to support it
+		invisibleMarker := !opts.MySQLVersion.IsZero() && opts.supports(featureInvisibleColumn)
+		t.Columns = append(t.Columns, softDeleteColumns(sd, needsSoftDeleteMarker(tableOpts), invisibleMarker)...)
 	}
 
-	createDefinitions = append(createDefinitions,
-		"\tPROTO_BINARY BLOB NOT NULL",
-	)
+	t.Columns = append(t.Columns, &Column{Name: ProtoBinaryColumn, Type: MySQLDataTypeWithArgs{BLOB, nil}})
+	if err := checkDuplicateColumns(mt, t.Columns); err != nil {
+		return nil, err
+	}
 
-	return fmt.Sprintf("CREATE TABLE %s (\n%s\n);",
-		mt.GetName(),
-		strings.Join(createDefinitions, ",\n"),
-	)
+	for _, r := range mt.GetReservedRange() {
+		t.ReservedRanges = append(t.ReservedRanges, ReservedRange{Start: r.GetStart(), End: r.GetEnd()})
+	}
+	t.ReservedNames = mt.GetReservedName()
+	for _, c := range t.Columns {
+		for _, name := range t.ReservedNames {
+			if c.Name == name {
+				glog.Warningf("column %s of message %s reuses reserved name, so values of the deleted field may be read as the column", c.Name, mt.GetName())
+			}
+		}
+	}
+
+	columns := map[string]bool{}
+	for _, c := range t.Columns {
+		columns[c.Name] = true
+	}
+	if err := checkKeyColumns(mt, tableOpts, columns); err != nil {
+		return nil, err
+	}
+
+	t.PrimaryKey = tableOpts.GetPrimaryKey()
+	for _, index := range tableOpts.GetIndexes() {
+		idx, err := buildIndex(mt, index, t.SoftDelete != nil, opts.Dialect)
+		if err != nil {
+			return nil, fmt.Errorf("failed to process index in message %s: %v", mt.GetName(), err)
+		}
+		t.Indexes = append(t.Indexes, idx)
+	}
+	for _, fk := range tableOpts.GetForeignKeys() {
+		foreignKey, err := buildForeignKey(dep, f, fk, opts)
+		if err != nil {
+			return nil, fmt.Errorf("failed to process foreign key in message %s: %v", mt.GetName(), err)
+		}
+		t.ForeignKeys = append(t.ForeignKeys, foreignKey)
+	}
+
+	if !opts.MySQLVersion.IsZero() {
+		t.Charset = "utf8mb4"
+		t.Collation = defaultCollation(opts.MySQLVersion)
+	}
+
+	if tableOpts.GetPartition() != nil {
+		var err error
+		if t.Partition, err = genPartitionOptions(mt, tableOpts, t.Columns); err != nil {
+			return nil, fmt.Errorf("failed to process partition in message %s: %v", mt.GetName(), err)
+		}
+	}
+	return t, nil
 }
 
-func GenSQL(dep dep.INameSpace, f *descriptor.FileDescriptorProto) string {
-	createTables := make([]string, 0, len(f.MessageType))
+// build the model of tables generated for f with dialect mysql or mariadb.
+// return error when the file needs features missing in opts.MySQLVersion
+func BuildSchema(dep dep.INameSpace, f *descriptor.FileDescriptorProto, opts Options) (*Schema, error) {
+	switch opts.Dialect {
+	case DialectMySQL, DialectMariaDB, "":
+	default:
+		return nil, fmt.Errorf("schema model is not available for dialect %s", opts.Dialect)
+	}
+
+	s := &Schema{}
+	if opts.PackageMapping == PackageMappingDatabase && f.GetPackage() != "" {
+		s.Database = packageIdent(f)
+	}
 	for _, mt := range f.MessageType {
-		createTables = append(createTables, genCreateTable(dep, mt))
+		t, err := buildTable(dep, f, mt, opts)
+		if err != nil {
+			return nil, err
+		}
+		s.Tables = append(s.Tables, t)
 	}
-	return strings.Join(createTables, "\n\n")
+	// referenced tables are created first
+	s.Tables, _ = sortTables(s.Tables)
+	return s, nil
+}
+
+// return error when the file needs features missing in opts.MySQLVersion
+func GenSQL(dep dep.INameSpace, f *descriptor.FileDescriptorProto, opts Options) (string, error) {
+	switch opts.Dialect {
+	case DialectPostgreSQL:
+		return genPostgresSQL(dep, f, opts, map[string]bool{})
+	case DialectSQLite:
+		return genSQLiteSQL(dep, f, opts)
+	case DialectClickHouse:
+		return genClickHouseSQL(dep, f, opts)
+	}
+
+	s, err := BuildSchema(dep, f, opts)
+	if err != nil {
+		return "", err
+	}
+	if err := OrderTables([]*Schema{s}, opts); err != nil {
+		return "", err
+	}
+	return genSchemaSQL(s, opts), nil
+}
+
+// SQL of each file, which are run in the given order. with dialect mysql or mariadb, tables are ordered by
+// foreign key references across the files
+func GenSQLFiles(deps []dep.INameSpace, files []*descriptor.FileDescriptorProto, opts Options) ([]string, error) {
+	switch opts.Dialect {
+	case DialectMySQL, DialectMariaDB, "":
+	default:
+		// an enum type of PostgreSQL is created by the first file using it
+		createdTypes := map[string]bool{}
+		ret := make([]string, 0, len(files))
+		for i, f := range files {
+			var sql string
+			var err error
+			if opts.Dialect == DialectPostgreSQL {
+				sql, err = genPostgresSQL(deps[i], f, opts, createdTypes)
+			} else {
+				sql, err = GenSQL(deps[i], f, opts)
+			}
+			if err != nil {
+				return nil, err
+			}
+			ret = append(ret, sql)
+		}
+		return ret, nil
+	}
+
+	schemas := make([]*Schema, 0, len(files))
+	for i, f := range files {
+		s, err := BuildSchema(deps[i], f, opts)
+		if err != nil {
+			return nil, err
+		}
+		schemas = append(schemas, s)
+	}
+	if err := OrderTables(schemas, opts); err != nil {
+		return nil, err
+	}
+	ret := make([]string, 0, len(files))
+	for _, s := range schemas {
+		ret = append(ret, genSchemaSQL(s, opts))
+	}
+	return ret, nil
 }
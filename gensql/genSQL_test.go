@@ -0,0 +1,170 @@
+package gensql
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestGenSQL(t *testing.T) {
+	const id = `field { name: "id" number: 1 type: TYPE_INT64 label: LABEL_OPTIONAL }`
+	const email = `field { name: "email" number: 2 type: TYPE_STRING label: LABEL_OPTIONAL options { [mySQLType] { typeName: "VARCHAR" args: ["255"] } } }`
+	// deleted rows have NULL in is_live, so they never collide
+	const liveRows = "CREATE TABLE User (\n\tid BIGINT NOT NULL,\n\temail VARCHAR(255) NOT NULL,\n\tdeleted_at DATETIME(6) NULL DEFAULT NULL,\n" +
+		"\tis_live TINYINT AS (IF(deleted_at IS NULL, 1, NULL)) VIRTUAL,\n" +
+		"\tPROTO_BINARY BLOB NOT NULL,\n\tPRIMARY KEY (id),\n\tUNIQUE INDEX email_idx (email,is_live)\n);"
+	tests := []struct {
+		name    string
+		message string
+		opts    Options
+		sql     string
+		err     string
+	}{
+		{
+			name:    "audit columns",
+			message: id + ` options { [mySQLTable] { auditColumns {} } }`,
+			sql: "CREATE TABLE User (\n\tid BIGINT NOT NULL,\n" +
+				"\tcreated_at DATETIME(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6),\n" +
+				"\tupdated_at DATETIME(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6) ON UPDATE CURRENT_TIMESTAMP(6),\n" +
+				"\trow_version BIGINT UNSIGNED NOT NULL DEFAULT 0,\n\tPROTO_BINARY BLOB NOT NULL\n);",
+		},
+		{
+			name:    "renamed audit columns",
+			message: id + ` options { [mySQLTable] { auditColumns { createdAt: "inserted_at" updatedAt: "modified_at" rowVersion: "version" } } }`,
+			sql: "CREATE TABLE User (\n\tid BIGINT NOT NULL,\n" +
+				"\tinserted_at DATETIME(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6),\n" +
+				"\tmodified_at DATETIME(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6) ON UPDATE CURRENT_TIMESTAMP(6),\n" +
+				"\tversion BIGINT UNSIGNED NOT NULL DEFAULT 0,\n\tPROTO_BINARY BLOB NOT NULL\n);",
+		},
+		{
+			name:    "soft delete",
+			message: id + email + ` options { [mySQLTable] { primaryKey: ["id"] softDelete {} indexes { name: "email_idx" columns: ["email"] unique: true } } }`,
+			sql: "CREATE TABLE User (\n\tid BIGINT NOT NULL,\n\temail VARCHAR(255) NOT NULL,\n\tdeleted_at DATETIME(6) NULL DEFAULT NULL,\n" +
+				"\tPROTO_BINARY BLOB NOT NULL,\n\tPRIMARY KEY (id),\n\tUNIQUE INDEX email_idx (email)\n);",
+		},
+		{
+			name:    "unique among live rows",
+			message: id + email + ` options { [mySQLTable] { primaryKey: ["id"] softDelete {} indexes { name: "email_idx" columns: ["email"] unique: true includeSoftDelete: true } } }`,
+			sql:     liveRows,
+		},
+		{
+			name:    "invisible marker on 8.0.23",
+			message: id + email + ` options { [mySQLTable] { primaryKey: ["id"] softDelete {} indexes { name: "email_idx" columns: ["email"] unique: true includeSoftDelete: true } } }`,
+			opts:    Options{MySQLVersion: MySQLVersion{8, 0, 23}},
+			sql: "CREATE TABLE User (\n\tid BIGINT NOT NULL,\n\temail VARCHAR(255) NOT NULL,\n\tdeleted_at DATETIME(6) NULL DEFAULT NULL,\n" +
+				"\tis_live TINYINT AS (IF(deleted_at IS NULL, 1, NULL)) VIRTUAL INVISIBLE,\n" +
+				"\tPROTO_BINARY BLOB NOT NULL,\n\tPRIMARY KEY (id),\n\tUNIQUE INDEX email_idx (email,is_live)\n" +
+				") DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_0900_ai_ci;",
+		},
+		{
+			name:    "unique among live rows on MariaDB",
+			message: id + email + ` options { [mySQLTable] { primaryKey: ["id"] softDelete {} indexes { name: "email_idx" columns: ["email"] unique: true includeSoftDelete: true } } }`,
+			opts:    Options{Dialect: DialectMariaDB},
+			sql:     liveRows,
+		},
+		{
+			name:    "UUID on MariaDB",
+			message: `field { name: "id" number: 1 type: TYPE_STRING label: LABEL_OPTIONAL options { [mySQLColumn] { semantic: UUID } } }`,
+			opts:    Options{Dialect: DialectMariaDB},
+			sql:     "CREATE TABLE User (\n\tid UUID NOT NULL,\n\tPROTO_BINARY BLOB NOT NULL\n);",
+		},
+		{
+			name: "ENUM values in number order",
+			message: `
+				field { name: "status" number: 1 type: TYPE_ENUM type_name: ".Foo.User.Status" label: LABEL_OPTIONAL }
+				enum_type { name: "Status" value { name: "DONE" number: 2 } value { name: "NEW" number: 0 } value { name: "ACTIVE" number: 1 } }`,
+			sql: "CREATE TABLE User (\n\tstatus ENUM('NEW','ACTIVE','DONE') NOT NULL,\n\tPROTO_BINARY BLOB NOT NULL\n);",
+		},
+		{
+			name:    "JSON on MariaDB",
+			message: `field { name: "tags" number: 1 type: TYPE_STRING label: LABEL_REPEATED }`,
+			opts:    Options{Dialect: DialectMariaDB},
+			sql:     "CREATE TABLE User (\n\ttags JSON NOT NULL CHECK (JSON_VALID(tags)),\n\tPROTO_BINARY BLOB NOT NULL\n);",
+		},
+		{
+			name:    "field named as the soft-delete marker",
+			message: id + email + `field { name: "Is_Live" number: 3 type: TYPE_BOOL label: LABEL_OPTIONAL } options { [mySQLTable] { primaryKey: ["id"] softDelete {} indexes { name: "email_idx" columns: ["email"] unique: true includeSoftDelete: true } } }`,
+			err:     "collides with the soft-delete marker",
+		},
+		{
+			name:    "field named as the soft-delete column",
+			message: id + `field { name: "deleted_at" number: 2 type: TYPE_INT64 label: LABEL_OPTIONAL } options { [mySQLTable] { softDelete {} } }`,
+			err:     "column deleted_at of message User is defined twice",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dep, f := parseMessages(t, `message_type { name: "User" `+tt.message+` }`)
+			sql, err := GenSQL(dep, f, tt.opts)
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Errorf("err = %v, want %s", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if sql != tt.sql {
+				t.Errorf("sql = \n%s\nwant\n%s", sql, tt.sql)
+			}
+		})
+	}
+}
+
+func TestGenSQLRerunnable(t *testing.T) {
+	const messages = `
+		message_type {
+			name: "Purchase"
+			field { name: "id" number: 1 type: TYPE_INT64 label: LABEL_OPTIONAL }
+			field { name: "customer_id" number: 2 type: TYPE_INT64 label: LABEL_OPTIONAL }
+			options { [mySQLTable] { primaryKey: ["id"] foreignKeys { name: "purchase_customer_fk" columns: ["customer_id"] references: "Customer" } softDelete { liveView: true } } }
+		}
+		message_type {
+			name: "Customer"
+			field { name: "id" number: 1 type: TYPE_INT64 label: LABEL_OPTIONAL }
+			options { [mySQLTable] { primaryKey: ["id"] } }
+		}`
+	customer := "CREATE TABLE Customer (\n\tid BIGINT NOT NULL,\n\tPROTO_BINARY BLOB NOT NULL,\n\tPRIMARY KEY (id)\n);"
+	purchase := "CREATE TABLE Purchase (\n\tid BIGINT NOT NULL,\n\tcustomer_id BIGINT NOT NULL,\n\tdeleted_at DATETIME(6) NULL DEFAULT NULL,\n\tPROTO_BINARY BLOB NOT NULL,\n\tPRIMARY KEY (id),\n\tCONSTRAINT purchase_customer_fk FOREIGN KEY (customer_id) REFERENCES Customer (id)\n);"
+	view := "CREATE VIEW Purchase_live AS SELECT * FROM Purchase WHERE deleted_at IS NULL;"
+	tests := []struct {
+		name string
+		opts Options
+		sql  string
+	}{
+		{
+			name: "referenced table first",
+			sql:  customer + "\n\n" + purchase + "\n\n" + view,
+		},
+		{
+			name: "if not exists",
+			opts: Options{IfNotExists: true},
+			sql: strings.Replace(customer, "CREATE TABLE", "CREATE TABLE IF NOT EXISTS", 1) + "\n\n" +
+				strings.Replace(purchase, "CREATE TABLE", "CREATE TABLE IF NOT EXISTS", 1) + "\n\n" +
+				strings.Replace(view, "CREATE VIEW", "CREATE OR REPLACE VIEW", 1),
+		},
+		{
+			name: "drop tables in reverse purchase",
+			opts: Options{DropTables: true},
+			sql:  "DROP VIEW IF EXISTS Purchase_live;\nDROP TABLE IF EXISTS Purchase;\nDROP TABLE IF EXISTS Customer;\n\n" + customer + "\n\n" + purchase + "\n\n" + view,
+		},
+		{
+			name: "foreign key checks guard",
+			opts: Options{ForeignKeyChecksGuard: true},
+			sql: "SET @OLD_FOREIGN_KEY_CHECKS=@@FOREIGN_KEY_CHECKS, FOREIGN_KEY_CHECKS=0;\n\n" +
+				customer + "\n\n" + purchase + "\n\n" + view + "\n\nSET FOREIGN_KEY_CHECKS=@OLD_FOREIGN_KEY_CHECKS;",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dep, f := parseMessages(t, messages)
+			sql, err := GenSQL(dep, f, tt.opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if sql != tt.sql {
+				t.Errorf("sql = \n%s\nwant\n%s", sql, tt.sql)
+			}
+		})
+	}
+}
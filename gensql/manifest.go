@@ -0,0 +1,175 @@
+package gensql
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/Mojashi/proto-mysql/dep"
+	"github.com/golang/protobuf/protoc-gen-go/descriptor"
+)
+
+// how a column stores its value
+type Storage string
+
+const (
+	StorageScalar Storage = "scalar"
+	StorageJSON   Storage = "json"
+	// serialized message
+	StorageProtoBinary Storage = "protoBinary"
+	// audit, soft-delete and generated columns without proto field
+	StorageBookkeeping Storage = "bookkeeping"
+)
+
+// conversion applied by helpers from proto value to column value
+type Conversion string
+
+const (
+	ConversionNone Conversion = "none"
+	// enum number to its name
+	ConversionEnumName Conversion = "enumName"
+	// json_format.MessageToJson
+	ConversionJSONMessage Conversion = "jsonMessage"
+	// JSON array of scalars
+	ConversionJSONArray Conversion = "jsonArray"
+	// JSON array of json_format.MessageToJson
+	ConversionJSONMessageArray Conversion = "jsonMessageArray"
+	// JSON object of a map field, as json_format writes it in the message
+	ConversionJSONMap Conversion = "jsonMap"
+	// SerializeToString of the whole message
+	ConversionSerialize Conversion = "serialize"
+	// filled by the database and left out of INSERT
+	ConversionDatabase Conversion = "database"
+)
+
+// conversion of field by helpers, which is decided from the MySQL type
+func GetConversion(dep dep.INameSpace, field *descriptor.FieldDescriptorProto) Conversion {
+	if IsAutoIncrement(field) {
+		return ConversionDatabase
+	}
+	switch t, _ := GenMySQLDataType(dep, field); t.GetType() {
+	case JSON:
+		if field.GetLabel() != descriptor.FieldDescriptorProto_LABEL_REPEATED {
+			return ConversionJSONMessage
+		}
+		if IsMapField(dep, field) {
+			return ConversionJSONMap
+		}
+		if field.GetType() == descriptor.FieldDescriptorProto_TYPE_MESSAGE {
+			return ConversionJSONMessageArray
+		}
+		return ConversionJSONArray
+	case ENUM:
+		return ConversionEnumName
+	default:
+		return ConversionNone
+	}
+}
+
+// map<K, V> is a repeated field of its map entry message
+func IsMapField(dep dep.INameSpace, field *descriptor.FieldDescriptorProto) bool {
+	if field.GetLabel() != descriptor.FieldDescriptorProto_LABEL_REPEATED || field.GetType() != descriptor.FieldDescriptorProto_TYPE_MESSAGE {
+		return false
+	}
+	msg, ok := dep.GetMessage(strings.Split(field.GetTypeName(), "."))
+	return ok && msg.GetDescriptor().GetOptions().GetMapEntry()
+}
+
+type Manifest struct {
+	File    string          `json:"file"`
+	Dialect Dialect         `json:"dialect"`
+	Tables  []ManifestTable `json:"tables"`
+}
+
+type ManifestTable struct {
+	Database string `json:"database,omitempty"`
+	Name     string `json:"name"`
+	Message  string `json:"message"`
+	// MessageFingerprint of the message
+	Fingerprint string           `json:"fingerprint"`
+	Columns     []ManifestColumn `json:"columns"`
+}
+
+type ManifestColumn struct {
+	Name string `json:"name"`
+	// zero for columns without proto field
+	FieldNumber   int32      `json:"fieldNumber,omitempty"`
+	FieldFullName string     `json:"fieldFullName,omitempty"`
+	Type          string     `json:"type"`
+	Nullable      bool       `json:"nullable"`
+	Storage       Storage    `json:"storage"`
+	Conversion    Conversion `json:"conversion"`
+}
+
+func manifestColumn(dep dep.INameSpace, mt *descriptor.DescriptorProto, c *Column) ManifestColumn {
+	ret := ManifestColumn{
+		Name:       c.Name,
+		Type:       c.Type.ToString(),
+		Nullable:   c.Nullable || c.Generated != "",
+		Storage:    StorageBookkeeping,
+		Conversion: ConversionDatabase,
+	}
+	if c.Name == ProtoBinaryColumn && c.Field == nil {
+		ret.Storage = StorageProtoBinary
+		ret.Conversion = ConversionSerialize
+		return ret
+	}
+	if c.Field == nil {
+		return ret
+	}
+
+	ret.FieldNumber = c.Field.Number
+	ret.FieldFullName = c.Field.FullName
+	ret.Storage = StorageScalar
+	if c.Type.GetType() == JSON {
+		ret.Storage = StorageJSON
+	}
+	for _, field := range mt.Field {
+		if field.GetNumber() == c.Field.Number {
+			ret.Conversion = GetConversion(dep, field)
+		}
+	}
+	return ret
+}
+
+// manifest of tables and columns generated for f, so that columns can be mapped back to proto fields
+func GenManifest(dep dep.INameSpace, f *descriptor.FileDescriptorProto, opts Options) (*Manifest, error) {
+	s, err := BuildSchema(dep, f, opts)
+	if err != nil {
+		return nil, err
+	}
+	dialect := opts.Dialect
+	if dialect == "" {
+		dialect = DialectMySQL
+	}
+	messages := map[string]*descriptor.DescriptorProto{}
+	for _, mt := range f.MessageType {
+		messages[strings.TrimPrefix(f.GetPackage()+"."+mt.GetName(), ".")] = mt
+	}
+	ret := &Manifest{File: f.GetName(), Dialect: dialect, Tables: []ManifestTable{}}
+	for _, t := range s.Tables {
+		table := ManifestTable{
+			Database:    t.Name.Database,
+			Name:        t.Name.Name,
+			Message:     t.Message,
+			Fingerprint: t.Fingerprint,
+			Columns:     make([]ManifestColumn, 0, len(t.Columns)),
+		}
+		for _, c := range t.Columns {
+			table.Columns = append(table.Columns, manifestColumn(dep, messages[t.Message], c))
+		}
+		ret.Tables = append(ret.Tables, table)
+	}
+	return ret, nil
+}
+
+func GenManifestJSON(dep dep.INameSpace, f *descriptor.FileDescriptorProto, opts Options) (string, error) {
+	m, err := GenManifest(dep, f, opts)
+	if err != nil {
+		return "", err
+	}
+	buf, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return string(buf) + "\n", nil
+}
@@ -0,0 +1,110 @@
+package gensql
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestGetConversion(t *testing.T) {
+	dep, f := parseMessages(t, `
+		message_type {
+			name: "User"
+			field { name: "id" number: 1 type: TYPE_INT64 label: LABEL_OPTIONAL options { [mySQLColumn] { autoIncrement: true } } }
+			field { name: "name" number: 2 type: TYPE_STRING label: LABEL_OPTIONAL }
+			field { name: "gender" number: 3 type: TYPE_ENUM type_name: ".Foo.Gender" label: LABEL_OPTIONAL }
+			field { name: "tags" number: 4 type: TYPE_STRING label: LABEL_REPEATED }
+			field { name: "friend" number: 5 type: TYPE_MESSAGE type_name: ".Foo.Friend" label: LABEL_OPTIONAL }
+			field { name: "friends" number: 6 type: TYPE_MESSAGE type_name: ".Foo.Friend" label: LABEL_REPEATED }
+			field { name: "labels" number: 7 type: TYPE_MESSAGE type_name: ".Foo.User.LabelsEntry" label: LABEL_REPEATED }
+			nested_type {
+				name: "LabelsEntry"
+				field { name: "key" number: 1 type: TYPE_STRING label: LABEL_OPTIONAL }
+				field { name: "value" number: 2 type: TYPE_INT32 label: LABEL_OPTIONAL }
+				options { map_entry: true }
+			}
+		}
+		message_type { name: "Friend" field { name: "name" number: 1 type: TYPE_STRING label: LABEL_OPTIONAL } }
+		`+genderEnum)
+	want := map[string]Conversion{
+		"id":      ConversionDatabase,
+		"name":    ConversionNone,
+		"gender":  ConversionEnumName,
+		"tags":    ConversionJSONArray,
+		"friend":  ConversionJSONMessage,
+		"friends": ConversionJSONMessageArray,
+		"labels":  ConversionJSONMap,
+	}
+	for _, field := range f.MessageType[0].Field {
+		t.Run(field.GetName(), func(t *testing.T) {
+			if c := GetConversion(dep, field); c != want[field.GetName()] {
+				t.Errorf("conversion = %s, want %s", c, want[field.GetName()])
+			}
+		})
+	}
+}
+
+func TestGenManifest(t *testing.T) {
+	dep, f := parseMessages(t, `
+		message_type {
+			name: "User"
+			field { name: "id" number: 1 type: TYPE_INT64 label: LABEL_OPTIONAL }
+			field { name: "page_number" number: 2 type: TYPE_INT32 label: LABEL_OPTIONAL proto3_optional: true oneof_index: 0 }
+			field { name: "tags" number: 3 type: TYPE_STRING label: LABEL_REPEATED }
+			oneof_decl { name: "_page_number" }
+			options { [mySQLTable] { primaryKey: ["id"] softDelete {} } }
+		}`)
+	columns := []ManifestColumn{
+		{Name: "id", FieldNumber: 1, FieldFullName: "Foo.User.id", Type: "BIGINT", Storage: StorageScalar, Conversion: ConversionNone},
+		{Name: "page_number", FieldNumber: 2, FieldFullName: "Foo.User.page_number", Type: "INT", Nullable: true, Storage: StorageScalar, Conversion: ConversionNone},
+		{Name: "tags", FieldNumber: 3, FieldFullName: "Foo.User.tags", Type: "JSON", Storage: StorageJSON, Conversion: ConversionJSONArray},
+		{Name: "deleted_at", Type: "DATETIME(6)", Nullable: true, Storage: StorageBookkeeping, Conversion: ConversionDatabase},
+		{Name: "PROTO_BINARY", Type: "BLOB", Storage: StorageProtoBinary, Conversion: ConversionSerialize},
+	}
+	tests := []struct {
+		name    string
+		opts    Options
+		dialect Dialect
+		table   ManifestTable
+		err     bool
+	}{
+		{
+			name:    "mysql",
+			dialect: DialectMySQL,
+			table:   ManifestTable{Name: "User", Message: "Foo.User", Columns: columns},
+		},
+		{
+			name:    "database of package",
+			opts:    Options{Dialect: DialectMariaDB, PackageMapping: PackageMappingDatabase},
+			dialect: DialectMariaDB,
+			table:   ManifestTable{Database: "foo", Name: "User", Message: "Foo.User", Columns: columns},
+		},
+		{
+			name: "dialect without schema model",
+			opts: Options{Dialect: DialectPostgreSQL},
+			err:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, err := GenManifest(dep, f, tt.opts)
+			if (err != nil) != tt.err {
+				t.Fatalf("err = %v, want error %v", err, tt.err)
+			}
+			if err != nil {
+				return
+			}
+			if m.File != "user.proto" || m.Dialect != tt.dialect || len(m.Tables) != 1 {
+				t.Fatalf("manifest = %+v", m)
+			}
+			// fingerprints are tested in fingerprint_test.go
+			table := m.Tables[0]
+			if table.Fingerprint == "" {
+				t.Errorf("fingerprint is empty")
+			}
+			table.Fingerprint = ""
+			if !reflect.DeepEqual(table, tt.table) {
+				t.Errorf("table = %+v, want %+v", table, tt.table)
+			}
+		})
+	}
+}
@@ -0,0 +1,568 @@
+package gensql
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Mojashi/proto-mysql/dep"
+	"github.com/golang/glog"
+	"github.com/golang/protobuf/protoc-gen-go/descriptor"
+)
+
+type ChangeKind string
+
+const (
+	ChangeCreateDatabase ChangeKind = "CREATE DATABASE"
+	ChangeCreateTable    ChangeKind = "CREATE TABLE"
+	ChangeDropTable      ChangeKind = "DROP TABLE"
+	ChangeRenameTable    ChangeKind = "RENAME TABLE"
+	ChangeCreateView     ChangeKind = "CREATE VIEW"
+	ChangeDropView       ChangeKind = "DROP VIEW"
+	ChangeAddColumn      ChangeKind = "ADD COLUMN"
+	ChangeDropColumn     ChangeKind = "DROP COLUMN"
+	ChangeModifyColumn   ChangeKind = "MODIFY COLUMN"
+	ChangeRenameColumn   ChangeKind = "RENAME COLUMN"
+	// rename and modify, or rename before MySQL 8.0
+	ChangeChangeColumn   ChangeKind = "CHANGE COLUMN"
+	ChangeAddPrimaryKey  ChangeKind = "ADD PRIMARY KEY"
+	ChangeDropPrimaryKey ChangeKind = "DROP PRIMARY KEY"
+	ChangeAddIndex       ChangeKind = "ADD INDEX"
+	ChangeDropIndex      ChangeKind = "DROP INDEX"
+	ChangeAddForeignKey  ChangeKind = "ADD FOREIGN KEY"
+	ChangeDropForeignKey ChangeKind = "DROP FOREIGN KEY"
+	ChangeAddCheck       ChangeKind = "ADD CHECK"
+	ChangeDropCheck      ChangeKind = "DROP CHECK"
+	ChangeTableOptions   ChangeKind = "TABLE OPTIONS"
+	// UPDATE of rows between ALTER TABLE statements. e.g. renaming ENUM values
+	ChangeUpdateRows ChangeKind = "UPDATE"
+	// PARTITION BY or REMOVE PARTITIONING, which is issued in its own ALTER TABLE
+	ChangePartition ChangeKind = "PARTITION"
+	// statement on the fingerprint table
+	ChangeFingerprint ChangeKind = "FINGERPRINT"
+)
+
+// a step of migration from one schema to another
+type Change struct {
+	Kind  ChangeKind
+	Table TableName
+	// clause of ALTER TABLE, or the whole statement for databases, tables and views
+	SQL string
+	// columns before and after the change. nil unless the change is about a column
+	OldColumn *Column
+	NewColumn *Column
+	// caveats rendered as comments above the statement. e.g. the change copies the table
+	Warnings []string
+	// ALGORITHM and LOCK of the clause. nil unless Options.AlterAlgorithm
+	OnlineDDL *OnlineDDL
+}
+
+func (c *Change) isStatement() bool {
+	switch c.Kind {
+	case ChangeCreateDatabase, ChangeCreateTable, ChangeDropTable, ChangeRenameTable, ChangeCreateView, ChangeDropView, ChangeUpdateRows, ChangeFingerprint:
+		return true
+	}
+	return false
+}
+
+func tableKey(t TableName) string {
+	return strings.ToLower(t.String())
+}
+
+// changes which migrate tables of old to those of new.
+// tables are matched by name or previousNames, and columns by field number or name.
+func DiffSchema(old, new *Schema, opts Options) []*Change {
+	changes := []*Change{}
+	if new.Database != "" && new.Database != old.Database {
+		changes = append(changes, &Change{
+			Kind: ChangeCreateDatabase,
+			SQL:  fmt.Sprintf("CREATE DATABASE IF NOT EXISTS `%s`;", new.Database),
+		})
+	}
+
+	oldTables := map[string]*Table{}
+	for _, t := range old.Tables {
+		oldTables[tableKey(t.Name)] = t
+	}
+	matched := map[string]bool{}
+	for _, t := range new.Tables {
+		// a table renamed to another one is not matched again
+		o, ok := oldTables[tableKey(t.Name)]
+		ok = ok && !matched[tableKey(t.Name)]
+		for _, name := range t.PreviousNames {
+			if ok {
+				break
+			}
+			if matched[tableKey(name)] {
+				continue
+			}
+			if o, ok = oldTables[tableKey(name)]; ok {
+				changes = append(changes, &Change{
+					Kind:  ChangeRenameTable,
+					Table: t.Name,
+					SQL:   fmt.Sprintf("RENAME TABLE %s TO %s;", o.Name, t.Name),
+				})
+			}
+		}
+		if !ok {
+			changes = append(changes, &Change{Kind: ChangeCreateTable, Table: t.Name, SQL: genTableSQL(t, false)})
+			continue
+		}
+		matched[tableKey(o.Name)] = true
+		changes = append(changes, diffTable(o, t, opts)...)
+	}
+
+	for _, o := range old.Tables {
+		if matched[tableKey(o.Name)] {
+			continue
+		}
+		if o.SoftDelete != nil && o.SoftDelete.LiveView {
+			changes = append(changes, &Change{
+				Kind:  ChangeDropView,
+				Table: o.Name,
+				SQL:   fmt.Sprintf("DROP VIEW IF EXISTS %s;", LiveViewName(o.Name)),
+			})
+		}
+		changes = append(changes, &Change{Kind: ChangeDropTable, Table: o.Name, SQL: fmt.Sprintf("DROP TABLE %s;", o.Name)})
+	}
+	if opts.Fingerprint {
+		changes = append(changes, diffFingerprints(old, new)...)
+	}
+	if opts.AlterAlgorithm {
+		annotateOnlineDDL(changes, opts)
+	}
+	return changes
+}
+
+// return old column of each new column. proto field numbers are stable, so fields
+// of the same number are the same column even if renamed, as long as the type is kept.
+// arguments such as length and ENUM values may change. others are matched by name.
+func matchColumns(o, t *Table) map[*Column]*Column {
+	ret := map[*Column]*Column{}
+	used := map[*Column]bool{}
+	for _, c := range t.Columns {
+		for _, oc := range o.Columns {
+			if c.Field == nil || oc.Field == nil || c.Field.Number != oc.Field.Number {
+				continue
+			}
+			// a renamed field of another type is a new column, and the old one is dropped
+			if c.Name != oc.Name && !strings.EqualFold(string(c.Type.GetType()), string(oc.Type.GetType())) {
+				continue
+			}
+			ret[c] = oc
+			used[oc] = true
+		}
+	}
+	for _, c := range t.Columns {
+		if _, ok := ret[c]; ok {
+			continue
+		}
+		oc, ok := o.GetColumn(c.Name)
+		if !ok || used[oc] {
+			continue
+		}
+		// values of the old field must not be read as the new field
+		if c.Field != nil && oc.Field != nil {
+			glog.Warningf("column %s of %s is reused by field number %d, which was %d. the old column is dropped",
+				c.Name, t.Name, c.Field.Number, oc.Field.Number)
+			continue
+		}
+		ret[c] = oc
+		used[oc] = true
+	}
+	return ret
+}
+
+func renameColumns(columns []string, renames map[string]string) []string {
+	ret := make([]string, 0, len(columns))
+	for _, c := range columns {
+		if n, ok := renames[c]; ok {
+			c = n
+		}
+		ret = append(ret, c)
+	}
+	return ret
+}
+
+// MySQL names an unnamed index after its first column
+func indexName(index *Index) string {
+	if index.Name != "" {
+		return index.Name
+	}
+	return strings.Trim(index.Columns[0], "()")
+}
+
+func indexKey(index *Index) string {
+	if index.Name != "" {
+		return index.Name
+	}
+	return genIndexDefinition(index)
+}
+
+func foreignKeyKey(fk *ForeignKey) string {
+	if fk.Name != "" {
+		return fk.Name
+	}
+	return genForeignKeyDefinition(fk)
+}
+
+func checkKey(c *Check) string {
+	return c.Column + "\x00" + genCheckDefinition(c)
+}
+
+func diffTable(o, t *Table, opts Options) []*Change {
+	changes := []*Change{}
+	add := func(kind ChangeKind, sql string, oldColumn, newColumn *Column) {
+		change := &Change{Kind: kind, Table: t.Name, SQL: sql, OldColumn: oldColumn, NewColumn: newColumn}
+		if oldColumn != nil && newColumn != nil {
+			change.Warnings = enumChangeWarnings(t.Name, oldColumn, newColumn)
+		}
+		changes = append(changes, change)
+	}
+
+	oldLiveView := o.SoftDelete != nil && o.SoftDelete.LiveView
+	newLiveView := t.SoftDelete != nil && t.SoftDelete.LiveView
+	if oldLiveView && (!newLiveView || o.Name != t.Name) {
+		changes = append(changes, &Change{
+			Kind:  ChangeDropView,
+			Table: t.Name,
+			SQL:   fmt.Sprintf("DROP VIEW IF EXISTS %s;", LiveViewName(o.Name)),
+		})
+	}
+
+	// keys of o are compared after renaming their columns
+	oldColumns := matchColumns(o, t)
+	renames := map[string]string{}
+	for c, oc := range oldColumns {
+		if oc.Name != c.Name {
+			renames[oc.Name] = c.Name
+		}
+	}
+	renamedForeignKey := func(fk *ForeignKey) *ForeignKey {
+		ret := *fk
+		ret.Columns = renameColumns(fk.Columns, renames)
+		return &ret
+	}
+	renamedIndex := func(index *Index) *Index {
+		ret := *index
+		ret.Columns = renameColumns(index.Columns, renames)
+		return &ret
+	}
+
+	newForeignKeys := map[string]*ForeignKey{}
+	for _, fk := range t.ForeignKeys {
+		newForeignKeys[foreignKeyKey(fk)] = fk
+	}
+	oldForeignKeys := map[string]*ForeignKey{}
+	for _, fk := range o.ForeignKeys {
+		oldForeignKeys[foreignKeyKey(renamedForeignKey(fk))] = renamedForeignKey(fk)
+		if n, ok := newForeignKeys[foreignKeyKey(renamedForeignKey(fk))]; ok && genForeignKeyDefinition(n) == genForeignKeyDefinition(renamedForeignKey(fk)) {
+			continue
+		}
+		if fk.Name == "" {
+			glog.Errorf("unnamed foreign key of %s must be dropped by its generated name: %s", o.Name, genForeignKeyDefinition(fk))
+			continue
+		}
+		add(ChangeDropForeignKey, "DROP FOREIGN KEY "+fk.Name, nil, nil)
+	}
+
+	newIndexes := map[string]*Index{}
+	for _, index := range t.Indexes {
+		newIndexes[indexKey(index)] = index
+	}
+	oldIndexes := map[string]*Index{}
+	for _, index := range o.Indexes {
+		oldIndexes[indexKey(renamedIndex(index))] = renamedIndex(index)
+		if n, ok := newIndexes[indexKey(renamedIndex(index))]; ok && genIndexDefinition(n) == genIndexDefinition(renamedIndex(index)) {
+			continue
+		}
+		add(ChangeDropIndex, "DROP INDEX "+indexName(index), nil, nil)
+	}
+
+	primaryKeyChanged := strings.Join(renameColumns(o.PrimaryKey, renames), ",") != strings.Join(t.PrimaryKey, ",")
+	if primaryKeyChanged && len(o.PrimaryKey) > 0 {
+		add(ChangeDropPrimaryKey, "DROP PRIMARY KEY", nil, nil)
+	}
+
+	columnsChanged := false
+	matchedColumns := map[*Column]bool{}
+	for _, oc := range oldColumns {
+		matchedColumns[oc] = true
+	}
+	for _, c := range o.Columns {
+		if matchedColumns[c] {
+			continue
+		}
+		if c.Field != nil && t.IsReserved(c.Field) && opts.ReservedPolicy == ReservedKeep {
+			if _, ok := t.GetColumn(c.Name); ok {
+				glog.Errorf("column %s of reserved field cannot be kept in %s, as the name is reused", c.Name, t.Name)
+			} else {
+				if !c.Nullable && c.Generated == "" {
+					kept := *c
+					kept.Nullable = true
+					columnsChanged = true
+					add(ChangeModifyColumn, "MODIFY COLUMN "+genColumnDefinition(&kept, nil), c, &kept)
+				}
+				continue
+			}
+		}
+		columnsChanged = true
+		add(ChangeDropColumn, "DROP COLUMN "+c.Name, c, nil)
+	}
+	for i, c := range t.Columns {
+		oc, ok := oldColumns[c]
+		if !ok {
+			columnsChanged = true
+			position := "FIRST"
+			if i > 0 {
+				position = "AFTER " + t.Columns[i-1].Name
+			}
+			add(ChangeAddColumn, fmt.Sprintf("ADD COLUMN %s %s", genColumnDefinition(c, t.columnChecks(c.Name)), position), nil, c)
+			continue
+		}
+		renamed := *oc
+		renamed.Name = c.Name
+		modified := genColumnDefinition(&renamed, nil) != genColumnDefinition(c, nil)
+		if step, renames := enumRenameStep(oc, c); step != nil {
+			// values are renamed by appending the new names, updating rows and dropping the old names
+			columnsChanged = true
+			if oc.Name != c.Name {
+				add(ChangeChangeColumn, fmt.Sprintf("CHANGE COLUMN %s %s", oc.Name, genColumnDefinition(step, nil)), oc, step)
+			} else {
+				add(ChangeModifyColumn, "MODIFY COLUMN "+genColumnDefinition(step, nil), oc, step)
+			}
+			for _, r := range renames {
+				add(ChangeUpdateRows, fmt.Sprintf("UPDATE %s SET %s = '%s' WHERE %s = '%s';", t.Name, c.Name, r.New, c.Name, r.Old), nil, nil)
+			}
+			add(ChangeModifyColumn, "MODIFY COLUMN "+genColumnDefinition(c, nil), step, c)
+			continue
+		}
+		switch {
+		case oc.Name != c.Name && !modified && opts.supports(featureRenameColumn):
+			columnsChanged = true
+			add(ChangeRenameColumn, fmt.Sprintf("RENAME COLUMN %s TO %s", oc.Name, c.Name), oc, c)
+		case oc.Name != c.Name:
+			columnsChanged = true
+			add(ChangeChangeColumn, fmt.Sprintf("CHANGE COLUMN %s %s", oc.Name, genColumnDefinition(c, nil)), oc, c)
+		case modified:
+			columnsChanged = true
+			add(ChangeModifyColumn, "MODIFY COLUMN "+genColumnDefinition(c, nil), oc, c)
+		}
+	}
+
+	if primaryKeyChanged && len(t.PrimaryKey) > 0 {
+		add(ChangeAddPrimaryKey, fmt.Sprintf("ADD PRIMARY KEY (%s)", strings.Join(t.PrimaryKey, ",")), nil, nil)
+	}
+	for _, index := range t.Indexes {
+		if n, ok := oldIndexes[indexKey(index)]; ok && genIndexDefinition(n) == genIndexDefinition(index) {
+			continue
+		}
+		add(ChangeAddIndex, "ADD "+genIndexDefinition(index), nil, nil)
+	}
+	for _, fk := range t.ForeignKeys {
+		if n, ok := oldForeignKeys[foreignKeyKey(fk)]; ok && genForeignKeyDefinition(n) == genForeignKeyDefinition(fk) {
+			continue
+		}
+		add(ChangeAddForeignKey, "ADD "+genForeignKeyDefinition(fk), nil, nil)
+	}
+
+	// checks of added and dropped columns come and go with the columns
+	newChecks := map[string]bool{}
+	for _, c := range t.Checks {
+		newChecks[checkKey(c)] = true
+	}
+	oldChecks := map[string]bool{}
+	for _, oc := range o.Checks {
+		c := *oc
+		if n, ok := renames[c.Column]; ok {
+			c.Column = n
+		}
+		oldChecks[checkKey(&c)] = true
+		if _, ok := t.GetColumn(c.Column); newChecks[checkKey(&c)] || (c.Column != "" && !ok) {
+			continue
+		}
+		if c.Name == "" {
+			glog.Errorf("unnamed CHECK of %s must be dropped by its generated name: %s", o.Name, c.Expression)
+			continue
+		}
+		add(ChangeDropCheck, "DROP CHECK "+c.Name, nil, nil)
+	}
+	for _, c := range t.Checks {
+		if column, ok := t.GetColumn(c.Column); ok {
+			// checks of added columns are in ADD COLUMN
+			if _, ok := oldColumns[column]; !ok {
+				continue
+			}
+		}
+		if oldChecks[checkKey(c)] {
+			continue
+		}
+		add(ChangeAddCheck, "ADD "+genCheckDefinition(c), nil, nil)
+	}
+
+	if (o.Charset != t.Charset || o.Collation != t.Collation) && t.Charset != "" {
+		add(ChangeTableOptions, fmt.Sprintf("DEFAULT CHARSET=%s COLLATE=%s", t.Charset, t.Collation), nil, nil)
+	}
+
+	if o.Partition != t.Partition {
+		if t.Partition == "" {
+			add(ChangePartition, "REMOVE PARTITIONING", nil, nil)
+		} else {
+			add(ChangePartition, t.Partition, nil, nil)
+		}
+	}
+
+	// columns of a view are fixed when it is created, so the view is replaced
+	if newLiveView && (!oldLiveView || columnsChanged || o.Name != t.Name || o.SoftDelete.Column != t.SoftDelete.Column) {
+		changes = append(changes, &Change{
+			Kind:  ChangeCreateView,
+			Table: t.Name,
+			SQL:   strings.Replace(genLiveView(t.Name, *t.SoftDelete), "CREATE VIEW", "CREATE OR REPLACE VIEW", 1),
+		})
+	}
+	return changes
+}
+
+// changes issued as one statement
+type changeGroup []*Change
+
+// consecutive clauses for a table are merged into an ALTER TABLE
+func groupChanges(changes []*Change) []changeGroup {
+	groups := []changeGroup{}
+	for _, c := range changes {
+		if n := len(groups); n > 0 && groups[n-1].isAlter() && !c.isStatement() && c.Kind != ChangePartition && groups[n-1][0].Table == c.Table {
+			groups[n-1] = append(groups[n-1], c)
+			continue
+		}
+		groups = append(groups, changeGroup{c})
+	}
+	return groups
+}
+
+// whether the group is clauses of ALTER TABLE
+func (g changeGroup) isAlter() bool {
+	return !g[0].isStatement() && g[0].Kind != ChangePartition
+}
+
+func (g changeGroup) clauses() []string {
+	ret := make([]string, 0, len(g))
+	for _, c := range g {
+		ret = append(ret, c.SQL)
+	}
+	return ret
+}
+
+// render changes as statements. consecutive clauses for a table are merged into an ALTER TABLE.
+func GenMigrationSQL(changes []*Change) string {
+	statements := []string{}
+	for _, g := range groupChanges(changes) {
+		var statement string
+		switch {
+		case g.isAlter():
+			clauses := g.clauses()
+			if d := g.onlineDDL(); d != nil {
+				clauses = append(clauses, d.clause())
+			}
+			statement = fmt.Sprintf("ALTER TABLE %s\n\t%s;", g[0].Table, strings.Join(clauses, ",\n\t"))
+		case g[0].Kind == ChangePartition:
+			statement = fmt.Sprintf("ALTER TABLE %s %s;", g[0].Table, g[0].SQL)
+		default:
+			statement = g[0].SQL
+		}
+		comments := ""
+		for _, c := range g {
+			for _, w := range c.Warnings {
+				comments += "-- " + w + "\n"
+			}
+		}
+		statements = append(statements, comments+statement)
+	}
+	return strings.Join(statements, "\n\n")
+}
+
+// baseline is nil when the file is new, and f is nil when the file is removed
+func buildMigrationSchemas(baselineDep dep.INameSpace, baseline *descriptor.FileDescriptorProto, dep dep.INameSpace, f *descriptor.FileDescriptorProto, opts Options) (*Schema, *Schema, error) {
+	old, new := &Schema{}, &Schema{}
+	var err error
+	if baseline != nil {
+		if old, err = BuildSchema(baselineDep, baseline, opts); err != nil {
+			return nil, nil, err
+		}
+	}
+	if f != nil {
+		if new, err = BuildSchema(dep, f, opts); err != nil {
+			return nil, nil, err
+		}
+	}
+	return old, new, nil
+}
+
+// migration SQL from baseline to f. baseline is nil when the file is new
+func GenMigration(baselineDep dep.INameSpace, baseline *descriptor.FileDescriptorProto, dep dep.INameSpace, f *descriptor.FileDescriptorProto, opts Options) (string, error) {
+	old, new, err := buildMigrationSchemas(baselineDep, baseline, dep, f, opts)
+	if err != nil {
+		return "", err
+	}
+	return GenMigrationSQL(DiffSchema(old, new, opts)), nil
+}
+
+// up and down migration between two schemas
+type Migration struct {
+	Up   string
+	Down string
+	// changes of up
+	Changes []*Change
+	// data which down cannot restore. e.g. values of dropped columns
+	Irreversible []string
+}
+
+// old as the target of down migration. renamed tables remember their current names
+func reverseRenames(old, new *Schema) *Schema {
+	ret := *old
+	ret.Tables = make([]*Table, 0, len(old.Tables))
+	for _, o := range old.Tables {
+		reversed := *o
+		reversed.PreviousNames = nil
+		for _, t := range new.Tables {
+			for _, name := range t.PreviousNames {
+				if tableKey(name) == tableKey(o.Name) {
+					reversed.PreviousNames = append(reversed.PreviousNames, t.Name)
+				}
+			}
+		}
+		ret.Tables = append(ret.Tables, &reversed)
+	}
+	return &ret
+}
+
+// down is the diff in the opposite direction. the database is left as is
+func DiffMigration(old, new *Schema, opts Options) *Migration {
+	reversed := reverseRenames(old, new)
+	changes := DiffSchema(old, new, opts)
+	m := &Migration{
+		Up:      GenMigrationSQL(changes),
+		Down:    GenMigrationSQL(DiffSchema(new, reversed, opts)),
+		Changes: changes,
+	}
+	if new.Database != "" && new.Database != old.Database {
+		m.Irreversible = append(m.Irreversible, fmt.Sprintf("database %s is not dropped", new.Database))
+	}
+	for _, b := range FindBreakingChanges(old, new, opts) {
+		m.Irreversible = append(m.Irreversible, b.String())
+	}
+	// values written after up may not fit the restored columns
+	for _, b := range FindBreakingChanges(new, reversed, opts) {
+		if b.Column != "" {
+			m.Irreversible = append(m.Irreversible, "down: "+b.String())
+		}
+	}
+	return m
+}
+
+// up and down migration from baseline to f. baseline is nil when the file is new, and f is nil when the file is removed
+func GenMigrationPair(baselineDep dep.INameSpace, baseline *descriptor.FileDescriptorProto, dep dep.INameSpace, f *descriptor.FileDescriptorProto, opts Options) (*Migration, error) {
+	old, new, err := buildMigrationSchemas(baselineDep, baseline, dep, f, opts)
+	if err != nil {
+		return nil, err
+	}
+	return DiffMigration(old, new, opts), nil
+}
@@ -0,0 +1,115 @@
+package gensql
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// naming convention of versioned migration files
+type MigrationFormat string
+
+const (
+	// 000001_name.up.sql and 000001_name.down.sql
+	MigrationGolangMigrate MigrationFormat = "golang-migrate"
+	// 00001_name.sql with -- +goose Up and -- +goose Down
+	MigrationGoose MigrationFormat = "goose"
+	// V1__name.sql and U1__name.sql
+	MigrationFlyway MigrationFormat = "flyway"
+)
+
+func ParseMigrationFormat(name string) (MigrationFormat, error) {
+	switch f := MigrationFormat(name); f {
+	case MigrationGolangMigrate, MigrationGoose, MigrationFlyway:
+		return f, nil
+	default:
+		return "", fmt.Errorf("unknown migration format %s", name)
+	}
+}
+
+var migrationVersionPatterns = map[MigrationFormat]*regexp.Regexp{
+	MigrationGolangMigrate: regexp.MustCompile(`^(\d+)_.*\.(up|down)\.sql$`),
+	MigrationGoose:         regexp.MustCompile(`^(\d+)_.*\.sql$`),
+	MigrationFlyway:        regexp.MustCompile(`^[VU](\d+)__.*\.sql$`),
+}
+
+var defaultVersionDigits = map[MigrationFormat]int{
+	MigrationGolangMigrate: 6,
+	MigrationGoose:         5,
+	MigrationFlyway:        0,
+}
+
+// return version next to the files in the migration directory, and its zero-padded form.
+// the padding of existing files is kept.
+func NextMigrationVersion(format MigrationFormat, existing []string) string {
+	latest, digits := 0, defaultVersionDigits[format]
+	for _, name := range existing {
+		m := migrationVersionPatterns[format].FindStringSubmatch(name)
+		if m == nil {
+			continue
+		}
+		if v, err := strconv.Atoi(m[1]); err == nil && v >= latest {
+			latest = v
+			if format != MigrationFlyway {
+				digits = len(m[1])
+			}
+		}
+	}
+	return fmt.Sprintf("%0*d", digits, latest+1)
+}
+
+// merge migrations of files into one version. down reverts them in the reverse order
+func MergeMigrations(ms []*Migration) *Migration {
+	ret := &Migration{}
+	ups, downs := []string{}, []string{}
+	for i, m := range ms {
+		if m.Up != "" {
+			ups = append(ups, m.Up)
+		}
+		if down := ms[len(ms)-1-i].Down; down != "" {
+			downs = append(downs, down)
+		}
+		ret.Irreversible = append(ret.Irreversible, m.Irreversible...)
+	}
+	ret.Up = strings.Join(ups, "\n\n")
+	ret.Down = strings.Join(downs, "\n\n")
+	return ret
+}
+
+var migrationNameReplacer = regexp.MustCompile(`[^A-Za-z0-9]+`)
+
+func genDownSQL(m *Migration) string {
+	if len(m.Irreversible) == 0 {
+		return m.Down
+	}
+	lines := []string{"-- IRREVERSIBLE: the schema is restored, but data is lost"}
+	for _, i := range m.Irreversible {
+		lines = append(lines, "--   "+i)
+	}
+	return strings.Join(append(lines, m.Down), "\n")
+}
+
+// return file names and contents of a migration
+func GenMigrationFiles(format MigrationFormat, version string, name string, m *Migration) map[string]string {
+	if name == "" {
+		name = "schema"
+	}
+	name = strings.Trim(migrationNameReplacer.ReplaceAllString(name, "_"), "_")
+	switch format {
+	case MigrationGoose:
+		return map[string]string{
+			fmt.Sprintf("%s_%s.sql", version, name): fmt.Sprintf("-- +goose Up\n%s\n\n-- +goose Down\n%s\n", m.Up, genDownSQL(m)),
+		}
+	case MigrationFlyway:
+		return map[string]string{
+			fmt.Sprintf("V%s__%s.sql", version, name): m.Up + "\n",
+			fmt.Sprintf("U%s__%s.sql", version, name): genDownSQL(m) + "\n",
+		}
+	default:
+		return map[string]string{
+			fmt.Sprintf("%s_%s.up.sql", version, name):   m.Up + "\n",
+			fmt.Sprintf("%s_%s.down.sql", version, name): genDownSQL(m) + "\n",
+		}
+	}
+}
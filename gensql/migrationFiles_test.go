@@ -0,0 +1,92 @@
+package gensql
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestNextMigrationVersion(t *testing.T) {
+	tests := []struct {
+		name     string
+		format   MigrationFormat
+		existing []string
+		version  string
+	}{
+		{name: "golang-migrate of empty directory", format: MigrationGolangMigrate, version: "000001"},
+		{name: "goose of empty directory", format: MigrationGoose, version: "00001"},
+		{name: "flyway of empty directory", format: MigrationFlyway, version: "1"},
+		{
+			name:     "golang-migrate",
+			format:   MigrationGolangMigrate,
+			existing: []string{"000002_a.up.sql", "000002_a.down.sql", "000010_b.up.sql", "README.md"},
+			version:  "000011",
+		},
+		{name: "padding of existing files", format: MigrationGolangMigrate, existing: []string{"0003_a.up.sql"}, version: "0004"},
+		{name: "goose", format: MigrationGoose, existing: []string{"00009_a.sql"}, version: "00010"},
+		{name: "flyway", format: MigrationFlyway, existing: []string{"V1__a.sql", "U12__b.sql", "R__view.sql"}, version: "13"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if v := NextMigrationVersion(tt.format, tt.existing); v != tt.version {
+				t.Errorf("version = %s, want %s", v, tt.version)
+			}
+		})
+	}
+}
+
+func TestGenMigrationFiles(t *testing.T) {
+	m := &Migration{Up: "DROP TABLE User;", Down: "CREATE TABLE User (\n\tage INT NOT NULL\n);"}
+	irreversible := &Migration{Up: m.Up, Down: m.Down, Irreversible: []string{"User: the table is dropped with its rows"}}
+	tests := []struct {
+		name      string
+		format    MigrationFormat
+		migration *Migration
+		files     map[string]string
+	}{
+		{
+			name:      "golang-migrate",
+			format:    MigrationGolangMigrate,
+			migration: m,
+			files: map[string]string{
+				"000002_drop_user.up.sql":   "DROP TABLE User;\n",
+				"000002_drop_user.down.sql": "CREATE TABLE User (\n\tage INT NOT NULL\n);\n",
+			},
+		},
+		{
+			name:      "goose",
+			format:    MigrationGoose,
+			migration: m,
+			files: map[string]string{
+				"000002_drop_user.sql": "-- +goose Up\nDROP TABLE User;\n\n-- +goose Down\nCREATE TABLE User (\n\tage INT NOT NULL\n);\n",
+			},
+		},
+		{
+			name:      "flyway",
+			format:    MigrationFlyway,
+			migration: m,
+			files: map[string]string{
+				"V000002__drop_user.sql": "DROP TABLE User;\n",
+				"U000002__drop_user.sql": "CREATE TABLE User (\n\tage INT NOT NULL\n);\n",
+			},
+		},
+		{
+			name:      "irreversible",
+			format:    MigrationGolangMigrate,
+			migration: irreversible,
+			files: map[string]string{
+				"000002_drop_user.up.sql": "DROP TABLE User;\n",
+				"000002_drop_user.down.sql": "-- IRREVERSIBLE: the schema is restored, but data is lost\n" +
+					"--   User: the table is dropped with its rows\n" +
+					"CREATE TABLE User (\n\tage INT NOT NULL\n);\n",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			files := GenMigrationFiles(tt.format, "000002", "drop user!", tt.migration)
+			if !reflect.DeepEqual(files, tt.files) {
+				t.Errorf("files = %q, want %q", files, tt.files)
+			}
+		})
+	}
+}
@@ -0,0 +1,266 @@
+package gensql
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/golang/protobuf/protoc-gen-go/descriptor"
+)
+
+func TestDiffSchema(t *testing.T) {
+	tests := []struct {
+		name    string
+		old     string
+		new     string
+		changes []string
+	}{
+		{
+			name:    "same",
+			old:     `message_type { name: "User" field { name: "age" number: 1 type: TYPE_INT32 label: LABEL_OPTIONAL } }`,
+			new:     `message_type { name: "User" field { name: "age" number: 1 type: TYPE_INT32 label: LABEL_OPTIONAL } }`,
+			changes: []string{},
+		},
+		{
+			name:    "added field",
+			old:     `message_type { name: "User" field { name: "age" number: 1 type: TYPE_INT32 label: LABEL_OPTIONAL } }`,
+			new:     `message_type { name: "User" field { name: "age" number: 1 type: TYPE_INT32 label: LABEL_OPTIONAL } field { name: "gender" number: 2 type: TYPE_ENUM type_name: ".Foo.Gender" label: LABEL_OPTIONAL } }`,
+			changes: []string{"ADD COLUMN: ADD COLUMN gender ENUM('MALE','FEMALE') NOT NULL AFTER age"},
+		},
+		{
+			name:    "field of another type",
+			old:     `message_type { name: "User" field { name: "age" number: 1 type: TYPE_INT32 label: LABEL_OPTIONAL } }`,
+			new:     `message_type { name: "User" field { name: "age" number: 1 type: TYPE_INT64 label: LABEL_OPTIONAL } }`,
+			changes: []string{"MODIFY COLUMN: MODIFY COLUMN age BIGINT NOT NULL"},
+		},
+		{
+			name:    "deleted field",
+			old:     `message_type { name: "User" field { name: "age" number: 1 type: TYPE_INT32 label: LABEL_OPTIONAL } field { name: "name" number: 2 type: TYPE_STRING label: LABEL_OPTIONAL } }`,
+			new:     `message_type { name: "User" field { name: "age" number: 1 type: TYPE_INT32 label: LABEL_OPTIONAL } }`,
+			changes: []string{"DROP COLUMN: DROP COLUMN name"},
+		},
+		{
+			name:    "added index",
+			old:     `message_type { name: "User" field { name: "age" number: 1 type: TYPE_INT32 label: LABEL_OPTIONAL } }`,
+			new:     `message_type { name: "User" field { name: "age" number: 1 type: TYPE_INT32 label: LABEL_OPTIONAL } options { [mySQLTable] { indexes { name: "age_idx" columns: ["age"] } } } }`,
+			changes: []string{"ADD INDEX: ADD INDEX age_idx (age)"},
+		},
+		{
+			name:    "renamed field",
+			old:     `message_type { name: "User" field { name: "age" number: 1 type: TYPE_INT32 label: LABEL_OPTIONAL } }`,
+			new:     `message_type { name: "User" field { name: "years" number: 1 type: TYPE_INT32 label: LABEL_OPTIONAL } }`,
+			changes: []string{"RENAME COLUMN: RENAME COLUMN age TO years"},
+		},
+		{
+			name:    "renamed field of another type",
+			old:     `message_type { name: "User" field { name: "age" number: 1 type: TYPE_INT32 label: LABEL_OPTIONAL } }`,
+			new:     `message_type { name: "User" field { name: "birthday" number: 1 type: TYPE_STRING label: LABEL_OPTIONAL } }`,
+			changes: []string{"DROP COLUMN: DROP COLUMN age", "ADD COLUMN: ADD COLUMN birthday TEXT NOT NULL FIRST"},
+		},
+		{
+			name:    "name reused by another field",
+			old:     `message_type { name: "User" field { name: "age" number: 1 type: TYPE_INT32 label: LABEL_OPTIONAL } }`,
+			new:     `message_type { name: "User" field { name: "age" number: 2 type: TYPE_INT32 label: LABEL_OPTIONAL } }`,
+			changes: []string{"DROP COLUMN: DROP COLUMN age", "ADD COLUMN: ADD COLUMN age INT NOT NULL FIRST"},
+		},
+		{
+			name:    "renamed message",
+			old:     `message_type { name: "SearchRequest" field { name: "query" number: 1 type: TYPE_STRING label: LABEL_OPTIONAL } }`,
+			new:     `message_type { name: "Search" field { name: "query" number: 1 type: TYPE_STRING label: LABEL_OPTIONAL } options { [mySQLTable] { previousNames: ["SearchRequest"] } } }`,
+			changes: []string{"RENAME TABLE: RENAME TABLE SearchRequest TO Search;"},
+		},
+		{
+			name:    "renamed message without previous names",
+			old:     `message_type { name: "SearchRequest" field { name: "query" number: 1 type: TYPE_STRING label: LABEL_OPTIONAL } }`,
+			new:     `message_type { name: "Search" field { name: "query" number: 1 type: TYPE_STRING label: LABEL_OPTIONAL } }`,
+			changes: []string{"CREATE TABLE: CREATE TABLE Search (\n\tquery TEXT NOT NULL,\n\tPROTO_BINARY BLOB NOT NULL\n);", "DROP TABLE: DROP TABLE SearchRequest;"},
+		},
+		{
+			name: "previous name renamed to another message",
+			old:  `message_type { name: "A" field { name: "id" number: 1 type: TYPE_INT64 label: LABEL_OPTIONAL } }`,
+			new: `
+				message_type { name: "B" field { name: "id" number: 1 type: TYPE_INT64 label: LABEL_OPTIONAL } options { [mySQLTable] { previousNames: ["A"] } } }
+				message_type { name: "C" field { name: "id" number: 1 type: TYPE_INT64 label: LABEL_OPTIONAL } options { [mySQLTable] { previousNames: ["A"] } } }`,
+			changes: []string{"RENAME TABLE: RENAME TABLE A TO B;", "CREATE TABLE: CREATE TABLE C (\n\tid BIGINT NOT NULL,\n\tPROTO_BINARY BLOB NOT NULL\n);"},
+		},
+		{
+			name: "name taken over by a renamed message",
+			old: `
+				message_type { name: "A" field { name: "id" number: 1 type: TYPE_INT64 label: LABEL_OPTIONAL } }
+				message_type { name: "B" field { name: "id" number: 1 type: TYPE_INT64 label: LABEL_OPTIONAL } }`,
+			new:     `message_type { name: "B" field { name: "id" number: 1 type: TYPE_INT64 label: LABEL_OPTIONAL } options { [mySQLTable] { previousNames: ["A"] } } }`,
+			changes: []string{"DROP TABLE: DROP TABLE A;"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			changes := []string{}
+			for _, c := range DiffSchema(buildSchema(t, tt.old), buildSchema(t, tt.new), Options{}) {
+				changes = append(changes, string(c.Kind)+": "+c.SQL)
+			}
+			if got, want := strings.Join(changes, "\n"), strings.Join(tt.changes, "\n"); got != want {
+				t.Errorf("changes = \n%s\nwant\n%s", got, want)
+			}
+		})
+	}
+}
+
+func TestGenMigration(t *testing.T) {
+	const user = `message_type { name: "User" field { name: "age" number: 1 type: TYPE_INT32 label: LABEL_OPTIONAL } }`
+	tests := []struct {
+		name     string
+		baseline string
+		sql      string
+	}{
+		{
+			name: "new file",
+			sql:  "CREATE TABLE User (\n\tage INT NOT NULL,\n\tPROTO_BINARY BLOB NOT NULL\n);",
+		},
+		{
+			name:     "clauses merged into ALTER TABLE",
+			baseline: `message_type { name: "User" field { name: "name" number: 2 type: TYPE_STRING label: LABEL_OPTIONAL } }`,
+			sql:      "ALTER TABLE User\n\tDROP COLUMN name,\n\tADD COLUMN age INT NOT NULL FIRST;",
+		},
+		{
+			name:     "same",
+			baseline: user,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dep, f := parseMessages(t, user)
+			baselineDep, baseline := dep, (*descriptor.FileDescriptorProto)(nil)
+			if tt.baseline != "" {
+				baselineDep, baseline = parseMessages(t, tt.baseline)
+			}
+			sql, err := GenMigration(baselineDep, baseline, dep, f, Options{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if sql != tt.sql {
+				t.Errorf("sql = \n%s\nwant\n%s", sql, tt.sql)
+			}
+		})
+	}
+}
+
+func TestDiffSchemaReserved(t *testing.T) {
+	const (
+		age  = `field { name: "age" number: 1 type: TYPE_INT32 label: LABEL_OPTIONAL }`
+		name = `field { name: "name" number: 2 type: TYPE_STRING label: LABEL_OPTIONAL }`
+	)
+	tests := []struct {
+		name   string
+		new    string
+		policy ReservedPolicy
+		sql    string
+	}{
+		{
+			name:   "deleted field without reserved",
+			new:    `message_type { name: "User" ` + age + ` options { [mySQLTable] {} } }`,
+			policy: ReservedKeep,
+			sql:    "ALTER TABLE User\n\tDROP COLUMN name;",
+		},
+		{
+			name: "reserved number",
+			new:  `message_type { name: "User" ` + age + ` reserved_range { start: 2 end: 3 } options { [mySQLTable] {} } }`,
+			sql:  "ALTER TABLE User\n\tDROP COLUMN name;",
+		},
+		{
+			name:   "reserved number kept",
+			new:    `message_type { name: "User" ` + age + ` reserved_range { start: 2 end: 3 } options { [mySQLTable] {} } }`,
+			policy: ReservedKeep,
+			sql:    "ALTER TABLE User\n\tMODIFY COLUMN name TEXT NULL;",
+		},
+		{
+			name:   "reserved name kept",
+			new:    `message_type { name: "User" ` + age + ` reserved_name: "name" options { [mySQLTable] {} } }`,
+			policy: ReservedKeep,
+			sql:    "ALTER TABLE User\n\tMODIFY COLUMN name TEXT NULL;",
+		},
+		// the column of the reserved field cannot be kept under the same name
+		{
+			name:   "reserved name reused",
+			new:    `message_type { name: "User" ` + age + ` field { name: "name" number: 3 type: TYPE_STRING label: LABEL_OPTIONAL } reserved_range { start: 2 end: 3 } options { [mySQLTable] {} } }`,
+			policy: ReservedKeep,
+			sql:    "ALTER TABLE User\n\tDROP COLUMN name,\n\tADD COLUMN name TEXT NOT NULL AFTER age;",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			old := buildSchema(t, `message_type { name: "User" `+age+name+` options { [mySQLTable] {} } }`)
+			changes := DiffSchema(old, buildSchema(t, tt.new), Options{ReservedPolicy: tt.policy})
+			if sql := GenMigrationSQL(changes); sql != tt.sql {
+				t.Errorf("sql = \n%s\nwant\n%s", sql, tt.sql)
+			}
+		})
+	}
+}
+
+func TestDiffMigration(t *testing.T) {
+	const (
+		age   = `field { name: "age" number: 1 type: TYPE_INT32 label: LABEL_OPTIONAL }`
+		years = `field { name: "years" number: 1 type: TYPE_INT32 label: LABEL_OPTIONAL }`
+	)
+	tests := []struct {
+		name         string
+		old          string
+		new          string
+		up           string
+		down         string
+		irreversible []string
+	}{
+		{
+			name:         "add column",
+			old:          `message_type { name: "User" ` + age + ` options { [mySQLTable] {} } }`,
+			new:          `message_type { name: "User" ` + age + ` field { name: "locale" number: 2 type: TYPE_STRING label: LABEL_OPTIONAL } options { [mySQLTable] {} } }`,
+			up:           "ALTER TABLE User\n\tADD COLUMN locale TEXT NOT NULL AFTER age;",
+			down:         "ALTER TABLE User\n\tDROP COLUMN locale;",
+			irreversible: []string{"down: Foo.User.locale (User.locale): the column is dropped with its values"},
+		},
+		{
+			name: "rename table",
+			old:  `message_type { name: "User" ` + age + ` options { [mySQLTable] {} } }`,
+			new:  `message_type { name: "Member" ` + age + ` options { [mySQLTable] { previousNames: ["User"] } } }`,
+			up:   "RENAME TABLE User TO Member;",
+			down: "RENAME TABLE Member TO User;",
+		},
+		{
+			name: "rename column",
+			old:  `message_type { name: "User" ` + age + ` options { [mySQLTable] {} } }`,
+			new:  `message_type { name: "User" ` + years + ` options { [mySQLTable] {} } }`,
+			up:   "ALTER TABLE User\n\tRENAME COLUMN age TO years;",
+			down: "ALTER TABLE User\n\tRENAME COLUMN years TO age;",
+		},
+		{
+			name:         "widen column",
+			old:          `message_type { name: "User" ` + age + ` options { [mySQLTable] {} } }`,
+			new:          `message_type { name: "User" field { name: "age" number: 1 type: TYPE_INT64 label: LABEL_OPTIONAL } options { [mySQLTable] {} } }`,
+			up:           "ALTER TABLE User\n\tMODIFY COLUMN age BIGINT NOT NULL;",
+			down:         "ALTER TABLE User\n\tMODIFY COLUMN age INT NOT NULL;",
+			irreversible: []string{"down: Foo.User.age (User.age): BIGINT -> INT narrows the range. out of range values are rejected"},
+		},
+		{
+			name:         "drop table",
+			old:          `message_type { name: "User" ` + age + ` options { [mySQLTable] {} } }`,
+			new:          ``,
+			up:           "DROP TABLE User;",
+			down:         "CREATE TABLE User (\n\tage INT NOT NULL,\n\tPROTO_BINARY BLOB NOT NULL\n);",
+			irreversible: []string{"User: the table is dropped with its rows"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := DiffMigration(buildSchema(t, tt.old), buildSchema(t, tt.new), Options{})
+			if m.Up != tt.up {
+				t.Errorf("up = \n%s\nwant\n%s", m.Up, tt.up)
+			}
+			if m.Down != tt.down {
+				t.Errorf("down = \n%s\nwant\n%s", m.Down, tt.down)
+			}
+			if !reflect.DeepEqual(m.Irreversible, tt.irreversible) {
+				t.Errorf("irreversible = %q, want %q", m.Irreversible, tt.irreversible)
+			}
+		})
+	}
+}
@@ -21,32 +21,619 @@ const (
 	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
 )
 
+// meaning of string field. native types are used where available.
+type MySQLColumn_Semantic int32
+
+const (
+	MySQLColumn_NONE  MySQLColumn_Semantic = 0
+	MySQLColumn_UUID  MySQLColumn_Semantic = 1
+	MySQLColumn_INET6 MySQLColumn_Semantic = 2
+)
+
+// Enum value maps for MySQLColumn_Semantic.
+var (
+	MySQLColumn_Semantic_name = map[int32]string{
+		0: "NONE",
+		1: "UUID",
+		2: "INET6",
+	}
+	MySQLColumn_Semantic_value = map[string]int32{
+		"NONE":  0,
+		"UUID":  1,
+		"INET6": 2,
+	}
+)
+
+func (x MySQLColumn_Semantic) Enum() *MySQLColumn_Semantic {
+	p := new(MySQLColumn_Semantic)
+	*p = x
+	return p
+}
+
+func (x MySQLColumn_Semantic) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (MySQLColumn_Semantic) Descriptor() protoreflect.EnumDescriptor {
+	return file_mySQLOptions_proto_enumTypes[0].Descriptor()
+}
+
+func (MySQLColumn_Semantic) Type() protoreflect.EnumType {
+	return &file_mySQLOptions_proto_enumTypes[0]
+}
+
+func (x MySQLColumn_Semantic) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use MySQLColumn_Semantic.Descriptor instead.
+func (MySQLColumn_Semantic) EnumDescriptor() ([]byte, []int) {
+	return file_mySQLOptions_proto_rawDescGZIP(), []int{1, 0}
+}
+
+type MySQLPartition_Type int32
+
+const (
+	MySQLPartition_RANGE MySQLPartition_Type = 0
+	MySQLPartition_LIST  MySQLPartition_Type = 1
+	MySQLPartition_HASH  MySQLPartition_Type = 2
+	MySQLPartition_KEY   MySQLPartition_Type = 3
+)
+
+// Enum value maps for MySQLPartition_Type.
+var (
+	MySQLPartition_Type_name = map[int32]string{
+		0: "RANGE",
+		1: "LIST",
+		2: "HASH",
+		3: "KEY",
+	}
+	MySQLPartition_Type_value = map[string]int32{
+		"RANGE": 0,
+		"LIST":  1,
+		"HASH":  2,
+		"KEY":   3,
+	}
+)
+
+func (x MySQLPartition_Type) Enum() *MySQLPartition_Type {
+	p := new(MySQLPartition_Type)
+	*p = x
+	return p
+}
+
+func (x MySQLPartition_Type) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (MySQLPartition_Type) Descriptor() protoreflect.EnumDescriptor {
+	return file_mySQLOptions_proto_enumTypes[1].Descriptor()
+}
+
+func (MySQLPartition_Type) Type() protoreflect.EnumType {
+	return &file_mySQLOptions_proto_enumTypes[1]
+}
+
+func (x MySQLPartition_Type) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use MySQLPartition_Type.Descriptor instead.
+func (MySQLPartition_Type) EnumDescriptor() ([]byte, []int) {
+	return file_mySQLOptions_proto_rawDescGZIP(), []int{6, 0}
+}
+
 type MySQLType struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	TypeName string   `protobuf:"bytes,1,opt,name=typeName,proto3" json:"typeName,omitempty"`
-	Args     []string `protobuf:"bytes,2,rep,name=args,proto3" json:"args,omitempty"`
+	TypeName string   `protobuf:"bytes,1,opt,name=typeName,proto3" json:"typeName,omitempty"`
+	Args     []string `protobuf:"bytes,2,rep,name=args,proto3" json:"args,omitempty"`
+}
+
+func (x *MySQLType) Reset() {
+	*x = MySQLType{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_mySQLOptions_proto_msgTypes[0]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *MySQLType) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MySQLType) ProtoMessage() {}
+
+func (x *MySQLType) ProtoReflect() protoreflect.Message {
+	mi := &file_mySQLOptions_proto_msgTypes[0]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MySQLType.ProtoReflect.Descriptor instead.
+func (*MySQLType) Descriptor() ([]byte, []int) {
+	return file_mySQLOptions_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *MySQLType) GetTypeName() string {
+	if x != nil {
+		return x.TypeName
+	}
+	return ""
+}
+
+func (x *MySQLType) GetArgs() []string {
+	if x != nil {
+		return x.Args
+	}
+	return nil
+}
+
+type MySQLColumn struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// AUTO_INCREMENT, or identity column on PostgreSQL.
+	// the column is left out of INSERT tuples generated by helpers.
+	AutoIncrement bool                 `protobuf:"varint,1,opt,name=autoIncrement,proto3" json:"autoIncrement,omitempty"`
+	Semantic      MySQLColumn_Semantic `protobuf:"varint,2,opt,name=semantic,proto3,enum=MySQLColumn_Semantic" json:"semantic,omitempty"`
+	// hidden from SELECT *. MySQL 8.0.23+.
+	Invisible bool `protobuf:"varint,3,opt,name=invisible,proto3" json:"invisible,omitempty"`
+	// CHECK (expr). enforced from MySQL 8.0.16.
+	Check string `protobuf:"bytes,4,opt,name=check,proto3" json:"check,omitempty"`
+	// DEFAULT (expr). MySQL 8.0.13+.
+	DefaultExpression string `protobuf:"bytes,5,opt,name=defaultExpression,proto3" json:"defaultExpression,omitempty"`
+}
+
+func (x *MySQLColumn) Reset() {
+	*x = MySQLColumn{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_mySQLOptions_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *MySQLColumn) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MySQLColumn) ProtoMessage() {}
+
+func (x *MySQLColumn) ProtoReflect() protoreflect.Message {
+	mi := &file_mySQLOptions_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MySQLColumn.ProtoReflect.Descriptor instead.
+func (*MySQLColumn) Descriptor() ([]byte, []int) {
+	return file_mySQLOptions_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *MySQLColumn) GetAutoIncrement() bool {
+	if x != nil {
+		return x.AutoIncrement
+	}
+	return false
+}
+
+func (x *MySQLColumn) GetSemantic() MySQLColumn_Semantic {
+	if x != nil {
+		return x.Semantic
+	}
+	return MySQLColumn_NONE
+}
+
+func (x *MySQLColumn) GetInvisible() bool {
+	if x != nil {
+		return x.Invisible
+	}
+	return false
+}
+
+func (x *MySQLColumn) GetCheck() string {
+	if x != nil {
+		return x.Check
+	}
+	return ""
+}
+
+func (x *MySQLColumn) GetDefaultExpression() string {
+	if x != nil {
+		return x.DefaultExpression
+	}
+	return ""
+}
+
+// bookkeeping columns without corresponding proto field.
+// empty names fall back to created_at, updated_at and row_version.
+type MySQLAuditColumns struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	CreatedAt  string `protobuf:"bytes,1,opt,name=createdAt,proto3" json:"createdAt,omitempty"`
+	UpdatedAt  string `protobuf:"bytes,2,opt,name=updatedAt,proto3" json:"updatedAt,omitempty"`
+	RowVersion string `protobuf:"bytes,3,opt,name=rowVersion,proto3" json:"rowVersion,omitempty"`
+}
+
+func (x *MySQLAuditColumns) Reset() {
+	*x = MySQLAuditColumns{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_mySQLOptions_proto_msgTypes[2]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *MySQLAuditColumns) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MySQLAuditColumns) ProtoMessage() {}
+
+func (x *MySQLAuditColumns) ProtoReflect() protoreflect.Message {
+	mi := &file_mySQLOptions_proto_msgTypes[2]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MySQLAuditColumns.ProtoReflect.Descriptor instead.
+func (*MySQLAuditColumns) Descriptor() ([]byte, []int) {
+	return file_mySQLOptions_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *MySQLAuditColumns) GetCreatedAt() string {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return ""
+}
+
+func (x *MySQLAuditColumns) GetUpdatedAt() string {
+	if x != nil {
+		return x.UpdatedAt
+	}
+	return ""
+}
+
+func (x *MySQLAuditColumns) GetRowVersion() string {
+	if x != nil {
+		return x.RowVersion
+	}
+	return ""
+}
+
+// nullable deleted_at column. empty column falls back to deleted_at.
+type MySQLSoftDelete struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Column string `protobuf:"bytes,1,opt,name=column,proto3" json:"column,omitempty"`
+	// create <table>_live view which hides deleted rows
+	LiveView bool `protobuf:"varint,2,opt,name=liveView,proto3" json:"liveView,omitempty"`
+}
+
+func (x *MySQLSoftDelete) Reset() {
+	*x = MySQLSoftDelete{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_mySQLOptions_proto_msgTypes[3]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *MySQLSoftDelete) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MySQLSoftDelete) ProtoMessage() {}
+
+func (x *MySQLSoftDelete) ProtoReflect() protoreflect.Message {
+	mi := &file_mySQLOptions_proto_msgTypes[3]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MySQLSoftDelete.ProtoReflect.Descriptor instead.
+func (*MySQLSoftDelete) Descriptor() ([]byte, []int) {
+	return file_mySQLOptions_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *MySQLSoftDelete) GetColumn() string {
+	if x != nil {
+		return x.Column
+	}
+	return ""
+}
+
+func (x *MySQLSoftDelete) GetLiveView() bool {
+	if x != nil {
+		return x.LiveView
+	}
+	return false
+}
+
+type MySQLIndex struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Name    string   `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Columns []string `protobuf:"bytes,2,rep,name=columns,proto3" json:"columns,omitempty"`
+	Unique  bool     `protobuf:"varint,3,opt,name=unique,proto3" json:"unique,omitempty"`
+	// let deleted rows share keys with live rows. requires softDelete.
+	IncludeSoftDelete bool `protobuf:"varint,4,opt,name=includeSoftDelete,proto3" json:"includeSoftDelete,omitempty"`
+	// index elements of a repeated scalar field stored as JSON array. MySQL 8.0.17+.
+	MultiValued bool `protobuf:"varint,5,opt,name=multiValued,proto3" json:"multiValued,omitempty"`
+}
+
+func (x *MySQLIndex) Reset() {
+	*x = MySQLIndex{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_mySQLOptions_proto_msgTypes[4]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *MySQLIndex) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MySQLIndex) ProtoMessage() {}
+
+func (x *MySQLIndex) ProtoReflect() protoreflect.Message {
+	mi := &file_mySQLOptions_proto_msgTypes[4]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MySQLIndex.ProtoReflect.Descriptor instead.
+func (*MySQLIndex) Descriptor() ([]byte, []int) {
+	return file_mySQLOptions_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *MySQLIndex) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *MySQLIndex) GetColumns() []string {
+	if x != nil {
+		return x.Columns
+	}
+	return nil
+}
+
+func (x *MySQLIndex) GetUnique() bool {
+	if x != nil {
+		return x.Unique
+	}
+	return false
+}
+
+func (x *MySQLIndex) GetIncludeSoftDelete() bool {
+	if x != nil {
+		return x.IncludeSoftDelete
+	}
+	return false
+}
+
+func (x *MySQLIndex) GetMultiValued() bool {
+	if x != nil {
+		return x.MultiValued
+	}
+	return false
+}
+
+type MySQLPartitionDefinition struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Name string `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	// "VALUES LESS THAN" for RANGE, "VALUES IN" for LIST. e.g. "1000", "MAXVALUE", "1,2,3"
+	Values string `protobuf:"bytes,2,opt,name=values,proto3" json:"values,omitempty"`
+}
+
+func (x *MySQLPartitionDefinition) Reset() {
+	*x = MySQLPartitionDefinition{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_mySQLOptions_proto_msgTypes[5]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *MySQLPartitionDefinition) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MySQLPartitionDefinition) ProtoMessage() {}
+
+func (x *MySQLPartitionDefinition) ProtoReflect() protoreflect.Message {
+	mi := &file_mySQLOptions_proto_msgTypes[5]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MySQLPartitionDefinition.ProtoReflect.Descriptor instead.
+func (*MySQLPartitionDefinition) Descriptor() ([]byte, []int) {
+	return file_mySQLOptions_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *MySQLPartitionDefinition) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *MySQLPartitionDefinition) GetValues() string {
+	if x != nil {
+		return x.Values
+	}
+	return ""
+}
+
+type MySQLPartition struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Type MySQLPartition_Type `protobuf:"varint,1,opt,name=type,proto3,enum=MySQLPartition_Type" json:"type,omitempty"`
+	// field name of the partitioning column
+	Column string `protobuf:"bytes,2,opt,name=column,proto3" json:"column,omitempty"`
+	// use RANGE COLUMNS / LIST COLUMNS, which accepts non-integer columns
+	Columns bool `protobuf:"varint,3,opt,name=columns,proto3" json:"columns,omitempty"`
+	// PARTITIONS n for HASH and KEY
+	Partitions  uint32                      `protobuf:"varint,4,opt,name=partitions,proto3" json:"partitions,omitempty"`
+	Definitions []*MySQLPartitionDefinition `protobuf:"bytes,5,rep,name=definitions,proto3" json:"definitions,omitempty"`
+}
+
+func (x *MySQLPartition) Reset() {
+	*x = MySQLPartition{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_mySQLOptions_proto_msgTypes[6]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *MySQLPartition) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MySQLPartition) ProtoMessage() {}
+
+func (x *MySQLPartition) ProtoReflect() protoreflect.Message {
+	mi := &file_mySQLOptions_proto_msgTypes[6]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MySQLPartition.ProtoReflect.Descriptor instead.
+func (*MySQLPartition) Descriptor() ([]byte, []int) {
+	return file_mySQLOptions_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *MySQLPartition) GetType() MySQLPartition_Type {
+	if x != nil {
+		return x.Type
+	}
+	return MySQLPartition_RANGE
+}
+
+func (x *MySQLPartition) GetColumn() string {
+	if x != nil {
+		return x.Column
+	}
+	return ""
+}
+
+func (x *MySQLPartition) GetColumns() bool {
+	if x != nil {
+		return x.Columns
+	}
+	return false
+}
+
+func (x *MySQLPartition) GetPartitions() uint32 {
+	if x != nil {
+		return x.Partitions
+	}
+	return 0
+}
+
+func (x *MySQLPartition) GetDefinitions() []*MySQLPartitionDefinition {
+	if x != nil {
+		return x.Definitions
+	}
+	return nil
+}
+
+// table settings for dialect=clickhouse
+type MySQLClickHouse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// MergeTree family engine. empty falls back to MergeTree
+	Engine string `protobuf:"bytes,1,opt,name=engine,proto3" json:"engine,omitempty"`
+	// columns or expressions of ORDER BY. empty falls back to primaryKey
+	OrderBy []string `protobuf:"bytes,2,rep,name=orderBy,proto3" json:"orderBy,omitempty"`
+	// e.g. "toYYYYMM(created_at)"
+	PartitionBy string `protobuf:"bytes,3,opt,name=partitionBy,proto3" json:"partitionBy,omitempty"`
 }
 
-func (x *MySQLType) Reset() {
-	*x = MySQLType{}
+func (x *MySQLClickHouse) Reset() {
+	*x = MySQLClickHouse{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_mySQLOptions_proto_msgTypes[0]
+		mi := &file_mySQLOptions_proto_msgTypes[7]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
 }
 
-func (x *MySQLType) String() string {
+func (x *MySQLClickHouse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*MySQLType) ProtoMessage() {}
+func (*MySQLClickHouse) ProtoMessage() {}
 
-func (x *MySQLType) ProtoReflect() protoreflect.Message {
-	mi := &file_mySQLOptions_proto_msgTypes[0]
+func (x *MySQLClickHouse) ProtoReflect() protoreflect.Message {
+	mi := &file_mySQLOptions_proto_msgTypes[7]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -57,21 +644,222 @@ func (x *MySQLType) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use MySQLType.ProtoReflect.Descriptor instead.
-func (*MySQLType) Descriptor() ([]byte, []int) {
-	return file_mySQLOptions_proto_rawDescGZIP(), []int{0}
+// Deprecated: Use MySQLClickHouse.ProtoReflect.Descriptor instead.
+func (*MySQLClickHouse) Descriptor() ([]byte, []int) {
+	return file_mySQLOptions_proto_rawDescGZIP(), []int{7}
 }
 
-func (x *MySQLType) GetTypeName() string {
+func (x *MySQLClickHouse) GetEngine() string {
 	if x != nil {
-		return x.TypeName
+		return x.Engine
 	}
 	return ""
 }
 
-func (x *MySQLType) GetArgs() []string {
+func (x *MySQLClickHouse) GetOrderBy() []string {
 	if x != nil {
-		return x.Args
+		return x.OrderBy
+	}
+	return nil
+}
+
+func (x *MySQLClickHouse) GetPartitionBy() string {
+	if x != nil {
+		return x.PartitionBy
+	}
+	return ""
+}
+
+type MySQLForeignKey struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Name    string   `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Columns []string `protobuf:"bytes,2,rep,name=columns,proto3" json:"columns,omitempty"`
+	// referenced message, relative to the package of the file or fully qualified with leading "."
+	References string `protobuf:"bytes,3,opt,name=references,proto3" json:"references,omitempty"`
+	// empty falls back to primaryKey of the referenced message
+	ReferencedColumns []string `protobuf:"bytes,4,rep,name=referencedColumns,proto3" json:"referencedColumns,omitempty"`
+	// e.g. "CASCADE", "SET NULL"
+	OnDelete string `protobuf:"bytes,5,opt,name=onDelete,proto3" json:"onDelete,omitempty"`
+	OnUpdate string `protobuf:"bytes,6,opt,name=onUpdate,proto3" json:"onUpdate,omitempty"`
+}
+
+func (x *MySQLForeignKey) Reset() {
+	*x = MySQLForeignKey{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_mySQLOptions_proto_msgTypes[8]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *MySQLForeignKey) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MySQLForeignKey) ProtoMessage() {}
+
+func (x *MySQLForeignKey) ProtoReflect() protoreflect.Message {
+	mi := &file_mySQLOptions_proto_msgTypes[8]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MySQLForeignKey.ProtoReflect.Descriptor instead.
+func (*MySQLForeignKey) Descriptor() ([]byte, []int) {
+	return file_mySQLOptions_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *MySQLForeignKey) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *MySQLForeignKey) GetColumns() []string {
+	if x != nil {
+		return x.Columns
+	}
+	return nil
+}
+
+func (x *MySQLForeignKey) GetReferences() string {
+	if x != nil {
+		return x.References
+	}
+	return ""
+}
+
+func (x *MySQLForeignKey) GetReferencedColumns() []string {
+	if x != nil {
+		return x.ReferencedColumns
+	}
+	return nil
+}
+
+func (x *MySQLForeignKey) GetOnDelete() string {
+	if x != nil {
+		return x.OnDelete
+	}
+	return ""
+}
+
+func (x *MySQLForeignKey) GetOnUpdate() string {
+	if x != nil {
+		return x.OnUpdate
+	}
+	return ""
+}
+
+type MySQLTable struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	AuditColumns *MySQLAuditColumns `protobuf:"bytes,1,opt,name=auditColumns,proto3" json:"auditColumns,omitempty"`
+	SoftDelete   *MySQLSoftDelete   `protobuf:"bytes,2,opt,name=softDelete,proto3" json:"softDelete,omitempty"`
+	PrimaryKey   []string           `protobuf:"bytes,3,rep,name=primaryKey,proto3" json:"primaryKey,omitempty"`
+	Indexes      []*MySQLIndex      `protobuf:"bytes,4,rep,name=indexes,proto3" json:"indexes,omitempty"`
+	Partition    *MySQLPartition    `protobuf:"bytes,5,opt,name=partition,proto3" json:"partition,omitempty"`
+	ClickHouse   *MySQLClickHouse   `protobuf:"bytes,6,opt,name=clickHouse,proto3" json:"clickHouse,omitempty"`
+	ForeignKeys  []*MySQLForeignKey `protobuf:"bytes,7,rep,name=foreignKeys,proto3" json:"foreignKeys,omitempty"`
+	// former names of the message. migrations rename the table instead of dropping it
+	PreviousNames []string `protobuf:"bytes,8,rep,name=previousNames,proto3" json:"previousNames,omitempty"`
+}
+
+func (x *MySQLTable) Reset() {
+	*x = MySQLTable{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_mySQLOptions_proto_msgTypes[9]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *MySQLTable) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MySQLTable) ProtoMessage() {}
+
+func (x *MySQLTable) ProtoReflect() protoreflect.Message {
+	mi := &file_mySQLOptions_proto_msgTypes[9]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MySQLTable.ProtoReflect.Descriptor instead.
+func (*MySQLTable) Descriptor() ([]byte, []int) {
+	return file_mySQLOptions_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *MySQLTable) GetAuditColumns() *MySQLAuditColumns {
+	if x != nil {
+		return x.AuditColumns
+	}
+	return nil
+}
+
+func (x *MySQLTable) GetSoftDelete() *MySQLSoftDelete {
+	if x != nil {
+		return x.SoftDelete
+	}
+	return nil
+}
+
+func (x *MySQLTable) GetPrimaryKey() []string {
+	if x != nil {
+		return x.PrimaryKey
+	}
+	return nil
+}
+
+func (x *MySQLTable) GetIndexes() []*MySQLIndex {
+	if x != nil {
+		return x.Indexes
+	}
+	return nil
+}
+
+func (x *MySQLTable) GetPartition() *MySQLPartition {
+	if x != nil {
+		return x.Partition
+	}
+	return nil
+}
+
+func (x *MySQLTable) GetClickHouse() *MySQLClickHouse {
+	if x != nil {
+		return x.ClickHouse
+	}
+	return nil
+}
+
+func (x *MySQLTable) GetForeignKeys() []*MySQLForeignKey {
+	if x != nil {
+		return x.ForeignKeys
+	}
+	return nil
+}
+
+func (x *MySQLTable) GetPreviousNames() []string {
+	if x != nil {
+		return x.PreviousNames
 	}
 	return nil
 }
@@ -85,12 +873,36 @@ var file_mySQLOptions_proto_extTypes = []protoimpl.ExtensionInfo{
 		Tag:           "bytes,50000,opt,name=mySQLType",
 		Filename:      "mySQLOptions.proto",
 	},
+	{
+		ExtendedType:  (*descriptorpb.FieldOptions)(nil),
+		ExtensionType: (*MySQLColumn)(nil),
+		Field:         50002,
+		Name:          "mySQLColumn",
+		Tag:           "bytes,50002,opt,name=mySQLColumn",
+		Filename:      "mySQLOptions.proto",
+	},
+	{
+		ExtendedType:  (*descriptorpb.MessageOptions)(nil),
+		ExtensionType: (*MySQLTable)(nil),
+		Field:         50001,
+		Name:          "mySQLTable",
+		Tag:           "bytes,50001,opt,name=mySQLTable",
+		Filename:      "mySQLOptions.proto",
+	},
 }
 
 // Extension fields to descriptorpb.FieldOptions.
 var (
 	// optional MySQLType mySQLType = 50000;
 	E_MySQLType = &file_mySQLOptions_proto_extTypes[0]
+	// optional MySQLColumn mySQLColumn = 50002;
+	E_MySQLColumn = &file_mySQLOptions_proto_extTypes[1]
+)
+
+// Extension fields to descriptorpb.MessageOptions.
+var (
+	// optional MySQLTable mySQLTable = 50001;
+	E_MySQLTable = &file_mySQLOptions_proto_extTypes[2]
 )
 
 var File_mySQLOptions_proto protoreflect.FileDescriptor
@@ -103,13 +915,123 @@ var file_mySQLOptions_proto_rawDesc = []byte{
 	0x79, 0x70, 0x65, 0x12, 0x1a, 0x0a, 0x08, 0x74, 0x79, 0x70, 0x65, 0x4e, 0x61, 0x6d, 0x65, 0x18,
 	0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x74, 0x79, 0x70, 0x65, 0x4e, 0x61, 0x6d, 0x65, 0x12,
 	0x12, 0x0a, 0x04, 0x61, 0x72, 0x67, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x09, 0x52, 0x04, 0x61,
-	0x72, 0x67, 0x73, 0x3a, 0x49, 0x0a, 0x09, 0x6d, 0x79, 0x53, 0x51, 0x4c, 0x54, 0x79, 0x70, 0x65,
-	0x12, 0x1d, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62,
-	0x75, 0x66, 0x2e, 0x46, 0x69, 0x65, 0x6c, 0x64, 0x4f, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x18,
-	0xd0, 0x86, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0a, 0x2e, 0x4d, 0x79, 0x53, 0x51, 0x4c, 0x54,
-	0x79, 0x70, 0x65, 0x52, 0x09, 0x6d, 0x79, 0x53, 0x51, 0x4c, 0x54, 0x79, 0x70, 0x65, 0x42, 0x0a,
-	0x5a, 0x08, 0x2e, 0x2f, 0x67, 0x65, 0x6e, 0x73, 0x71, 0x6c, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74,
-	0x6f, 0x33,
+	0x72, 0x67, 0x73, 0x22, 0xf3, 0x01, 0x0a, 0x0b, 0x4d, 0x79, 0x53, 0x51, 0x4c, 0x43, 0x6f, 0x6c,
+	0x75, 0x6d, 0x6e, 0x12, 0x24, 0x0a, 0x0d, 0x61, 0x75, 0x74, 0x6f, 0x49, 0x6e, 0x63, 0x72, 0x65,
+	0x6d, 0x65, 0x6e, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0d, 0x61, 0x75, 0x74, 0x6f,
+	0x49, 0x6e, 0x63, 0x72, 0x65, 0x6d, 0x65, 0x6e, 0x74, 0x12, 0x31, 0x0a, 0x08, 0x73, 0x65, 0x6d,
+	0x61, 0x6e, 0x74, 0x69, 0x63, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x15, 0x2e, 0x4d, 0x79,
+	0x53, 0x51, 0x4c, 0x43, 0x6f, 0x6c, 0x75, 0x6d, 0x6e, 0x2e, 0x53, 0x65, 0x6d, 0x61, 0x6e, 0x74,
+	0x69, 0x63, 0x52, 0x08, 0x73, 0x65, 0x6d, 0x61, 0x6e, 0x74, 0x69, 0x63, 0x12, 0x1c, 0x0a, 0x09,
+	0x69, 0x6e, 0x76, 0x69, 0x73, 0x69, 0x62, 0x6c, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x08, 0x52,
+	0x09, 0x69, 0x6e, 0x76, 0x69, 0x73, 0x69, 0x62, 0x6c, 0x65, 0x12, 0x14, 0x0a, 0x05, 0x63, 0x68,
+	0x65, 0x63, 0x6b, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x63, 0x68, 0x65, 0x63, 0x6b,
+	0x12, 0x2c, 0x0a, 0x11, 0x64, 0x65, 0x66, 0x61, 0x75, 0x6c, 0x74, 0x45, 0x78, 0x70, 0x72, 0x65,
+	0x73, 0x73, 0x69, 0x6f, 0x6e, 0x18, 0x05, 0x20, 0x01, 0x28, 0x09, 0x52, 0x11, 0x64, 0x65, 0x66,
+	0x61, 0x75, 0x6c, 0x74, 0x45, 0x78, 0x70, 0x72, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x22, 0x29,
+	0x0a, 0x08, 0x53, 0x65, 0x6d, 0x61, 0x6e, 0x74, 0x69, 0x63, 0x12, 0x08, 0x0a, 0x04, 0x4e, 0x4f,
+	0x4e, 0x45, 0x10, 0x00, 0x12, 0x08, 0x0a, 0x04, 0x55, 0x55, 0x49, 0x44, 0x10, 0x01, 0x12, 0x09,
+	0x0a, 0x05, 0x49, 0x4e, 0x45, 0x54, 0x36, 0x10, 0x02, 0x22, 0x6f, 0x0a, 0x11, 0x4d, 0x79, 0x53,
+	0x51, 0x4c, 0x41, 0x75, 0x64, 0x69, 0x74, 0x43, 0x6f, 0x6c, 0x75, 0x6d, 0x6e, 0x73, 0x12, 0x1c,
+	0x0a, 0x09, 0x63, 0x72, 0x65, 0x61, 0x74, 0x65, 0x64, 0x41, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x09, 0x63, 0x72, 0x65, 0x61, 0x74, 0x65, 0x64, 0x41, 0x74, 0x12, 0x1c, 0x0a, 0x09,
+	0x75, 0x70, 0x64, 0x61, 0x74, 0x65, 0x64, 0x41, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x09, 0x75, 0x70, 0x64, 0x61, 0x74, 0x65, 0x64, 0x41, 0x74, 0x12, 0x1e, 0x0a, 0x0a, 0x72, 0x6f,
+	0x77, 0x56, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0a,
+	0x72, 0x6f, 0x77, 0x56, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x22, 0x45, 0x0a, 0x0f, 0x4d, 0x79,
+	0x53, 0x51, 0x4c, 0x53, 0x6f, 0x66, 0x74, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x12, 0x16, 0x0a,
+	0x06, 0x63, 0x6f, 0x6c, 0x75, 0x6d, 0x6e, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x63,
+	0x6f, 0x6c, 0x75, 0x6d, 0x6e, 0x12, 0x1a, 0x0a, 0x08, 0x6c, 0x69, 0x76, 0x65, 0x56, 0x69, 0x65,
+	0x77, 0x18, 0x02, 0x20, 0x01, 0x28, 0x08, 0x52, 0x08, 0x6c, 0x69, 0x76, 0x65, 0x56, 0x69, 0x65,
+	0x77, 0x22, 0xa2, 0x01, 0x0a, 0x0a, 0x4d, 0x79, 0x53, 0x51, 0x4c, 0x49, 0x6e, 0x64, 0x65, 0x78,
+	0x12, 0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04,
+	0x6e, 0x61, 0x6d, 0x65, 0x12, 0x18, 0x0a, 0x07, 0x63, 0x6f, 0x6c, 0x75, 0x6d, 0x6e, 0x73, 0x18,
+	0x02, 0x20, 0x03, 0x28, 0x09, 0x52, 0x07, 0x63, 0x6f, 0x6c, 0x75, 0x6d, 0x6e, 0x73, 0x12, 0x16,
+	0x0a, 0x06, 0x75, 0x6e, 0x69, 0x71, 0x75, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x08, 0x52, 0x06,
+	0x75, 0x6e, 0x69, 0x71, 0x75, 0x65, 0x12, 0x2c, 0x0a, 0x11, 0x69, 0x6e, 0x63, 0x6c, 0x75, 0x64,
+	0x65, 0x53, 0x6f, 0x66, 0x74, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x18, 0x04, 0x20, 0x01, 0x28,
+	0x08, 0x52, 0x11, 0x69, 0x6e, 0x63, 0x6c, 0x75, 0x64, 0x65, 0x53, 0x6f, 0x66, 0x74, 0x44, 0x65,
+	0x6c, 0x65, 0x74, 0x65, 0x12, 0x20, 0x0a, 0x0b, 0x6d, 0x75, 0x6c, 0x74, 0x69, 0x56, 0x61, 0x6c,
+	0x75, 0x65, 0x64, 0x18, 0x05, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0b, 0x6d, 0x75, 0x6c, 0x74, 0x69,
+	0x56, 0x61, 0x6c, 0x75, 0x65, 0x64, 0x22, 0x46, 0x0a, 0x18, 0x4d, 0x79, 0x53, 0x51, 0x4c, 0x50,
+	0x61, 0x72, 0x74, 0x69, 0x74, 0x69, 0x6f, 0x6e, 0x44, 0x65, 0x66, 0x69, 0x6e, 0x69, 0x74, 0x69,
+	0x6f, 0x6e, 0x12, 0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x12, 0x16, 0x0a, 0x06, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x73,
+	0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x73, 0x22, 0xf9,
+	0x01, 0x0a, 0x0e, 0x4d, 0x79, 0x53, 0x51, 0x4c, 0x50, 0x61, 0x72, 0x74, 0x69, 0x74, 0x69, 0x6f,
+	0x6e, 0x12, 0x28, 0x0a, 0x04, 0x74, 0x79, 0x70, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0e, 0x32,
+	0x14, 0x2e, 0x4d, 0x79, 0x53, 0x51, 0x4c, 0x50, 0x61, 0x72, 0x74, 0x69, 0x74, 0x69, 0x6f, 0x6e,
+	0x2e, 0x54, 0x79, 0x70, 0x65, 0x52, 0x04, 0x74, 0x79, 0x70, 0x65, 0x12, 0x16, 0x0a, 0x06, 0x63,
+	0x6f, 0x6c, 0x75, 0x6d, 0x6e, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x63, 0x6f, 0x6c,
+	0x75, 0x6d, 0x6e, 0x12, 0x18, 0x0a, 0x07, 0x63, 0x6f, 0x6c, 0x75, 0x6d, 0x6e, 0x73, 0x18, 0x03,
+	0x20, 0x01, 0x28, 0x08, 0x52, 0x07, 0x63, 0x6f, 0x6c, 0x75, 0x6d, 0x6e, 0x73, 0x12, 0x1e, 0x0a,
+	0x0a, 0x70, 0x61, 0x72, 0x74, 0x69, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x18, 0x04, 0x20, 0x01, 0x28,
+	0x0d, 0x52, 0x0a, 0x70, 0x61, 0x72, 0x74, 0x69, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x12, 0x3b, 0x0a,
+	0x0b, 0x64, 0x65, 0x66, 0x69, 0x6e, 0x69, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x18, 0x05, 0x20, 0x03,
+	0x28, 0x0b, 0x32, 0x19, 0x2e, 0x4d, 0x79, 0x53, 0x51, 0x4c, 0x50, 0x61, 0x72, 0x74, 0x69, 0x74,
+	0x69, 0x6f, 0x6e, 0x44, 0x65, 0x66, 0x69, 0x6e, 0x69, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x0b, 0x64,
+	0x65, 0x66, 0x69, 0x6e, 0x69, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x22, 0x2e, 0x0a, 0x04, 0x54, 0x79,
+	0x70, 0x65, 0x12, 0x09, 0x0a, 0x05, 0x52, 0x41, 0x4e, 0x47, 0x45, 0x10, 0x00, 0x12, 0x08, 0x0a,
+	0x04, 0x4c, 0x49, 0x53, 0x54, 0x10, 0x01, 0x12, 0x08, 0x0a, 0x04, 0x48, 0x41, 0x53, 0x48, 0x10,
+	0x02, 0x12, 0x07, 0x0a, 0x03, 0x4b, 0x45, 0x59, 0x10, 0x03, 0x22, 0x65, 0x0a, 0x0f, 0x4d, 0x79,
+	0x53, 0x51, 0x4c, 0x43, 0x6c, 0x69, 0x63, 0x6b, 0x48, 0x6f, 0x75, 0x73, 0x65, 0x12, 0x16, 0x0a,
+	0x06, 0x65, 0x6e, 0x67, 0x69, 0x6e, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x65,
+	0x6e, 0x67, 0x69, 0x6e, 0x65, 0x12, 0x18, 0x0a, 0x07, 0x6f, 0x72, 0x64, 0x65, 0x72, 0x42, 0x79,
+	0x18, 0x02, 0x20, 0x03, 0x28, 0x09, 0x52, 0x07, 0x6f, 0x72, 0x64, 0x65, 0x72, 0x42, 0x79, 0x12,
+	0x20, 0x0a, 0x0b, 0x70, 0x61, 0x72, 0x74, 0x69, 0x74, 0x69, 0x6f, 0x6e, 0x42, 0x79, 0x18, 0x03,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x0b, 0x70, 0x61, 0x72, 0x74, 0x69, 0x74, 0x69, 0x6f, 0x6e, 0x42,
+	0x79, 0x22, 0xc5, 0x01, 0x0a, 0x0f, 0x4d, 0x79, 0x53, 0x51, 0x4c, 0x46, 0x6f, 0x72, 0x65, 0x69,
+	0x67, 0x6e, 0x4b, 0x65, 0x79, 0x12, 0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x12, 0x18, 0x0a, 0x07, 0x63, 0x6f, 0x6c,
+	0x75, 0x6d, 0x6e, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x09, 0x52, 0x07, 0x63, 0x6f, 0x6c, 0x75,
+	0x6d, 0x6e, 0x73, 0x12, 0x1e, 0x0a, 0x0a, 0x72, 0x65, 0x66, 0x65, 0x72, 0x65, 0x6e, 0x63, 0x65,
+	0x73, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0a, 0x72, 0x65, 0x66, 0x65, 0x72, 0x65, 0x6e,
+	0x63, 0x65, 0x73, 0x12, 0x2c, 0x0a, 0x11, 0x72, 0x65, 0x66, 0x65, 0x72, 0x65, 0x6e, 0x63, 0x65,
+	0x64, 0x43, 0x6f, 0x6c, 0x75, 0x6d, 0x6e, 0x73, 0x18, 0x04, 0x20, 0x03, 0x28, 0x09, 0x52, 0x11,
+	0x72, 0x65, 0x66, 0x65, 0x72, 0x65, 0x6e, 0x63, 0x65, 0x64, 0x43, 0x6f, 0x6c, 0x75, 0x6d, 0x6e,
+	0x73, 0x12, 0x1a, 0x0a, 0x08, 0x6f, 0x6e, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x18, 0x05, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x08, 0x6f, 0x6e, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x12, 0x1a, 0x0a,
+	0x08, 0x6f, 0x6e, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x18, 0x06, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x08, 0x6f, 0x6e, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x22, 0xf8, 0x02, 0x0a, 0x0a, 0x4d, 0x79,
+	0x53, 0x51, 0x4c, 0x54, 0x61, 0x62, 0x6c, 0x65, 0x12, 0x36, 0x0a, 0x0c, 0x61, 0x75, 0x64, 0x69,
+	0x74, 0x43, 0x6f, 0x6c, 0x75, 0x6d, 0x6e, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x12,
+	0x2e, 0x4d, 0x79, 0x53, 0x51, 0x4c, 0x41, 0x75, 0x64, 0x69, 0x74, 0x43, 0x6f, 0x6c, 0x75, 0x6d,
+	0x6e, 0x73, 0x52, 0x0c, 0x61, 0x75, 0x64, 0x69, 0x74, 0x43, 0x6f, 0x6c, 0x75, 0x6d, 0x6e, 0x73,
+	0x12, 0x30, 0x0a, 0x0a, 0x73, 0x6f, 0x66, 0x74, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x18, 0x02,
+	0x20, 0x01, 0x28, 0x0b, 0x32, 0x10, 0x2e, 0x4d, 0x79, 0x53, 0x51, 0x4c, 0x53, 0x6f, 0x66, 0x74,
+	0x44, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x52, 0x0a, 0x73, 0x6f, 0x66, 0x74, 0x44, 0x65, 0x6c, 0x65,
+	0x74, 0x65, 0x12, 0x1e, 0x0a, 0x0a, 0x70, 0x72, 0x69, 0x6d, 0x61, 0x72, 0x79, 0x4b, 0x65, 0x79,
+	0x18, 0x03, 0x20, 0x03, 0x28, 0x09, 0x52, 0x0a, 0x70, 0x72, 0x69, 0x6d, 0x61, 0x72, 0x79, 0x4b,
+	0x65, 0x79, 0x12, 0x25, 0x0a, 0x07, 0x69, 0x6e, 0x64, 0x65, 0x78, 0x65, 0x73, 0x18, 0x04, 0x20,
+	0x03, 0x28, 0x0b, 0x32, 0x0b, 0x2e, 0x4d, 0x79, 0x53, 0x51, 0x4c, 0x49, 0x6e, 0x64, 0x65, 0x78,
+	0x52, 0x07, 0x69, 0x6e, 0x64, 0x65, 0x78, 0x65, 0x73, 0x12, 0x2d, 0x0a, 0x09, 0x70, 0x61, 0x72,
+	0x74, 0x69, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x05, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0f, 0x2e, 0x4d,
+	0x79, 0x53, 0x51, 0x4c, 0x50, 0x61, 0x72, 0x74, 0x69, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x09, 0x70,
+	0x61, 0x72, 0x74, 0x69, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x30, 0x0a, 0x0a, 0x63, 0x6c, 0x69, 0x63,
+	0x6b, 0x48, 0x6f, 0x75, 0x73, 0x65, 0x18, 0x06, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x10, 0x2e, 0x4d,
+	0x79, 0x53, 0x51, 0x4c, 0x43, 0x6c, 0x69, 0x63, 0x6b, 0x48, 0x6f, 0x75, 0x73, 0x65, 0x52, 0x0a,
+	0x63, 0x6c, 0x69, 0x63, 0x6b, 0x48, 0x6f, 0x75, 0x73, 0x65, 0x12, 0x32, 0x0a, 0x0b, 0x66, 0x6f,
+	0x72, 0x65, 0x69, 0x67, 0x6e, 0x4b, 0x65, 0x79, 0x73, 0x18, 0x07, 0x20, 0x03, 0x28, 0x0b, 0x32,
+	0x10, 0x2e, 0x4d, 0x79, 0x53, 0x51, 0x4c, 0x46, 0x6f, 0x72, 0x65, 0x69, 0x67, 0x6e, 0x4b, 0x65,
+	0x79, 0x52, 0x0b, 0x66, 0x6f, 0x72, 0x65, 0x69, 0x67, 0x6e, 0x4b, 0x65, 0x79, 0x73, 0x12, 0x24,
+	0x0a, 0x0d, 0x70, 0x72, 0x65, 0x76, 0x69, 0x6f, 0x75, 0x73, 0x4e, 0x61, 0x6d, 0x65, 0x73, 0x18,
+	0x08, 0x20, 0x03, 0x28, 0x09, 0x52, 0x0d, 0x70, 0x72, 0x65, 0x76, 0x69, 0x6f, 0x75, 0x73, 0x4e,
+	0x61, 0x6d, 0x65, 0x73, 0x3a, 0x49, 0x0a, 0x09, 0x6d, 0x79, 0x53, 0x51, 0x4c, 0x54, 0x79, 0x70,
+	0x65, 0x12, 0x1d, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f,
+	0x62, 0x75, 0x66, 0x2e, 0x46, 0x69, 0x65, 0x6c, 0x64, 0x4f, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x73,
+	0x18, 0xd0, 0x86, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0a, 0x2e, 0x4d, 0x79, 0x53, 0x51, 0x4c,
+	0x54, 0x79, 0x70, 0x65, 0x52, 0x09, 0x6d, 0x79, 0x53, 0x51, 0x4c, 0x54, 0x79, 0x70, 0x65, 0x3a,
+	0x4f, 0x0a, 0x0b, 0x6d, 0x79, 0x53, 0x51, 0x4c, 0x43, 0x6f, 0x6c, 0x75, 0x6d, 0x6e, 0x12, 0x1d,
+	0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66,
+	0x2e, 0x46, 0x69, 0x65, 0x6c, 0x64, 0x4f, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x18, 0xd2, 0x86,
+	0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0c, 0x2e, 0x4d, 0x79, 0x53, 0x51, 0x4c, 0x43, 0x6f, 0x6c,
+	0x75, 0x6d, 0x6e, 0x52, 0x0b, 0x6d, 0x79, 0x53, 0x51, 0x4c, 0x43, 0x6f, 0x6c, 0x75, 0x6d, 0x6e,
+	0x3a, 0x4e, 0x0a, 0x0a, 0x6d, 0x79, 0x53, 0x51, 0x4c, 0x54, 0x61, 0x62, 0x6c, 0x65, 0x12, 0x1f,
+	0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66,
+	0x2e, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x4f, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x18,
+	0xd1, 0x86, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0b, 0x2e, 0x4d, 0x79, 0x53, 0x51, 0x4c, 0x54,
+	0x61, 0x62, 0x6c, 0x65, 0x52, 0x0a, 0x6d, 0x79, 0x53, 0x51, 0x4c, 0x54, 0x61, 0x62, 0x6c, 0x65,
+	0x42, 0x0a, 0x5a, 0x08, 0x2e, 0x2f, 0x67, 0x65, 0x6e, 0x73, 0x71, 0x6c, 0x62, 0x06, 0x70, 0x72,
+	0x6f, 0x74, 0x6f, 0x33,
 }
 
 var (
@@ -124,19 +1046,45 @@ func file_mySQLOptions_proto_rawDescGZIP() []byte {
 	return file_mySQLOptions_proto_rawDescData
 }
 
-var file_mySQLOptions_proto_msgTypes = make([]protoimpl.MessageInfo, 1)
+var file_mySQLOptions_proto_enumTypes = make([]protoimpl.EnumInfo, 2)
+var file_mySQLOptions_proto_msgTypes = make([]protoimpl.MessageInfo, 10)
 var file_mySQLOptions_proto_goTypes = []interface{}{
-	(*MySQLType)(nil),                 // 0: MySQLType
-	(*descriptorpb.FieldOptions)(nil), // 1: google.protobuf.FieldOptions
+	(MySQLColumn_Semantic)(0),           // 0: MySQLColumn.Semantic
+	(MySQLPartition_Type)(0),            // 1: MySQLPartition.Type
+	(*MySQLType)(nil),                   // 2: MySQLType
+	(*MySQLColumn)(nil),                 // 3: MySQLColumn
+	(*MySQLAuditColumns)(nil),           // 4: MySQLAuditColumns
+	(*MySQLSoftDelete)(nil),             // 5: MySQLSoftDelete
+	(*MySQLIndex)(nil),                  // 6: MySQLIndex
+	(*MySQLPartitionDefinition)(nil),    // 7: MySQLPartitionDefinition
+	(*MySQLPartition)(nil),              // 8: MySQLPartition
+	(*MySQLClickHouse)(nil),             // 9: MySQLClickHouse
+	(*MySQLForeignKey)(nil),             // 10: MySQLForeignKey
+	(*MySQLTable)(nil),                  // 11: MySQLTable
+	(*descriptorpb.FieldOptions)(nil),   // 12: google.protobuf.FieldOptions
+	(*descriptorpb.MessageOptions)(nil), // 13: google.protobuf.MessageOptions
 }
 var file_mySQLOptions_proto_depIdxs = []int32{
-	1, // 0: mySQLType:extendee -> google.protobuf.FieldOptions
-	0, // 1: mySQLType:type_name -> MySQLType
-	2, // [2:2] is the sub-list for method output_type
-	2, // [2:2] is the sub-list for method input_type
-	1, // [1:2] is the sub-list for extension type_name
-	0, // [0:1] is the sub-list for extension extendee
-	0, // [0:0] is the sub-list for field type_name
+	0,  // 0: MySQLColumn.semantic:type_name -> MySQLColumn.Semantic
+	1,  // 1: MySQLPartition.type:type_name -> MySQLPartition.Type
+	7,  // 2: MySQLPartition.definitions:type_name -> MySQLPartitionDefinition
+	4,  // 3: MySQLTable.auditColumns:type_name -> MySQLAuditColumns
+	5,  // 4: MySQLTable.softDelete:type_name -> MySQLSoftDelete
+	6,  // 5: MySQLTable.indexes:type_name -> MySQLIndex
+	8,  // 6: MySQLTable.partition:type_name -> MySQLPartition
+	9,  // 7: MySQLTable.clickHouse:type_name -> MySQLClickHouse
+	10, // 8: MySQLTable.foreignKeys:type_name -> MySQLForeignKey
+	12, // 9: mySQLType:extendee -> google.protobuf.FieldOptions
+	12, // 10: mySQLColumn:extendee -> google.protobuf.FieldOptions
+	13, // 11: mySQLTable:extendee -> google.protobuf.MessageOptions
+	2,  // 12: mySQLType:type_name -> MySQLType
+	3,  // 13: mySQLColumn:type_name -> MySQLColumn
+	11, // 14: mySQLTable:type_name -> MySQLTable
+	15, // [15:15] is the sub-list for method output_type
+	15, // [15:15] is the sub-list for method input_type
+	12, // [12:15] is the sub-list for extension type_name
+	9,  // [9:12] is the sub-list for extension extendee
+	0,  // [0:9] is the sub-list for field type_name
 }
 
 func init() { file_mySQLOptions_proto_init() }
@@ -157,19 +1105,128 @@ func file_mySQLOptions_proto_init() {
 				return nil
 			}
 		}
+		file_mySQLOptions_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*MySQLColumn); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_mySQLOptions_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*MySQLAuditColumns); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_mySQLOptions_proto_msgTypes[3].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*MySQLSoftDelete); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_mySQLOptions_proto_msgTypes[4].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*MySQLIndex); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_mySQLOptions_proto_msgTypes[5].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*MySQLPartitionDefinition); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_mySQLOptions_proto_msgTypes[6].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*MySQLPartition); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_mySQLOptions_proto_msgTypes[7].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*MySQLClickHouse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_mySQLOptions_proto_msgTypes[8].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*MySQLForeignKey); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_mySQLOptions_proto_msgTypes[9].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*MySQLTable); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
 	}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: file_mySQLOptions_proto_rawDesc,
-			NumEnums:      0,
-			NumMessages:   1,
-			NumExtensions: 1,
+			NumEnums:      2,
+			NumMessages:   10,
+			NumExtensions: 3,
 			NumServices:   0,
 		},
 		GoTypes:           file_mySQLOptions_proto_goTypes,
 		DependencyIndexes: file_mySQLOptions_proto_depIdxs,
+		EnumInfos:         file_mySQLOptions_proto_enumTypes,
 		MessageInfos:      file_mySQLOptions_proto_msgTypes,
 		ExtensionInfos:    file_mySQLOptions_proto_extTypes,
 	}.Build()
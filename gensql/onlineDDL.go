@@ -0,0 +1,250 @@
+package gensql
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+)
+
+type Algorithm string
+
+const (
+	// metadata only
+	AlgorithmInstant Algorithm = "INSTANT"
+	// without copying the table, but possibly rebuilding it
+	AlgorithmInplace Algorithm = "INPLACE"
+	AlgorithmCopy    Algorithm = "COPY"
+)
+
+type Lock string
+
+const (
+	// ALGORITHM=INSTANT takes no LOCK clause
+	LockDefault Lock = ""
+	// concurrent reads and writes are allowed
+	LockNone Lock = "NONE"
+	// concurrent reads are allowed
+	LockShared Lock = "SHARED"
+)
+
+var algorithmRanks = map[Algorithm]int{AlgorithmInstant: 0, AlgorithmInplace: 1, AlgorithmCopy: 2}
+var lockRanks = map[Lock]int{LockDefault: 0, LockNone: 1, LockShared: 2}
+
+// ALGORITHM and LOCK clauses of ALTER TABLE
+type OnlineDDL struct {
+	Algorithm Algorithm
+	Lock      Lock
+}
+
+var (
+	instantDDL = OnlineDDL{AlgorithmInstant, LockDefault}
+	inplaceDDL = OnlineDDL{AlgorithmInplace, LockNone}
+	// INPLACE which blocks writes
+	inplaceSharedDDL = OnlineDDL{AlgorithmInplace, LockShared}
+	copyDDL          = OnlineDDL{AlgorithmCopy, LockShared}
+)
+
+// the strongest clauses valid for both
+func (d OnlineDDL) and(o OnlineDDL) OnlineDDL {
+	ret := d
+	if algorithmRanks[o.Algorithm] > algorithmRanks[ret.Algorithm] {
+		ret.Algorithm = o.Algorithm
+	}
+	if lockRanks[o.Lock] > lockRanks[ret.Lock] {
+		ret.Lock = o.Lock
+	}
+	return ret
+}
+
+// e.g. "ALGORITHM=INPLACE, LOCK=NONE"
+func (d OnlineDDL) clause() string {
+	if d.Lock == LockDefault {
+		return "ALGORITHM=" + string(d.Algorithm)
+	}
+	return "ALGORITHM=" + string(d.Algorithm) + ", LOCK=" + string(d.Lock)
+}
+
+// ALGORITHM and LOCK valid for all changes of the group. nil unless they are annotated
+func (g changeGroup) onlineDDL() *OnlineDDL {
+	var ret *OnlineDDL
+	for _, c := range g {
+		if c.OnlineDDL == nil {
+			continue
+		}
+		if ret == nil {
+			d := *c.OnlineDDL
+			ret = &d
+		} else {
+			*ret = ret.and(*c.OnlineDDL)
+		}
+	}
+	return ret
+}
+
+// INSTANT if the target version supports the feature, and INPLACE otherwise
+func (o Options) instantOrInplace(feature mySQLFeature) OnlineDDL {
+	if o.supports(feature) {
+		return instantDDL
+	}
+	return inplaceDDL
+}
+
+// VARCHAR keeps the length bytes while the maximum bytes stay within 255 or beyond, assuming utf8mb4
+func varcharExtendsInPlace(o, n MySQLDataTypeWithArgs) bool {
+	if o.GetType() != VARCHAR || n.GetType() != VARCHAR || len(o.GetArgs()) != 1 || len(n.GetArgs()) != 1 {
+		return false
+	}
+	ol, err1 := strconv.Atoi(o.GetArgs()[0])
+	nl, err2 := strconv.Atoi(n.GetArgs()[0])
+	if err1 != nil || err2 != nil || nl < ol {
+		return false
+	}
+	return (ol*4 <= 255) == (nl*4 <= 255)
+}
+
+func enumAppendedInPlace(o, n *Column) bool {
+	if o.Type.GetType() != ENUM || n.Type.GetType() != ENUM || len(n.Type.GetArgs()) > 255 {
+		return false
+	}
+	oldValues, newValues := o.Type.GetArgs(), n.Type.GetArgs()
+	if len(oldValues) > len(newValues) {
+		return false
+	}
+	for i, v := range oldValues {
+		if newValues[i] != v {
+			return false
+		}
+	}
+	return true
+}
+
+// the strongest ALGORITHM and LOCK of MODIFY COLUMN from o to n
+func modifyColumnOnlineDDL(o, n *Column, opts Options) OnlineDDL {
+	// compare the definitions without attributes changed in place
+	strip := func(c *Column) string {
+		ret := *c
+		ret.Name, ret.Type, ret.Default, ret.Invisible, ret.Nullable = n.Name, n.Type, "", false, false
+		return genColumnDefinition(&ret, nil)
+	}
+	if strip(o) != strip(n) {
+		return copyDDL
+	}
+	ret := instantDDL
+	switch {
+	case o.Type.ToString() == n.Type.ToString():
+		ret = opts.instantOrInplace(featureInstantDDL)
+	case enumAppendedInPlace(o, n):
+		ret = opts.instantOrInplace(featureInstantDDL)
+	case varcharExtendsInPlace(o.Type, n.Type):
+		ret = inplaceDDL
+	default:
+		return copyDDL
+	}
+	if o.Nullable != n.Nullable {
+		// rebuilds the table
+		ret = ret.and(inplaceDDL)
+	}
+	return ret
+}
+
+// the strongest ALGORITHM and LOCK of c, which is a clause of ALTER TABLE.
+// others are the changes of the same table
+func changeOnlineDDL(c *Change, others []*Change, opts Options) OnlineDDL {
+	switch c.Kind {
+	case ChangeAddColumn:
+		switch {
+		case c.NewColumn.AutoIncrement:
+			return inplaceSharedDDL
+		case c.NewColumn.Generated != "":
+			return opts.instantOrInplace(featureInstantDDL)
+		default:
+			// columns are added with AFTER, which is instant since 8.0.29
+			return opts.instantOrInplace(featureInstantColumnPosition)
+		}
+	case ChangeDropColumn:
+		if c.OldColumn.Generated != "" {
+			return opts.instantOrInplace(featureInstantDDL)
+		}
+		return opts.instantOrInplace(featureInstantColumnPosition)
+	case ChangeRenameColumn:
+		return opts.instantOrInplace(featureInstantRenameColumn)
+	case ChangeChangeColumn:
+		return opts.instantOrInplace(featureInstantRenameColumn).and(modifyColumnOnlineDDL(c.OldColumn, c.NewColumn, opts))
+	case ChangeModifyColumn:
+		return modifyColumnOnlineDDL(c.OldColumn, c.NewColumn, opts)
+	case ChangeAddIndex:
+		// functional key parts add hidden generated columns
+		if strings.Contains(c.SQL, "((") {
+			return inplaceSharedDDL
+		}
+		return inplaceDDL
+	case ChangeDropIndex, ChangeDropForeignKey, ChangeAddPrimaryKey:
+		return inplaceDDL
+	case ChangeDropPrimaryKey:
+		// only replacing the primary key is done in place
+		for _, o := range others {
+			if o.Kind == ChangeAddPrimaryKey {
+				return inplaceDDL
+			}
+		}
+		return copyDDL
+	case ChangeDropCheck:
+		return inplaceDDL
+	default:
+		// foreign keys are validated by copying the table while foreign_key_checks is enabled.
+		// checks and table options are also copied
+		return copyDDL
+	}
+}
+
+// annotate clauses of ALTER TABLE with ALGORITHM and LOCK
+func annotateOnlineDDL(changes []*Change, opts Options) {
+	for _, g := range groupChanges(changes) {
+		if !g.isAlter() {
+			continue
+		}
+		for _, c := range g {
+			d := changeOnlineDDL(c, g, opts)
+			c.OnlineDDL = &d
+		}
+	}
+}
+
+// step of online schema change tools such as gh-ost and pt-online-schema-change
+type OnlineSchemaChange struct {
+	Database string `json:"database,omitempty"`
+	Table    string `json:"table,omitempty"`
+	// ALTER TABLE fragment for --alter. e.g. "ADD COLUMN locale TEXT NOT NULL AFTER page_number"
+	Alter string `json:"alter,omitempty"`
+	// statement run as it is, such as CREATE TABLE and UPDATE. empty for Alter
+	SQL      string   `json:"sql,omitempty"`
+	Warnings []string `json:"warnings,omitempty"`
+}
+
+// steps of the changes. the clauses of each ALTER TABLE are joined into an alter fragment
+func GenOnlineSchemaChanges(changes []*Change) []OnlineSchemaChange {
+	ret := []OnlineSchemaChange{}
+	for _, g := range groupChanges(changes) {
+		step := OnlineSchemaChange{}
+		for _, c := range g {
+			step.Warnings = append(step.Warnings, c.Warnings...)
+		}
+		if g[0].isStatement() {
+			step.SQL = g[0].SQL
+		} else {
+			step.Database = g[0].Table.Database
+			step.Table = g[0].Table.Name
+			step.Alter = strings.Join(g.clauses(), ", ")
+		}
+		ret = append(ret, step)
+	}
+	return ret
+}
+
+func GenOnlineSchemaChangesJSON(changes []*Change) (string, error) {
+	buf, err := json.MarshalIndent(GenOnlineSchemaChanges(changes), "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return string(buf) + "\n", nil
+}
@@ -0,0 +1,139 @@
+package gensql
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestAlterAlgorithm(t *testing.T) {
+	const (
+		age      = `field { name: "age" number: 1 type: TYPE_INT32 label: LABEL_OPTIONAL }`
+		gender   = `field { name: "gender" number: 2 type: TYPE_ENUM type_name: ".Foo.Gender" label: LABEL_OPTIONAL }`
+		nickname = `field { name: "nickname" number: 3 type: TYPE_STRING label: LABEL_OPTIONAL options { [mySQLType] { typeName: "VARCHAR" args: ["16"] } } }`
+	)
+	user := func(fields, options string) string {
+		return `message_type { name: "User" ` + fields + ` options { [mySQLTable] { ` + options + ` } } }`
+	}
+	tests := []struct {
+		name    string
+		old     string
+		new     string
+		version string
+		sql     string
+	}{
+		{
+			name:    "add column on 8.0.29",
+			old:     user(age, ""),
+			new:     user(age+` field { name: "locale" number: 4 type: TYPE_STRING label: LABEL_OPTIONAL }`, ""),
+			version: "8.0.29",
+			sql:     "ALTER TABLE User\n\tADD COLUMN locale TEXT NOT NULL AFTER age,\n\tALGORITHM=INSTANT;",
+		},
+		{
+			name:    "add column on 8.0.28",
+			old:     user(age, ""),
+			new:     user(age+` field { name: "locale" number: 4 type: TYPE_STRING label: LABEL_OPTIONAL }`, ""),
+			version: "8.0.28",
+			sql:     "ALTER TABLE User\n\tADD COLUMN locale TEXT NOT NULL AFTER age,\n\tALGORITHM=INPLACE, LOCK=NONE;",
+		},
+		{
+			name:    "rename column on 8.0",
+			old:     user(age, ""),
+			new:     user(`field { name: "years" number: 1 type: TYPE_INT32 label: LABEL_OPTIONAL }`, ""),
+			version: "8.0",
+			sql:     "ALTER TABLE User\n\tRENAME COLUMN age TO years,\n\tALGORITHM=INSTANT;",
+		},
+		{
+			name:    "rename column on 5.7",
+			old:     user(age, ""),
+			new:     user(`field { name: "years" number: 1 type: TYPE_INT32 label: LABEL_OPTIONAL }`, ""),
+			version: "5.7",
+			sql:     "ALTER TABLE User\n\tCHANGE COLUMN age years INT NOT NULL,\n\tALGORITHM=INPLACE, LOCK=NONE;",
+		},
+		{
+			name:    "widen column",
+			old:     user(age, ""),
+			new:     user(`field { name: "age" number: 1 type: TYPE_INT64 label: LABEL_OPTIONAL }`, ""),
+			version: "8.0",
+			sql:     "ALTER TABLE User\n\tMODIFY COLUMN age BIGINT NOT NULL,\n\tALGORITHM=COPY, LOCK=SHARED;",
+		},
+		{
+			name:    "extend VARCHAR",
+			old:     user(nickname, ""),
+			new:     user(`field { name: "nickname" number: 3 type: TYPE_STRING label: LABEL_OPTIONAL options { [mySQLType] { typeName: "VARCHAR" args: ["32"] } } }`, ""),
+			version: "8.0",
+			sql:     "ALTER TABLE User\n\tMODIFY COLUMN nickname VARCHAR(32) NOT NULL,\n\tALGORITHM=INPLACE, LOCK=NONE;",
+		},
+		{
+			name:    "extend VARCHAR beyond 255 bytes",
+			old:     user(nickname, ""),
+			new:     user(`field { name: "nickname" number: 3 type: TYPE_STRING label: LABEL_OPTIONAL options { [mySQLType] { typeName: "VARCHAR" args: ["64"] } } }`, ""),
+			version: "8.0",
+			sql:     "ALTER TABLE User\n\tMODIFY COLUMN nickname VARCHAR(64) NOT NULL,\n\tALGORITHM=COPY, LOCK=SHARED;",
+		},
+		{
+			name:    "add index and column together",
+			old:     user(age, ""),
+			new:     user(age+` field { name: "locale" number: 4 type: TYPE_STRING label: LABEL_OPTIONAL options { [mySQLType] { typeName: "VARCHAR" args: ["8"] } } }`, `indexes { name: "age_idx" columns: ["age"] }`),
+			version: "8.0.29",
+			sql:     "ALTER TABLE User\n\tADD COLUMN locale VARCHAR(8) NOT NULL AFTER age,\n\tADD INDEX age_idx (age),\n\tALGORITHM=INPLACE, LOCK=NONE;",
+		},
+		{
+			name:    "replace primary key",
+			old:     user(age+gender, `primaryKey: ["age"]`),
+			new:     user(age+gender, `primaryKey: ["age", "gender"]`),
+			version: "8.0",
+			sql:     "ALTER TABLE User\n\tDROP PRIMARY KEY,\n\tADD PRIMARY KEY (age,gender),\n\tALGORITHM=INPLACE, LOCK=NONE;",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v, err := ParseMySQLVersion(tt.version)
+			if err != nil {
+				t.Fatal(err)
+			}
+			changes := DiffSchema(buildSchema(t, tt.old), buildSchema(t, tt.new), Options{AlterAlgorithm: true, MySQLVersion: v})
+			if sql := GenMigrationSQL(changes); sql != tt.sql {
+				t.Errorf("sql = \n%s\nwant\n%s", sql, tt.sql)
+			}
+		})
+	}
+}
+
+func TestGenOnlineSchemaChanges(t *testing.T) {
+	const age = `field { name: "age" number: 1 type: TYPE_INT32 label: LABEL_OPTIONAL }`
+	tests := []struct {
+		name  string
+		old   string
+		new   string
+		steps []OnlineSchemaChange
+	}{
+		{
+			name:  "alter without ALGORITHM",
+			old:   `message_type { name: "User" ` + age + ` options { [mySQLTable] {} } }`,
+			new:   `message_type { name: "User" ` + age + ` field { name: "locale" number: 2 type: TYPE_STRING label: LABEL_OPTIONAL } options { [mySQLTable] {} } }`,
+			steps: []OnlineSchemaChange{{Table: "User", Alter: "ADD COLUMN locale TEXT NOT NULL AFTER age"}},
+		},
+		{
+			name: "create and drop tables",
+			old:  `message_type { name: "User" ` + age + ` options { [mySQLTable] {} } }`,
+			new:  `message_type { name: "Item" ` + age + ` options { [mySQLTable] {} } }`,
+			steps: []OnlineSchemaChange{
+				{SQL: "CREATE TABLE Item (\n\tage INT NOT NULL,\n\tPROTO_BINARY BLOB NOT NULL\n);"},
+				{SQL: "DROP TABLE User;"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v, err := ParseMySQLVersion("8.0.29")
+			if err != nil {
+				t.Fatal(err)
+			}
+			// the tools choose how to alter the table, so ALGORITHM is not in the fragment
+			steps := GenOnlineSchemaChanges(DiffSchema(buildSchema(t, tt.old), buildSchema(t, tt.new), Options{AlterAlgorithm: true, MySQLVersion: v}))
+			if !reflect.DeepEqual(steps, tt.steps) {
+				t.Errorf("steps = %#v, want %#v", steps, tt.steps)
+			}
+		})
+	}
+}
@@ -0,0 +1,51 @@
+package gensql
+
+// what migrations do with columns of deleted and reserved fields
+type ReservedPolicy string
+
+const (
+	ReservedDrop ReservedPolicy = "drop"
+	// keep the column as nullable, so that rows can still be inserted without it
+	ReservedKeep ReservedPolicy = "keep"
+)
+
+// generator options given by protoc parameter
+type Options struct {
+	PackageMapping PackageMapping
+	Dialect        Dialect
+	// zero value targets no specific version
+	MySQLVersion MySQLVersion
+	// also write BigQuery schema JSON of each table
+	BigQuerySchema bool
+	// also write manifest JSON which maps columns to proto fields
+	Manifest bool
+	// path of FileDescriptorSet (protoc -o) of the previous version.
+	// ALTER statements from it are written to <file>.proto.migration.sql
+	Baseline string
+	// fail when changes from Baseline corrupt or reject stored rows
+	CheckBreaking bool
+	// empty is same as ReservedDrop
+	ReservedPolicy ReservedPolicy
+	// CREATE TABLE IF NOT EXISTS, so that the SQL can be run again
+	IfNotExists bool
+	// drop tables and views before creating them
+	DropTables bool
+	// disable foreign key checks while the SQL runs
+	ForeignKeyChecksGuard bool
+	// write the migration from Baseline as numbered up/down files. empty if not
+	MigrationFormat MigrationFormat
+	// directory of the numbered files relative to OutDir, which is scanned for the next version
+	MigrationDir string
+	// output directory given to protoc. the working directory if empty
+	OutDir string
+	// description in file names. "schema" if empty
+	MigrationName string
+	// append the strongest ALGORITHM and LOCK valid on MySQLVersion to each ALTER TABLE
+	AlterAlgorithm bool
+	// also write the migration as alter fragments for gh-ost and pt-online-schema-change
+	OnlineSchemaChange bool
+	// record the fingerprint of each message in FingerprintTable
+	Fingerprint bool
+	// names of helpers to generate. python if empty
+	Helpers []string
+}
@@ -0,0 +1,135 @@
+package gensql
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/golang/protobuf/protoc-gen-go/descriptor"
+)
+
+// MySQL requires every unique key to include all columns of the partitioning expression
+func checkPartitionColumn(mt *descriptor.DescriptorProto, t *MySQLTable) error {
+	p := t.GetPartition()
+	column := p.GetColumn()
+
+	found := false
+	for _, field := range mt.Field {
+		if field.GetName() == column {
+			found = true
+		}
+	}
+	if !found {
+		return fmt.Errorf("partition column %s is not a field of message %s", column, mt.GetName())
+	}
+
+	contains := func(columns []string) bool {
+		for _, c := range columns {
+			if c == column {
+				return true
+			}
+		}
+		return false
+	}
+	if len(t.GetPrimaryKey()) > 0 && !contains(t.GetPrimaryKey()) {
+		return fmt.Errorf("partition column %s must be part of the primary key of %s", column, mt.GetName())
+	}
+	for _, index := range t.GetIndexes() {
+		if index.GetUnique() && !contains(index.GetColumns()) {
+			return fmt.Errorf("partition column %s must be part of unique index %s of %s", column, index.GetName(), mt.GetName())
+		}
+	}
+	return nil
+}
+
+// column types MySQL accepts for RANGE COLUMNS and LIST COLUMNS besides integers
+var partitionColumnsTypes = map[string]bool{"DATE": true, "DATETIME": true, "CHAR": true, "VARCHAR": true, "BINARY": true, "VARBINARY": true}
+
+func isIntegerType(name string) bool {
+	switch strings.TrimSuffix(name, " UNSIGNED") {
+	case "TINYINT", "SMALLINT", "MEDIUMINT", "INT", "INTEGER", "BIGINT", "BOOL", "BOOLEAN":
+		return true
+	}
+	return false
+}
+
+// HASH, RANGE and LIST partition by an integer, and their COLUMNS variants also by dates and strings.
+// KEY accepts any type but TEXT, BLOB, JSON and spatial types. kind is the partitioning of p, e.g. "RANGE COLUMNS"
+func checkPartitionColumnType(p *MySQLPartition, kind string, c *Column) error {
+	name := strings.ToUpper(string(c.Type.GetType()))
+	ok := isIntegerType(name)
+	switch {
+	case p.GetType() == MySQLPartition_KEY:
+		ok = !strings.HasSuffix(name, "TEXT") && !strings.HasSuffix(name, "BLOB") && name != string(JSON) && name != "GEOMETRY" && name != "POINT"
+	case p.GetColumns():
+		ok = ok || partitionColumnsTypes[name]
+	}
+	if !ok {
+		return fmt.Errorf("partition column %s of type %s cannot be used for %s partitioning", c.Name, name, kind)
+	}
+	return nil
+}
+
+func genPartitionDefinition(pType MySQLPartition_Type, def *MySQLPartitionDefinition) (string, error) {
+	if def.GetName() == "" {
+		return "", fmt.Errorf("partition name is empty")
+	}
+	switch pType {
+	case MySQLPartition_RANGE:
+		if def.GetValues() == "MAXVALUE" {
+			return fmt.Sprintf("PARTITION %s VALUES LESS THAN MAXVALUE", def.GetName()), nil
+		}
+		return fmt.Sprintf("PARTITION %s VALUES LESS THAN (%s)", def.GetName(), def.GetValues()), nil
+	case MySQLPartition_LIST:
+		return fmt.Sprintf("PARTITION %s VALUES IN (%s)", def.GetName(), def.GetValues()), nil
+	default:
+		return fmt.Sprintf("PARTITION %s", def.GetName()), nil
+	}
+}
+
+// return partition options following the closing parenthesis of CREATE TABLE.
+// e.g. "PARTITION BY HASH (tenant_id) PARTITIONS 4"
+func genPartitionOptions(mt *descriptor.DescriptorProto, t *MySQLTable, columns []*Column) (string, error) {
+	p := t.GetPartition()
+	if err := checkPartitionColumn(mt, t); err != nil {
+		return "", err
+	}
+
+	kind := p.GetType().String()
+	if p.GetColumns() {
+		switch p.GetType() {
+		case MySQLPartition_RANGE, MySQLPartition_LIST:
+			kind += " COLUMNS"
+		default:
+			return "", fmt.Errorf("COLUMNS is not allowed for %s partitioning", kind)
+		}
+	}
+	for _, c := range columns {
+		if c.Name == p.GetColumn() {
+			if err := checkPartitionColumnType(p, kind, c); err != nil {
+				return "", err
+			}
+		}
+	}
+
+	ret := fmt.Sprintf("PARTITION BY %s (%s)", kind, p.GetColumn())
+	if p.GetPartitions() > 0 {
+		ret += fmt.Sprintf(" PARTITIONS %d", p.GetPartitions())
+	}
+
+	if len(p.GetDefinitions()) == 0 {
+		switch p.GetType() {
+		case MySQLPartition_RANGE, MySQLPartition_LIST:
+			return "", fmt.Errorf("%s partitioning requires partition definitions", kind)
+		}
+		return ret, nil
+	}
+	defs := make([]string, 0, len(p.GetDefinitions()))
+	for _, def := range p.GetDefinitions() {
+		d, err := genPartitionDefinition(p.GetType(), def)
+		if err != nil {
+			return "", err
+		}
+		defs = append(defs, "\t"+d)
+	}
+	return fmt.Sprintf("%s (\n%s\n)", ret, strings.Join(defs, ",\n")), nil
+}
@@ -0,0 +1,109 @@
+package gensql
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestBuildSchemaPartition(t *testing.T) {
+	tests := []struct {
+		name      string
+		options   string
+		partition string
+		err       string
+	}{
+		{
+			name:      "range",
+			options:   `primaryKey: ["id", "tenant_id"] partition { type: RANGE column: "tenant_id" definitions { name: "p0" values: "100" } definitions { name: "pmax" values: "MAXVALUE" } }`,
+			partition: "PARTITION BY RANGE (tenant_id) (\n\tPARTITION p0 VALUES LESS THAN (100),\n\tPARTITION pmax VALUES LESS THAN MAXVALUE\n)",
+		},
+		{
+			name:      "hash",
+			options:   `partition { type: HASH column: "tenant_id" partitions: 4 }`,
+			partition: "PARTITION BY HASH (tenant_id) PARTITIONS 4",
+		},
+		{
+			name:    "unknown column",
+			options: `partition { type: HASH column: "region" partitions: 4 }`,
+			err:     "partition column region is not a field of message Event",
+		},
+		{
+			name:    "column missing in primary key",
+			options: `primaryKey: ["id"] partition { type: HASH column: "tenant_id" partitions: 4 }`,
+			err:     "partition column tenant_id must be part of the primary key of Event",
+		},
+		{
+			name:    "column missing in unique index",
+			options: `indexes { name: "id_uniq" columns: ["id"] unique: true } partition { type: HASH column: "tenant_id" partitions: 4 }`,
+			err:     "partition column tenant_id must be part of unique index id_uniq of Event",
+		},
+		{
+			name:    "range without definitions",
+			options: `partition { type: RANGE column: "tenant_id" }`,
+			err:     "RANGE partitioning requires partition definitions",
+		},
+		{
+			name:    "unknown key column",
+			options: `primaryKey: ["id", "region"]`,
+			err:     "column region is not found in message Event",
+		},
+		{
+			name:    "HASH by message",
+			options: `partition { type: HASH column: "profile" partitions: 4 }`,
+			err:     "partition column profile of type JSON cannot be used for HASH partitioning",
+		},
+		{
+			name:    "RANGE by string",
+			options: `partition { type: RANGE column: "name" definitions { name: "pmax" values: "MAXVALUE" } }`,
+			err:     "partition column name of type TEXT cannot be used for RANGE partitioning",
+		},
+		{
+			name:    "LIST COLUMNS by TEXT",
+			options: `partition { type: LIST columns: true column: "name" definitions { name: "p0" values: "'a'" } }`,
+			err:     "partition column name of type TEXT cannot be used for LIST COLUMNS partitioning",
+		},
+		{
+			name:      "RANGE COLUMNS by VARCHAR",
+			options:   `partition { type: RANGE columns: true column: "code" definitions { name: "p0" values: "'m'" } definitions { name: "pmax" values: "MAXVALUE" } }`,
+			partition: "PARTITION BY RANGE COLUMNS (code) (\n\tPARTITION p0 VALUES LESS THAN ('m'),\n\tPARTITION pmax VALUES LESS THAN MAXVALUE\n)",
+		},
+		{
+			name:      "KEY by VARCHAR",
+			options:   `partition { type: KEY column: "code" partitions: 4 }`,
+			partition: "PARTITION BY KEY (code) PARTITIONS 4",
+		},
+		{
+			name:    "KEY by TEXT",
+			options: `partition { type: KEY column: "name" partitions: 4 }`,
+			err:     "partition column name of type TEXT cannot be used for KEY partitioning",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dep, f := parseMessages(t, `
+				message_type {
+					name: "Event"
+					field { name: "id" number: 1 type: TYPE_INT64 label: LABEL_OPTIONAL }
+					field { name: "tenant_id" number: 2 type: TYPE_INT32 label: LABEL_OPTIONAL }
+					field { name: "name" number: 3 type: TYPE_STRING label: LABEL_OPTIONAL }
+					field { name: "code" number: 4 type: TYPE_STRING label: LABEL_OPTIONAL options { [mySQLType] { typeName: "VARCHAR" args: ["8"] } } }
+					field { name: "profile" number: 5 type: TYPE_MESSAGE type_name: ".Foo.Profile" label: LABEL_OPTIONAL }
+					options { [mySQLTable] { `+tt.options+` } }
+				}
+				message_type { name: "Profile" field { name: "bio" number: 1 type: TYPE_STRING label: LABEL_OPTIONAL } }`)
+			s, err := BuildSchema(dep, f, Options{})
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Fatalf("error = %v, want %q", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := s.Tables[0].Partition; got != tt.partition {
+				t.Errorf("partition = %q, want %q", got, tt.partition)
+			}
+		})
+	}
+}
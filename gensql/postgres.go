@@ -0,0 +1,353 @@
+package gensql
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Mojashi/proto-mysql/dep"
+	"github.com/golang/glog"
+	"github.com/golang/protobuf/protoc-gen-go/descriptor"
+)
+
+// PostgreSQL has no unsigned types, so unsigned values are widened
+var postgresDataTypeMap = map[descriptor.FieldDescriptorProto_Type]string{
+	descriptor.FieldDescriptorProto_TYPE_DOUBLE:   "DOUBLE PRECISION",
+	descriptor.FieldDescriptorProto_TYPE_FLOAT:    "REAL",
+	descriptor.FieldDescriptorProto_TYPE_INT64:    "BIGINT",
+	descriptor.FieldDescriptorProto_TYPE_UINT64:   "NUMERIC(20)",
+	descriptor.FieldDescriptorProto_TYPE_INT32:    "INTEGER",
+	descriptor.FieldDescriptorProto_TYPE_FIXED64:  "NUMERIC(20)",
+	descriptor.FieldDescriptorProto_TYPE_FIXED32:  "BIGINT",
+	descriptor.FieldDescriptorProto_TYPE_BOOL:     "BOOLEAN",
+	descriptor.FieldDescriptorProto_TYPE_STRING:   "TEXT",
+	descriptor.FieldDescriptorProto_TYPE_BYTES:    "BYTEA",
+	descriptor.FieldDescriptorProto_TYPE_UINT32:   "BIGINT",
+	descriptor.FieldDescriptorProto_TYPE_SFIXED32: "INTEGER",
+	descriptor.FieldDescriptorProto_TYPE_SFIXED64: "BIGINT",
+	descriptor.FieldDescriptorProto_TYPE_SINT32:   "INTEGER",
+	descriptor.FieldDescriptorProto_TYPE_SINT64:   "BIGINT",
+}
+
+// enum types are named after the full name of proto enum. .Foo.User.Gender -> foo_user_gender
+func postgresEnumTypeName(typeName string) string {
+	return strings.ToLower(strings.Replace(strings.TrimPrefix(typeName, "."), ".", "_", -1))
+}
+
+func GenPostgresDataType(dep dep.INameSpace, field *descriptor.FieldDescriptorProto) (string, error) {
+	if cand, ok := CheckSpecifiedType(dep, field); ok {
+		return cand.ToString(), nil
+	}
+	if err := checkSemantic(field); err != nil {
+		return "", err
+	}
+	switch GetSemantic(field) {
+	case MySQLColumn_UUID:
+		return "UUID", nil
+	case MySQLColumn_INET6:
+		return "INET", nil
+	}
+	if field.GetLabel() == descriptor.FieldDescriptorProto_LABEL_REPEATED {
+		return "JSONB", nil
+	}
+
+	switch field.GetType() {
+	case descriptor.FieldDescriptorProto_TYPE_MESSAGE, descriptor.FieldDescriptorProto_TYPE_GROUP:
+		return "JSONB", nil
+	case descriptor.FieldDescriptorProto_TYPE_ENUM:
+		if _, ok := dep.GetEnum(strings.Split(field.GetTypeName(), ".")); !ok {
+			return "", fmt.Errorf("failed to find ENUM %s", field.GetTypeName())
+		}
+		return QuoteIdent(DialectPostgreSQL, postgresEnumTypeName(field.GetTypeName())), nil
+	}
+	if t, ok := postgresDataTypeMap[field.GetType()]; ok {
+		return t, nil
+	}
+	if field.TypeName != nil {
+		return "JSONB", nil
+	}
+	return "", fmt.Errorf("failed to find type")
+}
+
+func genPostgresCreateType(dep dep.INameSpace, typeName string) (string, error) {
+	enum, ok := dep.GetEnum(strings.Split(typeName, "."))
+	if !ok {
+		return "", fmt.Errorf("failed to find ENUM %s", typeName)
+	}
+	values := []string{}
+	for _, name := range enumEnum(enum.GetEnum()) {
+		values = append(values, "'"+name+"'")
+	}
+	// PostgreSQL has no CREATE TYPE IF NOT EXISTS, so rerunning the SQL ignores the existing type
+	return fmt.Sprintf("DO $$ BEGIN\n\tCREATE TYPE %s AS ENUM (%s);\nEXCEPTION\n\tWHEN duplicate_object THEN NULL;\nEND $$;",
+		QuoteIdent(DialectPostgreSQL, postgresEnumTypeName(typeName)),
+		strings.Join(values, ","),
+	), nil
+}
+
+func genPostgresColumnDefinition(dep dep.INameSpace, field *descriptor.FieldDescriptorProto) (string, error) {
+	if field.GetName() == "" {
+		return "", fmt.Errorf("field name is empty")
+	}
+	dataType, err := GenPostgresDataType(dep, field)
+	if err != nil {
+		return "", err
+	}
+	nullable := "NOT NULL"
+	if field.GetProto3Optional() {
+		nullable = "NULL"
+	}
+	identity := ""
+	if IsAutoIncrement(field) {
+		switch dataType {
+		case "INTEGER", "BIGINT":
+			identity = " GENERATED BY DEFAULT AS IDENTITY"
+		default:
+			return "", fmt.Errorf("identity column %s must be an integer", field.GetName())
+		}
+	}
+	column, _ := CheckColumnOptions(field)
+	if column.GetInvisible() {
+		return "", fmt.Errorf("INVISIBLE column %s is not supported by PostgreSQL", field.GetName())
+	}
+	constraints := ""
+	if column.GetDefaultExpression() != "" {
+		constraints += fmt.Sprintf(" DEFAULT (%s)", column.GetDefaultExpression())
+	}
+	if column.GetCheck() != "" {
+		constraints += fmt.Sprintf(" CHECK (%s)", column.GetCheck())
+	}
+	return fmt.Sprintf("%s %s%s %s%s", QuoteIdent(DialectPostgreSQL, field.GetName()), dataType, identity, nullable, constraints), nil
+}
+
+func genPostgresAuditColumns(tableName TableName, a AuditColumns) (defs []string, trigger string) {
+	q := func(name string) string { return QuoteIdent(DialectPostgreSQL, name) }
+	defs = []string{
+		fmt.Sprintf("%s TIMESTAMP(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6)", q(a.CreatedAt)),
+		fmt.Sprintf("%s TIMESTAMP(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6)", q(a.UpdatedAt)),
+		fmt.Sprintf("%s BIGINT NOT NULL DEFAULT 0", q(a.RowVersion)),
+	}
+
+	// PostgreSQL has no ON UPDATE clause
+	function := tableName.WithSuffix("_set_" + a.UpdatedAt).Format(DialectPostgreSQL)
+	trigger = fmt.Sprintf(`CREATE OR REPLACE FUNCTION %[1]s() RETURNS trigger AS $$
+BEGIN
+	NEW.%[2]s = CURRENT_TIMESTAMP(6);
+	RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER %[3]s BEFORE UPDATE ON %[4]s FOR EACH ROW EXECUTE FUNCTION %[1]s();`,
+		function, q(a.UpdatedAt), q(tableName.Name+"_set_"+a.UpdatedAt), tableName.Format(DialectPostgreSQL))
+	return defs, trigger
+}
+
+// deleted rows are excluded from unique indexes by partial index instead of MySQL's marker column
+// multi-valued indexes become GIN indexes of JSONB column
+func genPostgresIndex(tableName TableName, mt *descriptor.DescriptorProto, index *MySQLIndex, sd SoftDelete, softDelete bool) (string, error) {
+	if len(index.GetColumns()) == 0 {
+		return "", fmt.Errorf("index %s has no columns", index.GetName())
+	}
+	using := ""
+	if index.GetMultiValued() {
+		if _, _, err := getMultiValuedField(mt, index); err != nil {
+			return "", err
+		}
+		if index.GetUnique() {
+			return "", fmt.Errorf("unique multi-valued index %s is not supported by PostgreSQL", index.GetName())
+		}
+		using = " USING GIN"
+	}
+	kind := "INDEX"
+	if index.GetUnique() {
+		kind = "UNIQUE INDEX"
+	}
+	if index.GetName() != "" {
+		kind += " " + QuoteIdent(DialectPostgreSQL, index.GetName())
+	}
+	where := ""
+	if index.GetIncludeSoftDelete() {
+		if !softDelete {
+			return "", fmt.Errorf("index %s includes soft-delete column but softDelete is not set", index.GetName())
+		}
+		where = fmt.Sprintf(" WHERE %s IS NULL", QuoteIdent(DialectPostgreSQL, sd.Column))
+	}
+	return fmt.Sprintf("CREATE %s ON %s%s (%s)%s;",
+		kind,
+		tableName.Format(DialectPostgreSQL),
+		using,
+		strings.Join(quoteIdents(DialectPostgreSQL, index.GetColumns()), ","),
+		where,
+	), nil
+}
+
+// partitions are separate tables in PostgreSQL. returns the PARTITION BY clause and CREATE TABLE ... PARTITION OF statements.
+func genPostgresPartitions(tableName TableName, mt *descriptor.DescriptorProto, t *MySQLTable) (string, []string, error) {
+	p := t.GetPartition()
+	if err := checkPartitionColumn(mt, t); err != nil {
+		return "", nil, err
+	}
+	if p.GetType() == MySQLPartition_KEY {
+		return "", nil, fmt.Errorf("KEY partitioning is not supported by PostgreSQL")
+	}
+	clause := fmt.Sprintf(" PARTITION BY %s (%s)", p.GetType(), QuoteIdent(DialectPostgreSQL, p.GetColumn()))
+
+	partitionOf := func(name string, bound string) string {
+		return fmt.Sprintf("CREATE TABLE %s PARTITION OF %s FOR VALUES %s;",
+			tableName.WithSuffix("_"+name).Format(DialectPostgreSQL),
+			tableName.Format(DialectPostgreSQL),
+			bound,
+		)
+	}
+
+	partitions := []string{}
+	switch p.GetType() {
+	case MySQLPartition_RANGE:
+		from := "MINVALUE"
+		for _, def := range p.GetDefinitions() {
+			to := def.GetValues()
+			partitions = append(partitions, partitionOf(def.GetName(), fmt.Sprintf("FROM (%s) TO (%s)", from, to)))
+			from = to
+		}
+	case MySQLPartition_LIST:
+		for _, def := range p.GetDefinitions() {
+			partitions = append(partitions, partitionOf(def.GetName(), fmt.Sprintf("IN (%s)", def.GetValues())))
+		}
+	case MySQLPartition_HASH:
+		names := []string{}
+		for _, def := range p.GetDefinitions() {
+			names = append(names, def.GetName())
+		}
+		if len(names) == 0 {
+			for i := 0; uint32(i) < p.GetPartitions(); i++ {
+				names = append(names, fmt.Sprintf("p%d", i))
+			}
+		}
+		for i, name := range names {
+			partitions = append(partitions, partitionOf(name, fmt.Sprintf("WITH (MODULUS %d, REMAINDER %d)", len(names), i)))
+		}
+	}
+	if len(partitions) == 0 {
+		return "", nil, fmt.Errorf("%s partitioning requires partition definitions", p.GetType())
+	}
+	return clause, partitions, nil
+}
+
+// errors fail the generation, as a table missing a column, key or partitioning cannot be created as specified
+func genPostgresCreateTable(dep dep.INameSpace, tableName TableName, mt *descriptor.DescriptorProto) (string, error) {
+	createDefinitions := make([]string, 0, len(mt.Field))
+	columns := map[string]bool{}
+	statements := []string{}
+
+	for _, field := range mt.Field {
+		columns[field.GetName()] = true
+		createDefinition, err := genPostgresColumnDefinition(dep, field)
+		if err != nil {
+			return "", fmt.Errorf("failed to process field %s in message %s: %v", field.GetName(), mt.GetName(), err)
+		}
+		createDefinitions = append(createDefinitions, "\t"+createDefinition)
+	}
+
+	if audit, ok := GetAuditColumns(mt); ok {
+		defs, trigger := genPostgresAuditColumns(tableName, audit)
+		for _, def := range defs {
+			createDefinitions = append(createDefinitions, "\t"+def)
+		}
+		for _, name := range audit.Names() {
+			columns[name] = true
+		}
+		statements = append(statements, trigger)
+	}
+
+	tableOpts, _ := CheckTableOptions(mt)
+	if len(tableOpts.GetForeignKeys()) > 0 {
+		return "", fmt.Errorf("foreign keys of message %s are only generated for MySQL and MariaDB", mt.GetName())
+	}
+	sd, softDelete := GetSoftDelete(mt)
+	if softDelete {
+		createDefinitions = append(createDefinitions,
+			fmt.Sprintf("\t%s TIMESTAMP(6) NULL DEFAULT NULL", QuoteIdent(DialectPostgreSQL, sd.Column)))
+		columns[sd.Column] = true
+	}
+
+	createDefinitions = append(createDefinitions,
+		"\t"+QuoteIdent(DialectPostgreSQL, "PROTO_BINARY")+" BYTEA NOT NULL",
+	)
+
+	if err := checkKeyColumns(mt, tableOpts, columns); err != nil {
+		return "", err
+	}
+
+	if len(tableOpts.GetPrimaryKey()) > 0 {
+		createDefinitions = append(createDefinitions, fmt.Sprintf("\tPRIMARY KEY (%s)",
+			strings.Join(quoteIdents(DialectPostgreSQL, tableOpts.GetPrimaryKey()), ",")))
+	}
+	for _, index := range tableOpts.GetIndexes() {
+		createIndex, err := genPostgresIndex(tableName, mt, index, sd, softDelete)
+		if err != nil {
+			return "", fmt.Errorf("failed to process index in message %s: %v", mt.GetName(), err)
+		}
+		statements = append(statements, createIndex)
+	}
+
+	partitionClause := ""
+	if tableOpts.GetPartition() != nil {
+		clause, partitions, err := genPostgresPartitions(tableName, mt, tableOpts)
+		if err != nil {
+			return "", fmt.Errorf("failed to process partition in message %s: %v", mt.GetName(), err)
+		}
+		partitionClause = clause
+		statements = append(partitions, statements...)
+	}
+
+	if softDelete && sd.LiveView {
+		statements = append(statements, fmt.Sprintf("CREATE VIEW %s AS SELECT * FROM %s WHERE %s IS NULL;",
+			LiveViewName(tableName).Format(DialectPostgreSQL),
+			tableName.Format(DialectPostgreSQL),
+			QuoteIdent(DialectPostgreSQL, sd.Column),
+		))
+	}
+
+	createTable := fmt.Sprintf("CREATE TABLE %s (\n%s\n)%s;",
+		tableName.Format(DialectPostgreSQL),
+		strings.Join(createDefinitions, ",\n"),
+		partitionClause,
+	)
+	return strings.Join(append([]string{createTable}, statements...), "\n\n"), nil
+}
+
+// createdTypes holds enum types created by earlier files of the request, and gets the types created by f
+func genPostgresSQL(dep dep.INameSpace, f *descriptor.FileDescriptorProto, opts Options, createdTypes map[string]bool) (string, error) {
+	statements := []string{}
+	if opts.PackageMapping == PackageMappingDatabase && f.GetPackage() != "" {
+		statements = append(statements, fmt.Sprintf("CREATE SCHEMA IF NOT EXISTS %s;", QuoteIdent(DialectPostgreSQL, packageIdent(f))))
+	}
+
+	// enum types have to be created before the tables using them
+	for _, mt := range f.MessageType {
+		for _, field := range mt.Field {
+			if field.GetType() != descriptor.FieldDescriptorProto_TYPE_ENUM || createdTypes[field.GetTypeName()] {
+				continue
+			}
+			if t, err := GenPostgresDataType(dep, field); err != nil || t != QuoteIdent(DialectPostgreSQL, postgresEnumTypeName(field.GetTypeName())) {
+				// repeated or type specified by option
+				continue
+			}
+			createdTypes[field.GetTypeName()] = true
+			createType, err := genPostgresCreateType(dep, field.GetTypeName())
+			if err != nil {
+				glog.Error(err)
+				continue
+			}
+			statements = append(statements, createType)
+		}
+	}
+
+	for _, mt := range f.MessageType {
+		createTable, err := genPostgresCreateTable(dep, GetTableName(f, mt, opts), mt)
+		if err != nil {
+			return "", err
+		}
+		statements = append(statements, createTable)
+	}
+	return strings.Join(statements, "\n\n"), nil
+}
@@ -0,0 +1,100 @@
+package gensql
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestGenPostgresSQL(t *testing.T) {
+	tests := []struct {
+		name   string
+		fields string
+		sql    string
+		err    string
+	}{
+		{
+			name: "columns",
+			fields: `
+				field { name: "id" number: 1 type: TYPE_INT64 label: LABEL_OPTIONAL options { [mySQLColumn] { autoIncrement: true } } }
+				field { name: "session" number: 2 type: TYPE_STRING label: LABEL_OPTIONAL options { [mySQLColumn] { semantic: UUID } } }
+				field { name: "gender" number: 3 type: TYPE_ENUM type_name: ".Foo.Gender" label: LABEL_OPTIONAL }
+				field { name: "stamps" number: 4 type: TYPE_INT32 label: LABEL_REPEATED }`,
+			sql: `DO $$ BEGIN
+	CREATE TYPE "foo_gender" AS ENUM ('MALE','FEMALE');
+EXCEPTION
+	WHEN duplicate_object THEN NULL;
+END $$;
+
+CREATE TABLE "User" (
+	"id" BIGINT GENERATED BY DEFAULT AS IDENTITY NOT NULL,
+	"session" UUID NOT NULL,
+	"gender" "foo_gender" NOT NULL,
+	"stamps" JSONB NOT NULL,
+	"PROTO_BINARY" BYTEA NOT NULL
+);`,
+		},
+		{
+			name:   "invisible",
+			fields: `field { name: "token" number: 1 type: TYPE_STRING label: LABEL_OPTIONAL options { [mySQLColumn] { invisible: true } } }`,
+			err:    "failed to process field token in message User: INVISIBLE column token is not supported by PostgreSQL",
+		},
+		{
+			name:   "identity of non-integer",
+			fields: `field { name: "id" number: 1 type: TYPE_STRING label: LABEL_OPTIONAL options { [mySQLColumn] { autoIncrement: true } } }`,
+			err:    "failed to process field id in message User: identity column id must be an integer",
+		},
+		{
+			name:   "semantic of non-string",
+			fields: `field { name: "session" number: 1 type: TYPE_INT64 label: LABEL_OPTIONAL options { [mySQLColumn] { semantic: UUID } } }`,
+			err:    "failed to process field session in message User",
+		},
+		{
+			name: "foreign key",
+			fields: `
+				field { name: "parent_id" number: 1 type: TYPE_INT64 label: LABEL_OPTIONAL }
+				options { [mySQLTable] { foreignKeys { columns: ["parent_id"] references: "User" referencedColumns: ["parent_id"] } } }`,
+			err: "foreign keys of message User are only generated for MySQL and MariaDB",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dep, f := parseMessages(t, `
+				message_type { name: "User" `+tt.fields+` }
+				`+genderEnum)
+			sql, err := GenSQL(dep, f, Options{Dialect: DialectPostgreSQL})
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Fatalf("error = %v, want %q", err, tt.err)
+				}
+				if sql != "" {
+					t.Errorf("sql = %q, want none", sql)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if sql != tt.sql {
+				t.Errorf("sql = \n%s\nwant\n%s", sql, tt.sql)
+			}
+		})
+	}
+}
+
+func TestGenPostgresSQLFilesSharedEnum(t *testing.T) {
+	deps, files := parseFiles(t,
+		`name: "user.proto" package: "Foo" syntax: "proto3"
+		message_type { name: "User" field { name: "gender" number: 1 type: TYPE_ENUM type_name: ".Foo.Gender" label: LABEL_OPTIONAL } }
+		`+genderEnum,
+		`name: "admin.proto" package: "Foo" syntax: "proto3" dependency: "user.proto"
+		message_type { name: "Admin" field { name: "gender" number: 1 type: TYPE_ENUM type_name: ".Foo.Gender" label: LABEL_OPTIONAL } }`)
+	sqls, err := GenSQLFiles(deps, files, Options{Dialect: DialectPostgreSQL})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, want := range []int{1, 0} {
+		if n := strings.Count(sqls[i], "CREATE TYPE"); n != want {
+			t.Errorf("file %s creates %d types, want %d:\n%s", files[i].GetName(), n, want, sqls[i])
+		}
+	}
+}
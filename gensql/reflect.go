@@ -0,0 +1,32 @@
+package gensql
+
+import (
+	"fmt"
+
+	"github.com/Mojashi/proto-mysql/dep"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// build schema from compiled-in descriptors without protoc.
+// e.g. protoregistry.GlobalFiles.FindFileByPath("foo/user.proto")
+func BuildSchemaFromFileDescriptor(fd protoreflect.FileDescriptor, opts Options) (*Schema, error) {
+	return BuildSchema(dep.AnalyzeFileDescriptor(fd), protodesc.ToFileDescriptorProto(fd), opts)
+}
+
+// build the table of a top-level message. e.g. (&pb.User{}).ProtoReflect().Descriptor()
+func BuildTableFromMessageDescriptor(md protoreflect.MessageDescriptor, opts Options) (*Table, error) {
+	if _, ok := md.Parent().(protoreflect.FileDescriptor); !ok {
+		return nil, fmt.Errorf("message %s is not a top-level message", md.FullName())
+	}
+	s, err := BuildSchemaFromFileDescriptor(md.ParentFile(), opts)
+	if err != nil {
+		return nil, err
+	}
+	for _, t := range s.Tables {
+		if t.Message == string(md.FullName()) {
+			return t, nil
+		}
+	}
+	return nil, fmt.Errorf("table of message %s is not found", md.FullName())
+}
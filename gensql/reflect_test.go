@@ -0,0 +1,92 @@
+package gensql
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/golang/protobuf/protoc-gen-go/descriptor"
+	"google.golang.org/protobuf/encoding/prototext"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+)
+
+// user.proto imports status.proto, which imports gender.proto publicly
+var reflectTestFiles = []string{
+	`name: "gender.proto" package: "Baz" syntax: "proto3"
+	enum_type { name: "Gender" value { name: "MALE" number: 0 } value { name: "FEMALE" number: 1 } }`,
+	`name: "status.proto" package: "Bar" syntax: "proto3" dependency: "gender.proto" public_dependency: 0
+	message_type { name: "Status" field { name: "gender" number: 1 type: TYPE_ENUM type_name: ".Baz.Gender" label: LABEL_OPTIONAL } }`,
+	`name: "user.proto" package: "Foo" syntax: "proto3" dependency: "status.proto"
+	message_type {
+		name: "User"
+		field { name: "id" number: 1 type: TYPE_INT64 label: LABEL_OPTIONAL }
+		field { name: "status" number: 2 type: TYPE_MESSAGE type_name: ".Bar.Status" label: LABEL_OPTIONAL }
+		field { name: "gender" number: 3 type: TYPE_ENUM type_name: ".Baz.Gender" label: LABEL_OPTIONAL }
+		nested_type { name: "Nested" field { name: "id" number: 1 type: TYPE_INT64 label: LABEL_OPTIONAL } }
+		options { [mySQLTable] { primaryKey: ["id"] } }
+	}`,
+}
+
+func reflectTestFile(t *testing.T) protoreflect.FileDescriptor {
+	t.Helper()
+	files := &protoregistry.Files{}
+	var fd protoreflect.FileDescriptor
+	for _, text := range reflectTestFiles {
+		f := &descriptor.FileDescriptorProto{}
+		if err := prototext.Unmarshal([]byte(text), f); err != nil {
+			t.Fatal(err)
+		}
+		var err error
+		if fd, err = protodesc.NewFile(f, files); err != nil {
+			t.Fatal(err)
+		}
+		if err := files.RegisterFile(fd); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return fd
+}
+
+func TestBuildTableFromMessageDescriptor(t *testing.T) {
+	fd := reflectTestFile(t)
+	tests := []struct {
+		name    string
+		message protoreflect.MessageDescriptor
+		columns string
+		err     bool
+	}{
+		{
+			name:    "top-level message",
+			message: fd.Messages().ByName("User"),
+			columns: "id BIGINT, status JSON, gender ENUM('MALE','FEMALE'), PROTO_BINARY BLOB",
+		},
+		{
+			name:    "nested message",
+			message: fd.Messages().ByName("User").Messages().ByName("Nested"),
+			err:     true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			table, err := BuildTableFromMessageDescriptor(tt.message, Options{})
+			if (err != nil) != tt.err {
+				t.Fatalf("err = %v, want error %v", err, tt.err)
+			}
+			if err != nil {
+				return
+			}
+			columns := []string{}
+			for _, c := range table.Columns {
+				columns = append(columns, c.Name+" "+c.Type.ToString())
+			}
+			if s := strings.Join(columns, ", "); s != tt.columns {
+				t.Errorf("columns = %s, want %s", s, tt.columns)
+			}
+			if table.Name.Name != "User" || table.Message != "Foo.User" || !reflect.DeepEqual(table.PrimaryKey, []string{"id"}) {
+				t.Errorf("table = %+v", table)
+			}
+		})
+	}
+}
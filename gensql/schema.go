@@ -0,0 +1,278 @@
+package gensql
+
+import (
+	"fmt"
+	"strings"
+)
+
+// name of the column which holds the serialized message
+const ProtoBinaryColumn = "PROTO_BINARY"
+
+// tables generated for a proto file with dialect mysql or mariadb.
+// SQL is rendered from this model, so tools can reuse the mapping without parsing SQL.
+type Schema struct {
+	// database created before tables. empty if none
+	Database string
+	Tables   []*Table
+}
+
+type Table struct {
+	Name TableName
+	// full name of the message. e.g. "Foo.User"
+	Message     string
+	Columns     []*Column
+	PrimaryKey  []string
+	Indexes     []*Index
+	ForeignKeys []*ForeignKey
+	Checks      []*Check
+	// empty if not specified
+	Charset   string
+	Collation string
+	// e.g. "PARTITION BY HASH (tenant_id) PARTITIONS 4". empty if none
+	Partition string
+	// soft-delete column and the live view. nil if none
+	SoftDelete *SoftDelete
+	// tables of former message names, which migrations rename
+	PreviousNames []TableName
+	// reserved field numbers and names of the message
+	ReservedRanges []ReservedRange
+	ReservedNames  []string
+	// MessageFingerprint of the message
+	Fingerprint string
+}
+
+// field numbers from Start to End, exclusive
+type ReservedRange struct {
+	Start int32
+	End   int32
+}
+
+type Column struct {
+	Name     string
+	Type     MySQLDataTypeWithArgs
+	Nullable bool
+	// SQL expression. e.g. "CURRENT_TIMESTAMP(6)", "(UUID())". empty if none
+	Default  string
+	OnUpdate string
+	// expression of VIRTUAL generated column. empty if the column is stored
+	Generated     string
+	AutoIncrement bool
+	Invisible     bool
+	// proto field held by the column. nil for audit, soft-delete and PROTO_BINARY columns
+	Field *FieldRef
+	// proto enum values of ENUM column, in the order of the type. nil for other columns
+	Enum []EnumValue
+}
+
+type EnumValue struct {
+	Name   string
+	Number int32
+}
+
+// proto field of a column
+type FieldRef struct {
+	Name     string
+	Number   int32
+	FullName string
+}
+
+type Index struct {
+	Name string
+	// column names, or key parts such as "(CAST(stamps->'$' AS SIGNED ARRAY))"
+	Columns []string
+	Unique  bool
+}
+
+type ForeignKey struct {
+	Name              string
+	Columns           []string
+	ReferencedTable   TableName
+	ReferencedColumns []string
+	OnDelete          string
+	OnUpdate          string
+}
+
+type Check struct {
+	Name string
+	// column of column-level constraint. empty for table-level constraint
+	Column     string
+	Expression string
+}
+
+func (t *Table) GetColumn(name string) (*Column, bool) {
+	for _, c := range t.Columns {
+		if c.Name == name {
+			return c, true
+		}
+	}
+	return nil, false
+}
+
+// whether the field was deleted and reserved
+func (t *Table) IsReserved(f *FieldRef) bool {
+	for _, r := range t.ReservedRanges {
+		if r.Start <= f.Number && f.Number < r.End {
+			return true
+		}
+	}
+	for _, name := range t.ReservedNames {
+		if name == f.Name {
+			return true
+		}
+	}
+	return false
+}
+
+func (t *Table) columnChecks(column string) []*Check {
+	ret := []*Check{}
+	for _, c := range t.Checks {
+		if c.Column == column {
+			ret = append(ret, c)
+		}
+	}
+	return ret
+}
+
+func genCheckDefinition(c *Check) string {
+	if c.Name != "" {
+		return fmt.Sprintf("CONSTRAINT %s CHECK (%s)", c.Name, c.Expression)
+	}
+	return fmt.Sprintf("CHECK (%s)", c.Expression)
+}
+
+// return column definition. e.g. "id INT NOT NULL AUTO_INCREMENT"
+func genColumnDefinition(c *Column, checks []*Check) string {
+	terms := []string{c.Name, c.Type.ToString()}
+	if c.Generated != "" {
+		terms = append(terms, fmt.Sprintf("AS (%s) VIRTUAL", c.Generated))
+	} else if c.Nullable {
+		terms = append(terms, "NULL")
+	} else {
+		terms = append(terms, "NOT NULL")
+	}
+	if c.Default != "" {
+		terms = append(terms, "DEFAULT "+c.Default)
+	}
+	if c.OnUpdate != "" {
+		terms = append(terms, "ON UPDATE "+c.OnUpdate)
+	}
+	if c.Invisible {
+		terms = append(terms, "INVISIBLE")
+	}
+	if c.AutoIncrement {
+		terms = append(terms, "AUTO_INCREMENT")
+	}
+	for _, check := range checks {
+		terms = append(terms, genCheckDefinition(check))
+	}
+	return strings.Join(terms, " ")
+}
+
+func genIndexDefinition(index *Index) string {
+	kind := "INDEX"
+	if index.Unique {
+		kind = "UNIQUE INDEX"
+	}
+	if index.Name != "" {
+		kind += " " + index.Name
+	}
+	return fmt.Sprintf("%s (%s)", kind, strings.Join(index.Columns, ","))
+}
+
+// render CREATE TABLE, followed by the live view if any
+func genTableSQL(t *Table, ifNotExists bool) string {
+	createDefinitions := make([]string, 0, len(t.Columns))
+	for _, c := range t.Columns {
+		createDefinitions = append(createDefinitions, "\t"+genColumnDefinition(c, t.columnChecks(c.Name)))
+	}
+	if len(t.PrimaryKey) > 0 {
+		createDefinitions = append(createDefinitions, fmt.Sprintf("\tPRIMARY KEY (%s)", strings.Join(t.PrimaryKey, ",")))
+	}
+	for _, index := range t.Indexes {
+		createDefinitions = append(createDefinitions, "\t"+genIndexDefinition(index))
+	}
+	for _, fk := range t.ForeignKeys {
+		createDefinitions = append(createDefinitions, "\t"+genForeignKeyDefinition(fk))
+	}
+	for _, check := range t.columnChecks("") {
+		createDefinitions = append(createDefinitions, "\t"+genCheckDefinition(check))
+	}
+
+	tableOptions := ""
+	if t.Charset != "" {
+		tableOptions += " DEFAULT CHARSET=" + t.Charset
+	}
+	if t.Collation != "" {
+		tableOptions += " COLLATE=" + t.Collation
+	}
+	if t.Partition != "" {
+		tableOptions += " " + t.Partition
+	}
+
+	createTable := "CREATE TABLE"
+	if ifNotExists {
+		createTable += " IF NOT EXISTS"
+	}
+	createTable = fmt.Sprintf("%s %s (\n%s\n)%s;",
+		createTable,
+		t.Name,
+		strings.Join(createDefinitions, ",\n"),
+		tableOptions,
+	)
+	if t.SoftDelete != nil && t.SoftDelete.LiveView {
+		liveView := genLiveView(t.Name, *t.SoftDelete)
+		if ifNotExists {
+			// MySQL has no CREATE VIEW IF NOT EXISTS
+			liveView = strings.Replace(liveView, "CREATE VIEW", "CREATE OR REPLACE VIEW", 1)
+		}
+		createTable += "\n\n" + liveView
+	}
+	return createTable
+}
+
+// DROP statements of the tables and their live views. referencing tables are dropped first.
+func genDropTables(tables []*Table) []string {
+	statements := []string{}
+	for i := len(tables) - 1; i >= 0; i-- {
+		t := tables[i]
+		if t.SoftDelete != nil && t.SoftDelete.LiveView {
+			statements = append(statements, fmt.Sprintf("DROP VIEW IF EXISTS %s;", LiveViewName(t.Name)))
+		}
+		statements = append(statements, fmt.Sprintf("DROP TABLE IF EXISTS %s;", t.Name))
+	}
+	return statements
+}
+
+// tables are created in the order of s.Tables
+func genSchemaSQL(s *Schema, opts Options) string {
+	statements := make([]string, 0, len(s.Tables)+1)
+	if opts.ForeignKeyChecksGuard {
+		statements = append(statements, "SET @OLD_FOREIGN_KEY_CHECKS=@@FOREIGN_KEY_CHECKS, FOREIGN_KEY_CHECKS=0;")
+	}
+	if s.Database != "" {
+		statements = append(statements, fmt.Sprintf("CREATE DATABASE IF NOT EXISTS `%s`;", s.Database))
+	}
+	if opts.DropTables {
+		statements = append(statements, strings.Join(genDropTables(s.Tables), "\n"))
+	}
+	for _, t := range s.Tables {
+		statements = append(statements, genTableSQL(t, opts.IfNotExists))
+	}
+	if opts.Fingerprint && len(s.Tables) > 0 {
+		statements = append(statements, genFingerprintSQL(s))
+	}
+	if opts.ForeignKeyChecksGuard {
+		statements = append(statements, "SET FOREIGN_KEY_CHECKS=@OLD_FOREIGN_KEY_CHECKS;")
+	}
+	return strings.Join(statements, "\n\n")
+}
+
+// CREATE statements of the schema
+func (s *Schema) SQL() string {
+	return genSchemaSQL(s, Options{})
+}
+
+// CREATE TABLE, followed by the live view if any
+func (t *Table) SQL() string {
+	return genTableSQL(t, false)
+}
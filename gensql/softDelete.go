@@ -0,0 +1,67 @@
+package gensql
+
+import (
+	"fmt"
+
+	"github.com/golang/protobuf/protoc-gen-go/descriptor"
+)
+
+const (
+	defaultSoftDeleteColumn = "deleted_at"
+	// 1 for live rows and NULL for deleted rows.
+	// unique indexes ignore NULL, so deleted rows never collide.
+	SoftDeleteMarkerColumn = "is_live"
+)
+
+type SoftDelete struct {
+	Column   string
+	LiveView bool
+}
+
+func GetSoftDelete(mt *descriptor.DescriptorProto) (SoftDelete, bool) {
+	t, ok := CheckTableOptions(mt)
+	if !ok || t.GetSoftDelete() == nil {
+		return SoftDelete{}, false
+	}
+	ret := SoftDelete{
+		Column:   t.GetSoftDelete().GetColumn(),
+		LiveView: t.GetSoftDelete().GetLiveView(),
+	}
+	if ret.Column == "" {
+		ret.Column = defaultSoftDeleteColumn
+	}
+	return ret, true
+}
+
+func LiveViewName(tableName TableName) TableName {
+	return tableName.WithSuffix("_live")
+}
+
+func needsSoftDeleteMarker(t *MySQLTable) bool {
+	for _, index := range t.GetIndexes() {
+		if index.GetIncludeSoftDelete() {
+			return true
+		}
+	}
+	return false
+}
+
+func softDeleteColumns(sd SoftDelete, withMarker bool, invisibleMarker bool) []*Column {
+	columns := []*Column{
+		{Name: sd.Column, Type: MySQLDataTypeWithArgs{DATETIME, []string{"6"}}, Nullable: true, Default: "NULL"},
+	}
+	if withMarker {
+		columns = append(columns, &Column{
+			Name:      SoftDeleteMarkerColumn,
+			Type:      MySQLDataTypeWithArgs{TINYINT, nil},
+			Generated: fmt.Sprintf("IF(%s IS NULL, 1, NULL)", sd.Column),
+			Invisible: invisibleMarker,
+		})
+	}
+	return columns
+}
+
+func genLiveView(tableName TableName, sd SoftDelete) string {
+	return fmt.Sprintf("CREATE VIEW %s AS SELECT * FROM %s WHERE %s IS NULL;",
+		LiveViewName(tableName), tableName, sd.Column)
+}
@@ -0,0 +1,273 @@
+package gensql
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Mojashi/proto-mysql/dep"
+	"github.com/golang/protobuf/protoc-gen-go/descriptor"
+)
+
+// SQLite stores values by type affinity. ranges and enums are kept by CHECK constraints.
+var sqliteDataTypeMap = map[descriptor.FieldDescriptorProto_Type]string{
+	descriptor.FieldDescriptorProto_TYPE_DOUBLE:   "REAL",
+	descriptor.FieldDescriptorProto_TYPE_FLOAT:    "REAL",
+	descriptor.FieldDescriptorProto_TYPE_INT64:    "INTEGER",
+	descriptor.FieldDescriptorProto_TYPE_UINT64:   "INTEGER",
+	descriptor.FieldDescriptorProto_TYPE_INT32:    "INTEGER",
+	descriptor.FieldDescriptorProto_TYPE_FIXED64:  "INTEGER",
+	descriptor.FieldDescriptorProto_TYPE_FIXED32:  "INTEGER",
+	descriptor.FieldDescriptorProto_TYPE_BOOL:     "INTEGER",
+	descriptor.FieldDescriptorProto_TYPE_STRING:   "TEXT",
+	descriptor.FieldDescriptorProto_TYPE_BYTES:    "BLOB",
+	descriptor.FieldDescriptorProto_TYPE_UINT32:   "INTEGER",
+	descriptor.FieldDescriptorProto_TYPE_ENUM:     "TEXT",
+	descriptor.FieldDescriptorProto_TYPE_SFIXED32: "INTEGER",
+	descriptor.FieldDescriptorProto_TYPE_SFIXED64: "INTEGER",
+	descriptor.FieldDescriptorProto_TYPE_SINT32:   "INTEGER",
+	descriptor.FieldDescriptorProto_TYPE_SINT64:   "INTEGER",
+}
+
+// SQLite INTEGER is signed 64-bit, so uint64 values above 2^63-1 cannot be stored
+var sqliteRangeChecks = map[descriptor.FieldDescriptorProto_Type]string{
+	descriptor.FieldDescriptorProto_TYPE_UINT32:  "%s BETWEEN 0 AND 4294967295",
+	descriptor.FieldDescriptorProto_TYPE_FIXED32: "%s BETWEEN 0 AND 4294967295",
+	descriptor.FieldDescriptorProto_TYPE_UINT64:  "%s >= 0",
+	descriptor.FieldDescriptorProto_TYPE_FIXED64: "%s >= 0",
+	descriptor.FieldDescriptorProto_TYPE_BOOL:    "%s IN (0,1)",
+}
+
+const sqliteCurrentTimestamp = "strftime('%Y-%m-%d %H:%M:%f','now')"
+
+// return type affinity and CHECK expression of the column
+func GenSQLiteDataType(dep dep.INameSpace, field *descriptor.FieldDescriptorProto) (string, string, error) {
+	column := QuoteIdent(DialectSQLite, field.GetName())
+	if cand, ok := CheckSpecifiedType(dep, field); ok {
+		return cand.ToString(), "", nil
+	}
+	if err := checkSemantic(field); err != nil {
+		return "", "", err
+	}
+	if field.GetLabel() == descriptor.FieldDescriptorProto_LABEL_REPEATED {
+		return "TEXT", fmt.Sprintf("json_valid(%s)", column), nil
+	}
+
+	switch field.GetType() {
+	case descriptor.FieldDescriptorProto_TYPE_MESSAGE, descriptor.FieldDescriptorProto_TYPE_GROUP:
+		return "TEXT", fmt.Sprintf("json_valid(%s)", column), nil
+	case descriptor.FieldDescriptorProto_TYPE_ENUM:
+		enum, ok := dep.GetEnum(strings.Split(field.GetTypeName(), "."))
+		if !ok {
+			return "", "", fmt.Errorf("failed to find ENUM %s", field.GetTypeName())
+		}
+		values := []string{}
+		for _, name := range enumEnum(enum.GetEnum()) {
+			values = append(values, "'"+name+"'")
+		}
+		return "TEXT", fmt.Sprintf("%s IN (%s)", column, strings.Join(values, ",")), nil
+	}
+	if t, ok := sqliteDataTypeMap[field.GetType()]; ok {
+		check := ""
+		if c, ok := sqliteRangeChecks[field.GetType()]; ok {
+			check = fmt.Sprintf(c, column)
+		}
+		return t, check, nil
+	}
+	if field.TypeName != nil {
+		return "TEXT", fmt.Sprintf("json_valid(%s)", column), nil
+	}
+	return "", "", fmt.Errorf("failed to find type")
+}
+
+// AUTOINCREMENT is only allowed on the rowid alias, which is declared by "INTEGER PRIMARY KEY" of a single column.
+func genSQLiteColumnDefinition(dep dep.INameSpace, field *descriptor.FieldDescriptorProto, primaryKey []string) (string, error) {
+	if field.GetName() == "" {
+		return "", fmt.Errorf("field name is empty")
+	}
+	dataType, check, err := GenSQLiteDataType(dep, field)
+	if err != nil {
+		return "", err
+	}
+	nullable := "NOT NULL"
+	if field.GetProto3Optional() {
+		nullable = "NULL"
+	}
+	if IsAutoIncrement(field) {
+		if dataType != "INTEGER" || len(primaryKey) != 1 || primaryKey[0] != field.GetName() {
+			return "", fmt.Errorf("AUTOINCREMENT column %s must be the only primary key column of INTEGER", field.GetName())
+		}
+		nullable = "PRIMARY KEY AUTOINCREMENT"
+	}
+	if check != "" {
+		check = fmt.Sprintf(" CHECK (%s)", check)
+	}
+	column, _ := CheckColumnOptions(field)
+	if column.GetInvisible() {
+		return "", fmt.Errorf("INVISIBLE column %s is not supported by SQLite", field.GetName())
+	}
+	if column.GetCheck() != "" {
+		check += fmt.Sprintf(" CHECK (%s)", column.GetCheck())
+	}
+	if column.GetDefaultExpression() != "" {
+		check = fmt.Sprintf(" DEFAULT (%s)", column.GetDefaultExpression()) + check
+	}
+	return fmt.Sprintf("%s %s %s%s", QuoteIdent(DialectSQLite, field.GetName()), dataType, nullable, check), nil
+}
+
+func hasAutoIncrement(mt *descriptor.DescriptorProto) bool {
+	for _, field := range mt.Field {
+		if IsAutoIncrement(field) {
+			return true
+		}
+	}
+	return false
+}
+
+// indexes of attached databases are qualified by index name instead of table name
+func genSQLiteIndex(tableName TableName, index *MySQLIndex, sd SoftDelete, softDelete bool) (string, error) {
+	if len(index.GetColumns()) == 0 {
+		return "", fmt.Errorf("index %s has no columns", index.GetName())
+	}
+	if index.GetMultiValued() {
+		return "", fmt.Errorf("multi-valued index %s is not supported by SQLite", index.GetName())
+	}
+	name := index.GetName()
+	if name == "" {
+		name = tableName.Name + "_" + strings.Join(index.GetColumns(), "_")
+	}
+	kind := "INDEX"
+	if index.GetUnique() {
+		kind = "UNIQUE INDEX"
+	}
+	where := ""
+	if index.GetIncludeSoftDelete() {
+		if !softDelete {
+			return "", fmt.Errorf("index %s includes soft-delete column but softDelete is not set", index.GetName())
+		}
+		where = fmt.Sprintf(" WHERE %s IS NULL", QuoteIdent(DialectSQLite, sd.Column))
+	}
+	return fmt.Sprintf("CREATE %s %s ON %s (%s)%s;",
+		kind,
+		TableName{Database: tableName.Database, Name: name}.Format(DialectSQLite),
+		QuoteIdent(DialectSQLite, tableName.Name),
+		strings.Join(quoteIdents(DialectSQLite, index.GetColumns()), ","),
+		where,
+	), nil
+}
+
+func genSQLiteUpdatedAtTrigger(tableName TableName, a AuditColumns, primaryKey []string) string {
+	q := func(name string) string { return QuoteIdent(DialectSQLite, name) }
+	conds := []string{}
+	for _, column := range primaryKey {
+		conds = append(conds, fmt.Sprintf("%s = NEW.%s", q(column), q(column)))
+	}
+	if len(conds) == 0 {
+		conds = append(conds, "rowid = NEW.rowid")
+	}
+	return fmt.Sprintf(`CREATE TRIGGER %s AFTER UPDATE ON %s FOR EACH ROW BEGIN
+	UPDATE %s SET %s = %s WHERE %s;
+END;`,
+		tableName.WithSuffix("_set_"+a.UpdatedAt).Format(DialectSQLite),
+		q(tableName.Name),
+		q(tableName.Name), q(a.UpdatedAt), sqliteCurrentTimestamp, strings.Join(conds, " AND "),
+	)
+}
+
+// errors of columns, keys and indexes fail the generation, as the table cannot be created as specified
+func genSQLiteCreateTable(dep dep.INameSpace, tableName TableName, mt *descriptor.DescriptorProto) (string, error) {
+	createDefinitions := make([]string, 0, len(mt.Field))
+	columns := map[string]bool{}
+	statements := []string{}
+	tableOpts, _ := CheckTableOptions(mt)
+	if len(tableOpts.GetForeignKeys()) > 0 {
+		return "", fmt.Errorf("foreign keys of message %s are only generated for MySQL and MariaDB", mt.GetName())
+	}
+
+	for _, field := range mt.Field {
+		columns[field.GetName()] = true
+		createDefinition, err := genSQLiteColumnDefinition(dep, field, tableOpts.GetPrimaryKey())
+		if err != nil {
+			return "", fmt.Errorf("failed to process field %s in message %s: %v", field.GetName(), mt.GetName(), err)
+		}
+		createDefinitions = append(createDefinitions, "\t"+createDefinition)
+	}
+
+	if audit, ok := GetAuditColumns(mt); ok {
+		q := func(name string) string { return QuoteIdent(DialectSQLite, name) }
+		createDefinitions = append(createDefinitions,
+			fmt.Sprintf("\t%s TEXT NOT NULL DEFAULT (%s)", q(audit.CreatedAt), sqliteCurrentTimestamp),
+			fmt.Sprintf("\t%s TEXT NOT NULL DEFAULT (%s)", q(audit.UpdatedAt), sqliteCurrentTimestamp),
+			fmt.Sprintf("\t%s INTEGER NOT NULL DEFAULT 0", q(audit.RowVersion)),
+		)
+		for _, name := range audit.Names() {
+			columns[name] = true
+		}
+		// SQLite has no ON UPDATE clause
+		statements = append(statements, genSQLiteUpdatedAtTrigger(tableName, audit, tableOpts.GetPrimaryKey()))
+	}
+
+	sd, softDelete := GetSoftDelete(mt)
+	if softDelete {
+		createDefinitions = append(createDefinitions,
+			fmt.Sprintf("\t%s TEXT NULL DEFAULT NULL", QuoteIdent(DialectSQLite, sd.Column)))
+		columns[sd.Column] = true
+	}
+
+	createDefinitions = append(createDefinitions,
+		"\t"+QuoteIdent(DialectSQLite, "PROTO_BINARY")+" BLOB NOT NULL",
+	)
+
+	if err := checkKeyColumns(mt, tableOpts, columns); err != nil {
+		return "", err
+	}
+
+	// rowid tables are kept for AUTOINCREMENT, whose primary key is declared in the column
+	tableOptions := ""
+	if len(tableOpts.GetPrimaryKey()) > 0 && !hasAutoIncrement(mt) {
+		createDefinitions = append(createDefinitions, fmt.Sprintf("\tPRIMARY KEY (%s)",
+			strings.Join(quoteIdents(DialectSQLite, tableOpts.GetPrimaryKey()), ",")))
+		tableOptions = " WITHOUT ROWID"
+	}
+	for _, index := range tableOpts.GetIndexes() {
+		createIndex, err := genSQLiteIndex(tableName, index, sd, softDelete)
+		if err != nil {
+			return "", fmt.Errorf("failed to process index in message %s: %v", mt.GetName(), err)
+		}
+		statements = append(statements, createIndex)
+	}
+
+	if tableOpts.GetPartition() != nil {
+		return "", fmt.Errorf("partitioning of message %s is not supported by SQLite", mt.GetName())
+	}
+
+	if softDelete && sd.LiveView {
+		statements = append(statements, fmt.Sprintf("CREATE VIEW %s AS SELECT * FROM %s WHERE %s IS NULL;",
+			LiveViewName(tableName).Format(DialectSQLite),
+			QuoteIdent(DialectSQLite, tableName.Name),
+			QuoteIdent(DialectSQLite, sd.Column),
+		))
+	}
+
+	createTable := fmt.Sprintf("CREATE TABLE %s (\n%s\n)%s;",
+		tableName.Format(DialectSQLite),
+		strings.Join(createDefinitions, ",\n"),
+		tableOptions,
+	)
+	return strings.Join(append([]string{createTable}, statements...), "\n\n"), nil
+}
+
+// SQLite cannot create databases. with PackageMappingDatabase, tables are created in the attached database of the package name.
+func genSQLiteSQL(dep dep.INameSpace, f *descriptor.FileDescriptorProto, opts Options) (string, error) {
+	statements := []string{}
+	if opts.PackageMapping == PackageMappingDatabase && f.GetPackage() != "" {
+		statements = append(statements, fmt.Sprintf("-- ATTACH DATABASE '<file>' AS %s; is required before running", QuoteIdent(DialectSQLite, packageIdent(f))))
+	}
+	for _, mt := range f.MessageType {
+		createTable, err := genSQLiteCreateTable(dep, GetTableName(f, mt, opts), mt)
+		if err != nil {
+			return "", err
+		}
+		statements = append(statements, createTable)
+	}
+	return strings.Join(statements, "\n\n"), nil
+}
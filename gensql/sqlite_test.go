@@ -0,0 +1,95 @@
+package gensql
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestGenSQLiteSQL(t *testing.T) {
+	tests := []struct {
+		name    string
+		fields  string
+		options string
+		sql     string
+		err     string
+	}{
+		{
+			name: "columns",
+			fields: `
+				field { name: "id" number: 1 type: TYPE_INT64 label: LABEL_OPTIONAL options { [mySQLColumn] { autoIncrement: true } } }
+				field { name: "gender" number: 2 type: TYPE_ENUM type_name: ".Foo.Gender" label: LABEL_OPTIONAL }
+				field { name: "age" number: 3 type: TYPE_INT32 label: LABEL_OPTIONAL proto3_optional: true }`,
+			options: `primaryKey: ["id"]`,
+			sql: `CREATE TABLE "User" (
+	"id" INTEGER PRIMARY KEY AUTOINCREMENT,
+	"gender" TEXT NOT NULL CHECK ("gender" IN ('MALE','FEMALE')),
+	"age" INTEGER NULL,
+	"PROTO_BINARY" BLOB NOT NULL
+);`,
+		},
+		{
+			name:   "invisible",
+			fields: `field { name: "token" number: 1 type: TYPE_STRING label: LABEL_OPTIONAL options { [mySQLColumn] { invisible: true } } }`,
+			err:    "failed to process field token in message User: INVISIBLE column token is not supported by SQLite",
+		},
+		{
+			name:   "autoincrement without primary key",
+			fields: `field { name: "id" number: 1 type: TYPE_INT64 label: LABEL_OPTIONAL options { [mySQLColumn] { autoIncrement: true } } }`,
+			err:    "failed to process field id in message User: AUTOINCREMENT column id must be the only primary key column of INTEGER",
+		},
+		{
+			name: "autoincrement in composite primary key",
+			fields: `
+				field { name: "id" number: 1 type: TYPE_INT64 label: LABEL_OPTIONAL options { [mySQLColumn] { autoIncrement: true } } }
+				field { name: "tenant_id" number: 2 type: TYPE_INT64 label: LABEL_OPTIONAL }`,
+			options: `primaryKey: ["id", "tenant_id"]`,
+			err:     "AUTOINCREMENT column id must be the only primary key column of INTEGER",
+		},
+		{
+			name:   "semantic of non-string",
+			fields: `field { name: "session" number: 1 type: TYPE_INT64 label: LABEL_OPTIONAL options { [mySQLColumn] { semantic: UUID } } }`,
+			err:    "failed to process field session in message User",
+		},
+		{
+			name:    "multi-valued index",
+			fields:  `field { name: "stamps" number: 1 type: TYPE_INT32 label: LABEL_REPEATED }`,
+			options: `indexes { name: "stamps_idx" columns: ["stamps"] multiValued: true }`,
+			err:     "multi-valued index stamps_idx is not supported by SQLite",
+		},
+		{
+			name:    "foreign key",
+			fields:  `field { name: "parent_id" number: 1 type: TYPE_INT64 label: LABEL_OPTIONAL }`,
+			options: `foreignKeys { columns: ["parent_id"] references: "User" referencedColumns: ["parent_id"] }`,
+			err:     "foreign keys of message User are only generated for MySQL and MariaDB",
+		},
+		{
+			name:    "partition",
+			fields:  `field { name: "tenant_id" number: 1 type: TYPE_INT32 label: LABEL_OPTIONAL }`,
+			options: `partition { type: HASH column: "tenant_id" partitions: 4 }`,
+			err:     "partitioning of message User is not supported by SQLite",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dep, f := parseMessages(t, `
+				message_type { name: "User" `+tt.fields+` options { [mySQLTable] { `+tt.options+` } } }
+				`+genderEnum)
+			sql, err := GenSQL(dep, f, Options{Dialect: DialectSQLite})
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Fatalf("error = %v, want %q", err, tt.err)
+				}
+				if sql != "" {
+					t.Errorf("sql = %q, want none", sql)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if sql != tt.sql {
+				t.Errorf("sql = \n%s\nwant\n%s", sql, tt.sql)
+			}
+		})
+	}
+}
@@ -0,0 +1,92 @@
+package gensql
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/golang/protobuf/protoc-gen-go/descriptor"
+)
+
+// how proto packages are reflected in table names
+type PackageMapping string
+
+const (
+	// package is ignored. Foo.User -> User
+	PackageMappingNone PackageMapping = ""
+	// package is mapped to database. Foo.User -> `foo`.`User`
+	PackageMappingDatabase PackageMapping = "database"
+	// package is prepended to table name. Foo.User -> foo_user
+	PackageMappingPrefix PackageMapping = "prefix"
+)
+
+type TableName struct {
+	Database string
+	Name     string
+}
+
+func (t TableName) String() string {
+	if t.Database == "" {
+		return t.Name
+	}
+	return fmt.Sprintf("`%s`.`%s`", t.Database, t.Name)
+}
+
+func (t TableName) WithSuffix(suffix string) TableName {
+	return TableName{Database: t.Database, Name: t.Name + suffix}
+}
+
+func packageIdent(f *descriptor.FileDescriptorProto) string {
+	return toPackageIdent(f.GetPackage())
+}
+
+func toPackageIdent(pkg string) string {
+	return strings.ToLower(strings.Replace(pkg, ".", "_", -1))
+}
+
+func GetTableName(f *descriptor.FileDescriptorProto, mt *descriptor.DescriptorProto, opts Options) TableName {
+	return messageTableName(f.GetPackage(), mt.GetName(), opts)
+}
+
+// table name of top-level message name in package pkg
+func messageTableName(pkg string, name string, opts Options) TableName {
+	if pkg == "" {
+		return TableName{Name: name}
+	}
+	switch opts.PackageMapping {
+	case PackageMappingDatabase:
+		return TableName{Database: toPackageIdent(pkg), Name: name}
+	case PackageMappingPrefix:
+		return TableName{Name: toPackageIdent(pkg) + "_" + strings.ToLower(name)}
+	default:
+		return TableName{Name: name}
+	}
+}
+
+// tables are compared case-insensitively, as lower_case_table_names differs between servers
+func CheckTableNameCollision(files []*descriptor.FileDescriptorProto, opts Options) error {
+	generated := map[string]string{}
+	add := func(tableName TableName, fullName string) error {
+		key := strings.ToLower(tableName.String())
+		if other, ok := generated[key]; ok {
+			return fmt.Errorf("table %s is generated for both %s and %s", tableName, other, fullName)
+		}
+		generated[key] = fullName
+		return nil
+	}
+
+	for _, f := range files {
+		for _, mt := range f.MessageType {
+			fullName := strings.TrimPrefix(f.GetPackage()+"."+mt.GetName(), ".")
+			tableName := GetTableName(f, mt, opts)
+			if err := add(tableName, fullName); err != nil {
+				return err
+			}
+			if sd, ok := GetSoftDelete(mt); ok && sd.LiveView {
+				if err := add(LiveViewName(tableName), fullName); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,80 @@
+package gensql
+
+import (
+	"testing"
+
+	"github.com/golang/protobuf/protoc-gen-go/descriptor"
+	"google.golang.org/protobuf/encoding/prototext"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestGetTableName(t *testing.T) {
+	tests := []struct {
+		name    string
+		pkg     string
+		mapping PackageMapping
+		table   string
+	}{
+		{name: "none", pkg: "Foo.Bar", table: "User"},
+		{name: "database", pkg: "Foo.Bar", mapping: PackageMappingDatabase, table: "`foo_bar`.`User`"},
+		{name: "prefix", pkg: "Foo.Bar", mapping: PackageMappingPrefix, table: "foo_bar_user"},
+		{name: "database without package", mapping: PackageMappingDatabase, table: "User"},
+		{name: "prefix without package", mapping: PackageMappingPrefix, table: "User"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := &descriptor.FileDescriptorProto{Package: &tt.pkg}
+			mt := &descriptor.DescriptorProto{Name: proto.String("User")}
+			if table := GetTableName(f, mt, Options{PackageMapping: tt.mapping}); table.String() != tt.table {
+				t.Errorf("table = %s, want %s", table, tt.table)
+			}
+		})
+	}
+}
+
+func TestCheckTableNameCollision(t *testing.T) {
+	tests := []struct {
+		name    string
+		files   []string
+		mapping PackageMapping
+		err     string
+	}{
+		{
+			name:  "same message name in other packages",
+			files: []string{`package: "a" message_type { name: "User" }`, `package: "b" message_type { name: "User" }`},
+			err:   "table User is generated for both a.User and b.User",
+		},
+		{
+			name:    "same message name in other databases",
+			files:   []string{`package: "a" message_type { name: "User" }`, `package: "b" message_type { name: "User" }`},
+			mapping: PackageMappingDatabase,
+		},
+		{
+			name:    "prefixed names differ only in case",
+			files:   []string{`package: "a" message_type { name: "User" } message_type { name: "USER" }`},
+			mapping: PackageMappingPrefix,
+			err:     "table a_user is generated for both a.User and a.USER",
+		},
+		{
+			name:  "live view",
+			files: []string{`message_type { name: "User" options { [mySQLTable] { softDelete { liveView: true } } } } message_type { name: "User_live" }`},
+			err:   "table User_live is generated for both User and User_live",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			files := []*descriptor.FileDescriptorProto{}
+			for _, text := range tt.files {
+				f := &descriptor.FileDescriptorProto{}
+				if err := prototext.Unmarshal([]byte(text), f); err != nil {
+					t.Fatal(err)
+				}
+				files = append(files, f)
+			}
+			err := CheckTableNameCollision(files, Options{PackageMapping: tt.mapping})
+			if (err == nil) != (tt.err == "") || (err != nil && err.Error() != tt.err) {
+				t.Errorf("err = %v, want %q", err, tt.err)
+			}
+		})
+	}
+}
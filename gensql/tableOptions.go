@@ -0,0 +1,112 @@
+package gensql
+
+import (
+	"fmt"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/golang/protobuf/protoc-gen-go/descriptor"
+)
+
+const (
+	defaultCreatedAtColumn  = "created_at"
+	defaultUpdatedAtColumn  = "updated_at"
+	defaultRowVersionColumn = "row_version"
+)
+
+func CheckTableOptions(mt *descriptor.DescriptorProto) (*MySQLTable, bool) {
+	opts := mt.GetOptions()
+	if opts == nil {
+		return nil, false
+	}
+
+	ext, err := proto.GetExtension(opts, E_MySQLTable)
+	if err != nil {
+		return nil, false
+	}
+	return ext.(*MySQLTable), true
+}
+
+type AuditColumns struct {
+	CreatedAt  string
+	UpdatedAt  string
+	RowVersion string
+}
+
+// audit columns have no matching proto field.
+// helpers must not include them in INSERT tuples.
+func GetAuditColumns(mt *descriptor.DescriptorProto) (AuditColumns, bool) {
+	t, ok := CheckTableOptions(mt)
+	if !ok || t.GetAuditColumns() == nil {
+		return AuditColumns{}, false
+	}
+	a := t.GetAuditColumns()
+	ret := AuditColumns{
+		CreatedAt:  a.GetCreatedAt(),
+		UpdatedAt:  a.GetUpdatedAt(),
+		RowVersion: a.GetRowVersion(),
+	}
+	if ret.CreatedAt == "" {
+		ret.CreatedAt = defaultCreatedAtColumn
+	}
+	if ret.UpdatedAt == "" {
+		ret.UpdatedAt = defaultUpdatedAtColumn
+	}
+	if ret.RowVersion == "" {
+		ret.RowVersion = defaultRowVersionColumn
+	}
+	return ret, true
+}
+
+func (a AuditColumns) Names() []string {
+	return []string{a.CreatedAt, a.UpdatedAt, a.RowVersion}
+}
+
+func auditColumns(a AuditColumns) []*Column {
+	datetime := MySQLDataTypeWithArgs{DATETIME, []string{"6"}}
+	return []*Column{
+		{Name: a.CreatedAt, Type: datetime, Default: "CURRENT_TIMESTAMP(6)"},
+		{Name: a.UpdatedAt, Type: datetime, Default: "CURRENT_TIMESTAMP(6)", OnUpdate: "CURRENT_TIMESTAMP(6)"},
+		{Name: a.RowVersion, Type: MySQLDataTypeWithArgs{UBIGINT, nil}, Default: "0"},
+	}
+}
+
+func buildIndex(mt *descriptor.DescriptorProto, index *MySQLIndex, softDelete bool, d Dialect) (*Index, error) {
+	if len(index.GetColumns()) == 0 {
+		return nil, fmt.Errorf("index %s has no columns", index.GetName())
+	}
+	columns := index.GetColumns()
+	if index.GetMultiValued() {
+		if d == DialectMariaDB {
+			return nil, fmt.Errorf("multi-valued index %s is not supported by MariaDB", index.GetName())
+		}
+		field, castType, err := getMultiValuedField(mt, index)
+		if err != nil {
+			return nil, err
+		}
+		columns = []string{fmt.Sprintf("(CAST(%s->'$' AS %s ARRAY))", field.GetName(), castType)}
+	}
+	if index.GetIncludeSoftDelete() {
+		if !softDelete {
+			return nil, fmt.Errorf("index %s includes soft-delete column but softDelete is not set", index.GetName())
+		}
+		columns = append(append([]string{}, columns...), SoftDeleteMarkerColumn)
+	}
+	return &Index{Name: index.GetName(), Columns: columns, Unique: index.GetUnique()}, nil
+}
+
+// columns referred by primary key, indexes and foreign keys must be generated in the table
+func checkKeyColumns(mt *descriptor.DescriptorProto, t *MySQLTable, columns map[string]bool) error {
+	keys := append([]string{}, t.GetPrimaryKey()...)
+	for _, index := range t.GetIndexes() {
+		keys = append(keys, index.GetColumns()...)
+	}
+	for _, fk := range t.GetForeignKeys() {
+		keys = append(keys, fk.GetColumns()...)
+	}
+	for _, key := range keys {
+		if !columns[key] {
+			return fmt.Errorf("column %s is not found in message %s", key, mt.GetName())
+		}
+	}
+	return nil
+}
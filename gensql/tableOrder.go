@@ -0,0 +1,111 @@
+package gensql
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/golang/glog"
+)
+
+// sort tables so that referenced tables are created first. ties keep the given order.
+// a reference cycle is broken at its first table, which is returned as cyclic.
+func sortTables(tables []*Table) ([]*Table, []TableName) {
+	byKey := map[string]*Table{}
+	for _, t := range tables {
+		byKey[tableKey(t.Name)] = t
+	}
+	created := map[string]bool{}
+	// tables not created yet which t references.
+	// self references and tables outside the list do not constrain the order
+	pending := func(t *Table) []*Table {
+		ret := []*Table{}
+		for _, fk := range t.ForeignKeys {
+			ref := tableKey(fk.ReferencedTable)
+			if r, ok := byKey[ref]; ok && ref != tableKey(t.Name) && !created[ref] {
+				ret = append(ret, r)
+			}
+		}
+		return ret
+	}
+	onCycle := func(t *Table) bool {
+		visited := map[*Table]bool{}
+		stack := pending(t)
+		for len(stack) > 0 {
+			r := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			if r == t {
+				return true
+			}
+			if !visited[r] {
+				visited[r] = true
+				stack = append(stack, pending(r)...)
+			}
+		}
+		return false
+	}
+
+	sorted := make([]*Table, 0, len(tables))
+	cyclic := []TableName{}
+	rest := append([]*Table{}, tables...)
+	for len(rest) > 0 {
+		i := 0
+		for i < len(rest) && len(pending(rest[i])) > 0 {
+			i++
+		}
+		if i == len(rest) {
+			for i = 0; !onCycle(rest[i]); i++ {
+			}
+			cyclic = append(cyclic, rest[i].Name)
+		}
+		sorted = append(sorted, rest[i])
+		created[tableKey(rest[i].Name)] = true
+		rest = append(rest[:i], rest[i+1:]...)
+	}
+	return sorted, cyclic
+}
+
+// order tables of all schemas by foreign key references across the schemas.
+// each schema keeps its tables, in the order of the whole.
+// schemas are run in the given order, so a table referencing a table of a later schema is an error
+// unless opts.ForeignKeyChecksGuard
+func OrderTables(schemas []*Schema, opts Options) error {
+	all := []*Table{}
+	for _, s := range schemas {
+		all = append(all, s.Tables...)
+	}
+	sorted, cyclic := sortTables(all)
+	if len(cyclic) > 0 && !opts.ForeignKeyChecksGuard {
+		names := make([]string, 0, len(cyclic))
+		for _, name := range cyclic {
+			names = append(names, name.String())
+		}
+		glog.Warningf("foreign keys form a cycle, so %s is created before the referenced tables. use fk_checks_guard", strings.Join(names, ","))
+	}
+
+	owner := map[*Table]int{}
+	index := map[string]int{}
+	for i, s := range schemas {
+		for _, t := range s.Tables {
+			owner[t] = i
+			index[tableKey(t.Name)] = i
+		}
+	}
+	if !opts.ForeignKeyChecksGuard {
+		for _, t := range all {
+			for _, fk := range t.ForeignKeys {
+				if i, ok := index[tableKey(fk.ReferencedTable)]; ok && i > owner[t] {
+					return fmt.Errorf("table %s references %s, which is created by a later file. generate the file of %s first, or use fk_checks_guard", t.Name, fk.ReferencedTable, fk.ReferencedTable)
+				}
+			}
+		}
+	}
+
+	for _, s := range schemas {
+		s.Tables = s.Tables[:0:0]
+	}
+	for _, t := range sorted {
+		s := schemas[owner[t]]
+		s.Tables = append(s.Tables, t)
+	}
+	return nil
+}
@@ -0,0 +1,109 @@
+package gensql
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// tables written as "A>B,C" reference B and C
+func tablesOf(defs string) []*Table {
+	tables := []*Table{}
+	for _, def := range strings.Fields(defs) {
+		parts := strings.SplitN(def, ">", 2)
+		t := &Table{Name: TableName{Name: parts[0]}}
+		if len(parts) == 2 {
+			for _, ref := range strings.Split(parts[1], ",") {
+				t.ForeignKeys = append(t.ForeignKeys, &ForeignKey{ReferencedTable: TableName{Name: ref}})
+			}
+		}
+		tables = append(tables, t)
+	}
+	return tables
+}
+
+func names(tables []TableName) []string {
+	ret := []string{}
+	for _, t := range tables {
+		ret = append(ret, t.Name)
+	}
+	return ret
+}
+
+func TestSortTables(t *testing.T) {
+	tests := []struct {
+		name   string
+		tables string
+		sorted []string
+		cyclic []string
+	}{
+		{name: "no references", tables: "A B C", sorted: []string{"A", "B", "C"}, cyclic: []string{}},
+		{name: "referenced later", tables: "Order>Customer Customer", sorted: []string{"Customer", "Order"}, cyclic: []string{}},
+		{name: "chain", tables: "A>B B>C C", sorted: []string{"C", "B", "A"}, cyclic: []string{}},
+		{name: "ties keep the order", tables: "X A>C B C", sorted: []string{"X", "B", "C", "A"}, cyclic: []string{}},
+		{name: "self reference", tables: "Node>Node", sorted: []string{"Node"}, cyclic: []string{}},
+		{name: "table outside the list", tables: "A>Other", sorted: []string{"A"}, cyclic: []string{}},
+		{name: "names are case-insensitive", tables: "a>B b", sorted: []string{"b", "a"}, cyclic: []string{}},
+		{name: "cycle", tables: "A>B B>A C>A", sorted: []string{"A", "B", "C"}, cyclic: []string{"A"}},
+		{name: "cycle after a table", tables: "D A>B B>C C>A", sorted: []string{"D", "A", "C", "B"}, cyclic: []string{"A"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sorted, cyclic := sortTables(tablesOf(tt.tables))
+			got := []string{}
+			for _, s := range sorted {
+				got = append(got, s.Name.Name)
+			}
+			if !reflect.DeepEqual(got, tt.sorted) {
+				t.Errorf("sorted = %v, want %v", got, tt.sorted)
+			}
+			if got := names(cyclic); !reflect.DeepEqual(got, tt.cyclic) {
+				t.Errorf("cyclic = %v, want %v", got, tt.cyclic)
+			}
+		})
+	}
+}
+
+func TestOrderTables(t *testing.T) {
+	tests := []struct {
+		name   string
+		files  []string
+		opts   Options
+		tables [][]string
+		err    string
+	}{
+		{name: "referenced table in the file", files: []string{"Order>Customer Customer"}, tables: [][]string{{"Customer", "Order"}}},
+		{name: "referenced table in an earlier file", files: []string{"Customer", "Order>Customer Item"}, tables: [][]string{{"Customer"}, {"Order", "Item"}}},
+		{name: "referenced table in a later file", files: []string{"Order>Customer Item", "Customer"}, err: "table Order references Customer, which is created by a later file"},
+		{name: "later file with fk_checks_guard", files: []string{"Order>Customer Item", "Customer"}, opts: Options{ForeignKeyChecksGuard: true}, tables: [][]string{{"Item", "Order"}, {"Customer"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			schemas := []*Schema{}
+			for _, f := range tt.files {
+				schemas = append(schemas, &Schema{Tables: tablesOf(f)})
+			}
+			err := OrderTables(schemas, tt.opts)
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Fatalf("error = %v, want %q", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := [][]string{}
+			for _, s := range schemas {
+				ns := []string{}
+				for _, t := range s.Tables {
+					ns = append(ns, t.Name.Name)
+				}
+				got = append(got, ns)
+			}
+			if !reflect.DeepEqual(got, tt.tables) {
+				t.Errorf("tables = %v, want %v", got, tt.tables)
+			}
+		})
+	}
+}
@@ -0,0 +1,168 @@
+package gensql
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/Mojashi/proto-mysql/dep"
+	"github.com/golang/glog"
+	"github.com/golang/protobuf/protoc-gen-go/descriptor"
+)
+
+// latest release of the series. "8.0" is read as the latest 8.0.x
+const latestRelease = 1 << 16
+
+type MySQLVersion struct {
+	Major, Minor, Patch int
+}
+
+// accepts "5.7", "8.0.16", "8.4", "9.x"
+func ParseMySQLVersion(s string) (MySQLVersion, error) {
+	terms := strings.Split(s, ".")
+	if len(terms) < 2 || len(terms) > 3 {
+		return MySQLVersion{}, fmt.Errorf("invalid mysql version %s", s)
+	}
+	v := []int{latestRelease, latestRelease, latestRelease}
+	for i, term := range terms {
+		if term == "x" && i > 0 {
+			continue
+		}
+		n, err := strconv.Atoi(term)
+		if err != nil {
+			return MySQLVersion{}, fmt.Errorf("invalid mysql version %s", s)
+		}
+		v[i] = n
+	}
+	ret := MySQLVersion{Major: v[0], Minor: v[1], Patch: v[2]}
+	if !ret.AtLeast(MySQLVersion{5, 7, 0}) {
+		return MySQLVersion{}, fmt.Errorf("mysql version %s is not supported. 5.7 or later is required", s)
+	}
+	return ret, nil
+}
+
+// zero value means that no version is targeted
+func (v MySQLVersion) IsZero() bool {
+	return v == MySQLVersion{}
+}
+
+func (v MySQLVersion) AtLeast(o MySQLVersion) bool {
+	if v.Major != o.Major {
+		return v.Major > o.Major
+	}
+	if v.Minor != o.Minor {
+		return v.Minor > o.Minor
+	}
+	return v.Patch >= o.Patch
+}
+
+func (v MySQLVersion) String() string {
+	terms := []string{}
+	for _, n := range []int{v.Major, v.Minor, v.Patch} {
+		if n == latestRelease {
+			terms = append(terms, "x")
+		} else {
+			terms = append(terms, strconv.Itoa(n))
+		}
+	}
+	return strings.TrimSuffix(strings.Join(terms, "."), ".x")
+}
+
+type mySQLFeature struct {
+	name  string
+	since MySQLVersion
+}
+
+var (
+	featureJSON              = mySQLFeature{"JSON type", MySQLVersion{5, 7, 8}}
+	featureRenameColumn      = mySQLFeature{"RENAME COLUMN", MySQLVersion{8, 0, 0}}
+	featureExpressionDefault = mySQLFeature{"expression default", MySQLVersion{8, 0, 13}}
+	featureCheckConstraint   = mySQLFeature{"CHECK constraint", MySQLVersion{8, 0, 16}}
+	featureMultiValuedIndex  = mySQLFeature{"multi-valued index", MySQLVersion{8, 0, 17}}
+	featureInvisibleColumn   = mySQLFeature{"INVISIBLE column", MySQLVersion{8, 0, 23}}
+	// adding virtual columns, changing defaults and appending ENUM values
+	featureInstantDDL          = mySQLFeature{"ALGORITHM=INSTANT", MySQLVersion{8, 0, 12}}
+	featureInstantRenameColumn = mySQLFeature{"instant RENAME COLUMN", MySQLVersion{8, 0, 28}}
+	// adding columns at any position and dropping columns
+	featureInstantColumnPosition = mySQLFeature{"instant ADD COLUMN at any position", MySQLVersion{8, 0, 29}}
+	featureVector                = mySQLFeature{"VECTOR type", MySQLVersion{9, 0, 0}}
+)
+
+// features are available unless a version is targeted
+func (o Options) supports(feature mySQLFeature) bool {
+	if o.Dialect != DialectMySQL && o.Dialect != "" {
+		return true
+	}
+	return o.MySQLVersion.IsZero() || o.MySQLVersion.AtLeast(feature.since)
+}
+
+func (o Options) requires(feature mySQLFeature, where string) error {
+	if o.supports(feature) {
+		return nil
+	}
+	return fmt.Errorf("%s of %s requires MySQL %s, but target is %s", feature.name, where, feature.since, o.MySQLVersion)
+}
+
+func defaultCollation(v MySQLVersion) string {
+	if v.AtLeast(MySQLVersion{8, 0, 0}) {
+		return "utf8mb4_0900_ai_ci"
+	}
+	return "utf8mb4_general_ci"
+}
+
+// caveats of features the target version accepts but ignores.
+// CHECK constraints before 8.0.16 are parsed but not enforced
+func mySQLVersionWarnings(mt *descriptor.DescriptorProto, opts Options) []string {
+	ret := []string{}
+	for _, field := range mt.Field {
+		c, _ := CheckColumnOptions(field)
+		if c.GetCheck() != "" && !opts.supports(featureCheckConstraint) {
+			ret = append(ret, fmt.Sprintf("CHECK constraint of %s.%s is not enforced before MySQL %s", mt.GetName(), field.GetName(), featureCheckConstraint.since))
+		}
+	}
+	return ret
+}
+
+// return error for features the target version cannot express, and log the warnings of mySQLVersionWarnings
+func checkMySQLVersion(dep dep.INameSpace, mt *descriptor.DescriptorProto, opts Options) error {
+	for _, field := range mt.Field {
+		where := fmt.Sprintf("%s.%s", mt.GetName(), field.GetName())
+		if t, err := GenMySQLDataType(dep, field); err == nil {
+			switch strings.ToUpper(string(t.GetType())) {
+			case string(JSON):
+				if err := opts.requires(featureJSON, where); err != nil {
+					return err
+				}
+			case "VECTOR":
+				if err := opts.requires(featureVector, where); err != nil {
+					return err
+				}
+			}
+		}
+
+		c, _ := CheckColumnOptions(field)
+		if c.GetDefaultExpression() != "" {
+			if err := opts.requires(featureExpressionDefault, where); err != nil {
+				return err
+			}
+		}
+		if c.GetInvisible() {
+			if err := opts.requires(featureInvisibleColumn, where); err != nil {
+				return err
+			}
+		}
+	}
+
+	tableOpts, _ := CheckTableOptions(mt)
+	for _, index := range tableOpts.GetIndexes() {
+		if index.GetMultiValued() {
+			if err := opts.requires(featureMultiValuedIndex, fmt.Sprintf("%s.%s", mt.GetName(), index.GetName())); err != nil {
+				return err
+			}
+		}
+	}
+	for _, w := range mySQLVersionWarnings(mt, opts) {
+		glog.Warning(w)
+	}
+	return nil
+}
@@ -0,0 +1,99 @@
+package gensql
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseMySQLVersion(t *testing.T) {
+	tests := []struct {
+		in  string
+		out MySQLVersion
+		err bool
+	}{
+		{in: "8.0.16", out: MySQLVersion{8, 0, 16}},
+		{in: "8.0", out: MySQLVersion{8, 0, latestRelease}},
+		{in: "9.x", out: MySQLVersion{9, latestRelease, latestRelease}},
+		{in: "5.6", err: true},
+		{in: "8", err: true},
+		{in: "8.a", err: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			v, err := ParseMySQLVersion(tt.in)
+			if (err != nil) != tt.err {
+				t.Fatalf("error = %v, want error %v", err, tt.err)
+			}
+			if v != tt.out {
+				t.Errorf("version = %v, want %v", v, tt.out)
+			}
+		})
+	}
+}
+
+func TestBuildSchemaVersionFeatures(t *testing.T) {
+	const id = `field { name: "id" number: 1 type: TYPE_INT64 label: LABEL_OPTIONAL }`
+	multiValued := `
+		field { name: "stamps" number: 1 type: TYPE_INT32 label: LABEL_REPEATED }
+		options { [mySQLTable] { indexes { name: "stamps_idx" columns: ["stamps"] multiValued: true } } }`
+	invisible := `
+		field { name: "token" number: 1 type: TYPE_STRING label: LABEL_OPTIONAL options { [mySQLColumn] { invisible: true } } }`
+	check := `
+		field { name: "age" number: 1 type: TYPE_INT32 label: LABEL_OPTIONAL options { [mySQLColumn] { check: "age >= 0" } } }`
+	vector := `
+		field { name: "embedding" number: 1 type: TYPE_BYTES label: LABEL_OPTIONAL options { [mySQLType] { typeName: "VECTOR" args: ["3"] } } }`
+	tests := []struct {
+		name      string
+		message   string
+		dialect   Dialect
+		version   string
+		err       string
+		warnings  []string
+		collation string
+	}{
+		{name: "multi-valued index without version", message: multiValued},
+		{name: "multi-valued index on 8.0.17", message: multiValued, version: "8.0.17", collation: "utf8mb4_0900_ai_ci"},
+		{name: "multi-valued index on 8.0.16", message: multiValued, version: "8.0.16", err: "multi-valued index of User.stamps_idx requires MySQL 8.0.17, but target is 8.0.16"},
+		{name: "multi-valued index on MariaDB", message: multiValued, dialect: DialectMariaDB, err: "multi-valued index stamps_idx is not supported by MariaDB"},
+		{name: "invisible on 8.0.23", message: invisible, version: "8.0.23", collation: "utf8mb4_0900_ai_ci"},
+		{name: "invisible on 5.7", message: invisible, version: "5.7", err: "INVISIBLE column of User.token requires MySQL 8.0.23, but target is 5.7"},
+		{name: "check without version", message: check},
+		{name: "check on 8.0.16", message: check, version: "8.0.16", collation: "utf8mb4_0900_ai_ci"},
+		{name: "check on 8.0.15", message: check, version: "8.0.15", collation: "utf8mb4_0900_ai_ci", warnings: []string{"CHECK constraint of User.age is not enforced before MySQL 8.0.16"}},
+		{name: "check on 5.7", message: check, version: "5.7", collation: "utf8mb4_general_ci", warnings: []string{"CHECK constraint of User.age is not enforced before MySQL 8.0.16"}},
+		{name: "vector on 9.0", message: vector, version: "9.0", collation: "utf8mb4_0900_ai_ci"},
+		{name: "vector on 8.4", message: vector, version: "8.4", err: "VECTOR type of User.embedding requires MySQL 9.0.0, but target is 8.4"},
+		{name: "collation on 5.7", message: id, version: "5.7", collation: "utf8mb4_general_ci"},
+		{name: "collation on 8.0", message: id, version: "8.0", collation: "utf8mb4_0900_ai_ci"},
+		{name: "collation on 9.x", message: id, version: "9.x", collation: "utf8mb4_0900_ai_ci"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dep, f := parseMessages(t, `message_type { name: "User" `+tt.message+` }`)
+			opts := Options{Dialect: tt.dialect}
+			if tt.version != "" {
+				var err error
+				if opts.MySQLVersion, err = ParseMySQLVersion(tt.version); err != nil {
+					t.Fatal(err)
+				}
+			}
+			s, err := BuildSchema(dep, f, opts)
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Fatalf("error = %v, want %q", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if warnings := mySQLVersionWarnings(f.MessageType[0], opts); !reflect.DeepEqual(warnings, append([]string{}, tt.warnings...)) {
+				t.Errorf("warnings = %q, want %q", warnings, tt.warnings)
+			}
+			if table := s.Tables[0]; table.Collation != tt.collation {
+				t.Errorf("collation = %q, want %q", table.Collation, tt.collation)
+			}
+		})
+	}
+}
@@ -0,0 +1,356 @@
+package helper
+
+import (
+	"fmt"
+	"go/format"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/Mojashi/proto-mysql/dep"
+	"github.com/Mojashi/proto-mysql/gensql"
+	"github.com/golang/glog"
+	"github.com/golang/protobuf/protoc-gen-go/descriptor"
+	plugin "github.com/golang/protobuf/protoc-gen-go/plugin"
+	"google.golang.org/protobuf/proto"
+)
+
+func isASCIILower(c byte) bool {
+	return 'a' <= c && c <= 'z'
+}
+
+func isASCIIDigit(c byte) bool {
+	return '0' <= c && c <= '9'
+}
+
+// Go name of proto identifier, in the same way as protoc-gen-go. e.g. page_number -> PageNumber
+func goCamelCase(s string) string {
+	var b []byte
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c == '.' && i+1 < len(s) && isASCIILower(s[i+1]):
+		case c == '.':
+			b = append(b, '_')
+		case c == '_' && (i == 0 || s[i-1] == '.'):
+			b = append(b, 'X')
+		case c == '_' && i+1 < len(s) && isASCIILower(s[i+1]):
+		case isASCIIDigit(c):
+			b = append(b, c)
+		default:
+			if isASCIILower(c) {
+				c -= 'a' - 'A'
+			}
+			b = append(b, c)
+			for ; i+1 < len(s) && isASCIILower(s[i+1]); i++ {
+				b = append(b, s[i+1])
+			}
+		}
+	}
+	return string(b)
+}
+
+// import path and package name of go_package. e.g. "example.com/foo/foopb;foopb"
+func goPackage(f *descriptor.FileDescriptorProto) (importPath string, name string, ok bool) {
+	goPackage := f.GetOptions().GetGoPackage()
+	if goPackage == "" {
+		return "", "", false
+	}
+	importPath = goPackage
+	if i := strings.Index(goPackage, ";"); i >= 0 {
+		importPath, name = goPackage[:i], goPackage[i+1:]
+	}
+	if name == "" {
+		name = path.Base(importPath)
+	}
+	name = strings.Map(func(r rune) rune {
+		if r == '_' || 'a' <= r && r <= 'z' || 'A' <= r && r <= 'Z' || '0' <= r && r <= '9' {
+			return r
+		}
+		return '_'
+	}, name)
+	return importPath, name, true
+}
+
+// placeholders of database/sql drivers. the index starts at 1
+func goPlaceholder(d gensql.Dialect, index int) string {
+	if d == gensql.DialectPostgreSQL {
+		return "$" + strconv.Itoa(index)
+	}
+	return "?"
+}
+
+func genGoInsertSQL(tableName gensql.TableName, columnNames []string, opts gensql.Options) string {
+	placeholders := make([]string, 0, len(columnNames))
+	for i := range columnNames {
+		placeholders = append(placeholders, goPlaceholder(opts.Dialect, i+1))
+	}
+	return fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s)",
+		tableName.Format(opts.Dialect), strings.Join(quoteIdents(opts.Dialect, columnNames), ","), strings.Join(placeholders, ","))
+}
+
+func genGoElem(dep dep.INameSpace, fdesc *descriptor.FieldDescriptorProto, prefix string) string {
+	getter := "m.Get" + goCamelCase(fdesc.GetName()) + "()"
+	elem := getter
+	switch gensql.GetConversion(dep, fdesc) {
+	case gensql.ConversionJSONArray:
+		elem = fmt.Sprintf("%sJSONArray(%s)", prefix, getter)
+	case gensql.ConversionJSONMessageArray:
+		elem = fmt.Sprintf("%sJSONMessageArray(%s)", prefix, getter)
+	case gensql.ConversionJSONMessage:
+		elem = fmt.Sprintf("%sJSONMessage(%s)", prefix, getter)
+	case gensql.ConversionJSONMap:
+		elem = fmt.Sprintf("%sJSONMap(m, %s)", prefix, strconv.Quote(fdesc.GetName()))
+	case gensql.ConversionEnumName:
+		elem = fmt.Sprintf("%sEnumName(%s)", prefix, getter)
+	}
+
+	if fdesc.GetProto3Optional() {
+		elem = fmt.Sprintf("%sNullable(m.%s != nil, %s)", prefix, goCamelCase(fdesc.GetName()), elem)
+	}
+	return elem
+}
+
+func goStrings(values []string) string {
+	quoted := make([]string, 0, len(values))
+	for _, v := range values {
+		quoted = append(quoted, strconv.Quote(v))
+	}
+	return strings.Join(quoted, ", ")
+}
+
+func genGoMethods(dep dep.INameSpace, f *descriptor.FileDescriptorProto, mdesc *descriptor.DescriptorProto, pbName string, prefix string, opts gensql.Options) string {
+	name := goCamelCase(mdesc.GetName())
+	tableName := gensql.GetTableName(f, mdesc, opts)
+	elems := []string{}
+	columnNames := []string{}
+	fieldElems := map[string]string{}
+
+	for _, fdesc := range mdesc.Field {
+		elem := genGoElem(dep, fdesc, prefix)
+		fieldElems[fdesc.GetName()] = elem
+		// filled by the database
+		if gensql.IsAutoIncrement(fdesc) {
+			continue
+		}
+		columnNames = append(columnNames, fdesc.GetName())
+		elems = append(elems, elem)
+	}
+	columnNames = append(columnNames, gensql.ProtoBinaryColumn)
+	elems = append(elems, prefix+"ProtoBinary(m)")
+
+	auditColumns := []string{}
+	if audit, ok := gensql.GetAuditColumns(mdesc); ok {
+		auditColumns = audit.Names()
+	}
+
+	ret := fmt.Sprintf(`
+const %[1]sTableName = %[2]s
+
+// columns of %[1]sToRow. auto-increment and audit columns are filled by the database
+var %[1]sColumnNames = []string{%[3]s}
+
+var %[1]sAuditColumnNames = []string{%[4]s}
+
+const %[1]sInsertSQL = %[5]s
+
+// values of %[1]sColumnNames. it panics when m cannot be marshaled, e.g. a string is not valid UTF-8,
+// or when an enum field holds a number the enum does not declare
+func %[1]sToRow(m *%[6]s.%[1]s) []any {
+	return []any{
+		%[7]s,
+	}
+}
+`, name, strconv.Quote(tableName.Format(opts.Dialect)), goStrings(columnNames), goStrings(auditColumns),
+		strconv.Quote(genGoInsertSQL(tableName, columnNames, opts)), pbName, strings.Join(elems, ",\n\t\t"))
+
+	if sd, ok := gensql.GetSoftDelete(mdesc); ok {
+		ret += genGoSoftDeleteMethods(mdesc, name, tableName, sd, fieldElems, pbName, prefix, opts)
+	}
+	if opts.Fingerprint {
+		fingerprint, err := gensql.MessageFingerprint(dep, mdesc)
+		if err != nil {
+			glog.Error(err)
+		} else {
+			ret += fmt.Sprintf(`
+// fingerprint of the message descriptor, which the database records in %[2]s
+const %[1]sSchemaFingerprint = %[3]s
+
+const %[1]sSchemaFingerprintSQL = %[4]s
+`, name, gensql.FingerprintTable, strconv.Quote(fingerprint), strconv.Quote(gensql.FingerprintSelectSQL(tableName)))
+		}
+	}
+	return ret
+}
+
+// rows are identified by primary key, or by PROTO_BINARY if the table has none.
+// PROTO_BINARY is also used when a key column is not converted from a field, e.g. a generated column.
+func genGoSoftDeleteMethods(mdesc *descriptor.DescriptorProto, name string, tableName gensql.TableName, sd gensql.SoftDelete, fieldElems map[string]string, pbName string, prefix string, opts gensql.Options) string {
+	q := func(name string) string { return gensql.QuoteIdent(opts.Dialect, name) }
+	conds := []string{}
+	keyElems := []string{}
+
+	tableOpts, _ := gensql.CheckTableOptions(mdesc)
+	for i, column := range tableOpts.GetPrimaryKey() {
+		elem, ok := fieldElems[column]
+		if !ok {
+			glog.Warningf("primary key column %s of %s is not a field, so soft-deleted rows are identified by PROTO_BINARY", column, mdesc.GetName())
+			conds, keyElems = nil, nil
+			break
+		}
+		conds = append(conds, q(column)+" = "+goPlaceholder(opts.Dialect, i+1))
+		keyElems = append(keyElems, elem)
+	}
+	if len(conds) == 0 {
+		conds = append(conds, q(gensql.ProtoBinaryColumn)+" = "+goPlaceholder(opts.Dialect, 1))
+		keyElems = append(keyElems, prefix+"ProtoBinary(m)")
+	}
+	where := strings.Join(conds, " AND ")
+
+	softDeleteSQL := fmt.Sprintf("UPDATE %[1]s SET %[2]s = %[4]s WHERE %[3]s AND %[2]s IS NULL",
+		tableName.Format(opts.Dialect), q(sd.Column), where, gensql.CurrentTimestamp(opts.Dialect))
+	restoreSQL := fmt.Sprintf("UPDATE %[1]s SET %[2]s = NULL WHERE %[3]s AND %[2]s IS NOT NULL",
+		tableName.Format(opts.Dialect), q(sd.Column), where)
+
+	return fmt.Sprintf(`
+// key values for %[1]sSoftDeleteSQL and %[1]sRestoreSQL
+func %[1]sToKey(m *%[2]s.%[1]s) []any {
+	return []any{%[3]s}
+}
+
+// UPDATE statement which marks the row as deleted instead of DELETE
+const %[1]sSoftDeleteSQL = %[4]s
+
+const %[1]sRestoreSQL = %[5]s
+`, name, pbName, strings.Join(keyElems, ", "), strconv.Quote(softDeleteSQL), strconv.Quote(restoreSQL))
+}
+
+// conversions shared by the messages of a file. %[1]s is the prefix, as files of a package define their own
+const goHelperFuncs = `
+func %[1]sNullable(valid bool, v any) any {
+	if !valid {
+		return nil
+	}
+	return v
+}
+
+// name of the enum value. unknown numbers panic, as the ENUM column cannot hold them
+func %[1]sEnumName(v protoreflect.Enum) string {
+	d := v.Descriptor().Values().ByNumber(v.Number())
+	if d == nil {
+		panic(fmt.Sprintf("unknown value %%d of enum %%s", v.Number(), v.Descriptor().FullName()))
+	}
+	return string(d.Name())
+}
+
+func %[1]sJSONArray(v any) []byte {
+	buf, err := json.Marshal(v)
+	if err != nil {
+		panic(err)
+	}
+	// nil slice is an empty array, as the column is NOT NULL
+	if string(buf) == "null" {
+		return []byte("[]")
+	}
+	return buf
+}
+
+func %[1]sJSONMessage(m proto.Message) []byte {
+	buf, err := protojson.Marshal(m)
+	if err != nil {
+		panic(err)
+	}
+	return buf
+}
+
+func %[1]sJSONMessageArray[T proto.Message](ms []T) []byte {
+	elems := make([][]byte, 0, len(ms))
+	for _, m := range ms {
+		elems = append(elems, %[1]sJSONMessage(m))
+	}
+	return append(append([]byte("["), bytes.Join(elems, []byte(","))...), ']')
+}
+
+// JSON object of the map field, as protojson writes it in m
+func %[1]sJSONMap(m proto.Message, name protoreflect.Name) []byte {
+	r := m.ProtoReflect()
+	fd := r.Descriptor().Fields().ByName(name)
+	if !r.Has(fd) {
+		return []byte("{}")
+	}
+	only := r.New()
+	only.Set(fd, r.Get(fd))
+	var obj map[string]json.RawMessage
+	if err := json.Unmarshal(%[1]sJSONMessage(only.Interface()), &obj); err != nil {
+		panic(err)
+	}
+	return obj[fd.JSONName()]
+}
+
+func %[1]sProtoBinary(m proto.Message) []byte {
+	buf, err := proto.Marshal(m)
+	if err != nil {
+		panic(err)
+	}
+	return buf
+}
+`
+
+// unexported identifier of the file. e.g. user_service.proto -> userService
+func goFileIdent(f *descriptor.FileDescriptorProto) string {
+	name := goCamelCase(strings.TrimSuffix(path.Base(f.GetName()), ".proto"))
+	if name == "" {
+		return "file"
+	}
+	return strings.ToLower(name[:1]) + name[1:]
+}
+
+// the file imports the protobuf Go package of go_package, so it is written to <package>sql next to the proto file.
+// ClickHouse is not supported, as its drivers take nested messages as tuples
+func genGoHelper(dep dep.INameSpace, f *descriptor.FileDescriptorProto, opts gensql.Options) []*plugin.CodeGeneratorResponse_File {
+	if opts.Dialect == gensql.DialectClickHouse {
+		glog.Errorf("go helper is not available for dialect %s", opts.Dialect)
+		return nil
+	}
+	importPath, pbName, ok := goPackage(f)
+	if !ok {
+		glog.Errorf("go helper of %s requires go_package", f.GetName())
+		return nil
+	}
+
+	prefix := goFileIdent(f)
+	methods := []string{}
+	for _, mdesc := range f.MessageType {
+		methods = append(methods, genGoMethods(dep, f, mdesc, pbName, prefix, opts))
+	}
+
+	src := fmt.Sprintf(`// Code generated by protoc-gen-mysql. DO NOT EDIT.
+// source: %s
+// requires Go 1.18 or later, as it uses any and generics.
+
+package %ssql
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	%s %s
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+%s%s`, f.GetName(), pbName, pbName, strconv.Quote(importPath), strings.Join(methods, ""), fmt.Sprintf(goHelperFuncs, prefix))
+
+	formatted, err := format.Source([]byte(src))
+	if err != nil {
+		glog.Errorf("failed to format go helper of %s: %v", f.GetName(), err)
+		formatted = []byte(src)
+	}
+	return []*plugin.CodeGeneratorResponse_File{
+		{
+			Name:    proto.String(path.Join(path.Dir(f.GetName()), pbName+"sql", strings.TrimSuffix(path.Base(f.GetName()), ".proto")+"_sqlhelper.go")),
+			Content: proto.String(string(formatted)),
+		},
+	}
+}
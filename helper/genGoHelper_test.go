@@ -0,0 +1,192 @@
+package helper
+
+import (
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Mojashi/proto-mysql/dep"
+	"github.com/Mojashi/proto-mysql/gensql"
+	"github.com/golang/protobuf/protoc-gen-go/descriptor"
+	plugin "github.com/golang/protobuf/protoc-gen-go/plugin"
+	gengo "google.golang.org/protobuf/cmd/protoc-gen-go/internal_gengo"
+	"google.golang.org/protobuf/compiler/protogen"
+	"google.golang.org/protobuf/encoding/prototext"
+	"google.golang.org/protobuf/proto"
+)
+
+// fields of every conversion of the go helper. repeated enums are numbers, as in the python helper
+const goHelperTestFile = `
+	name: "userpb/user.proto"
+	package: "Foo"
+	syntax: "proto3"
+	options { go_package: "example.com/userpb;userpb" }
+	message_type {
+		name: "User"
+		field { name: "id" number: 1 type: TYPE_INT64 label: LABEL_OPTIONAL json_name: "id" }
+		field { name: "page_number" number: 2 type: TYPE_INT32 label: LABEL_OPTIONAL json_name: "pageNumber" proto3_optional: true oneof_index: 0 }
+		field { name: "gender" number: 3 type: TYPE_ENUM type_name: ".Foo.Gender" label: LABEL_OPTIONAL json_name: "gender" }
+		field { name: "genders" number: 4 type: TYPE_ENUM type_name: ".Foo.Gender" label: LABEL_REPEATED json_name: "genders" }
+		field { name: "friend" number: 5 type: TYPE_MESSAGE type_name: ".Foo.Friend" label: LABEL_OPTIONAL json_name: "friend" }
+		field { name: "friends" number: 6 type: TYPE_MESSAGE type_name: ".Foo.Friend" label: LABEL_REPEATED json_name: "friends" }
+		field { name: "labels" number: 7 type: TYPE_MESSAGE type_name: ".Foo.User.LabelsEntry" label: LABEL_REPEATED json_name: "labels" }
+		field { name: "friend_by_name" number: 8 type: TYPE_MESSAGE type_name: ".Foo.User.FriendByNameEntry" label: LABEL_REPEATED json_name: "friendByName" }
+		field { name: "tags" number: 9 type: TYPE_STRING label: LABEL_REPEATED json_name: "tags" }
+		nested_type {
+			name: "LabelsEntry"
+			field { name: "key" number: 1 type: TYPE_STRING label: LABEL_OPTIONAL json_name: "key" }
+			field { name: "value" number: 2 type: TYPE_INT32 label: LABEL_OPTIONAL json_name: "value" }
+			options { map_entry: true }
+		}
+		nested_type {
+			name: "FriendByNameEntry"
+			field { name: "key" number: 1 type: TYPE_STRING label: LABEL_OPTIONAL json_name: "key" }
+			field { name: "value" number: 2 type: TYPE_MESSAGE type_name: ".Foo.Friend" label: LABEL_OPTIONAL json_name: "value" }
+			options { map_entry: true }
+		}
+		oneof_decl { name: "_page_number" }
+		options { [mySQLTable] { primaryKey: ["id"] softDelete { column: "deleted_at" } } }
+	}
+	message_type {
+		name: "Friend"
+		field { name: "name" number: 1 type: TYPE_STRING label: LABEL_OPTIONAL json_name: "name" }
+	}
+	enum_type { name: "Gender" value { name: "MALE" number: 0 } value { name: "FEMALE" number: 1 } }`
+
+// prints the row of a user, so that the conversions are checked at run time
+const goHelperTestMain = `package main
+
+import (
+	"fmt"
+
+	"example.com/userpb"
+	"example.com/userpb/userpbsql"
+	"google.golang.org/protobuf/proto"
+)
+
+func main() {
+	u := &userpb.User{
+		Id:           1,
+		PageNumber:   proto.Int32(2),
+		Gender:       userpb.Gender_FEMALE,
+		Genders:      []userpb.Gender{userpb.Gender_MALE},
+		Friends:      []*userpb.Friend{{Name: "a"}},
+		Labels:       map[string]int32{"x": 1},
+		FriendByName: map[string]*userpb.Friend{"b": {Name: "b"}},
+	}
+	for _, u := range []*userpb.User{u, {}} {
+		for i, v := range userpbsql.UserToRow(u) {
+			if b, ok := v.([]byte); ok && userpbsql.UserColumnNames[i] != "PROTO_BINARY" {
+				v = string(b)
+			}
+			fmt.Printf("%s=%v\n", userpbsql.UserColumnNames[i], v)
+		}
+	}
+	// the ENUM column cannot hold numbers the enum does not declare
+	defer func() { fmt.Printf("unknown enum: %v\n", recover()) }()
+	userpbsql.UserToRow(&userpb.User{Gender: 7})
+}
+`
+
+// compiles the helper with the protobuf Go code of the same file, and runs it.
+// the go command must find google.golang.org/protobuf in the module cache, as the network is not used
+func TestGenGoHelperCompiles(t *testing.T) {
+	if testing.Short() {
+		t.Skip("go build is slow")
+	}
+	goCmd, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("go command is not found")
+	}
+	f := &descriptor.FileDescriptorProto{}
+	if err := prototext.Unmarshal([]byte(goHelperTestFile), f); err != nil {
+		t.Fatal(err)
+	}
+	req := &plugin.CodeGeneratorRequest{
+		FileToGenerate: []string{f.GetName()},
+		Parameter:      proto.String("paths=source_relative"),
+		ProtoFile:      []*descriptor.FileDescriptorProto{f},
+	}
+	gen, err := protogen.Options{}.New(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, gf := range gen.Files {
+		if gf.Generate {
+			gengo.GenerateFile(gen, gf)
+		}
+	}
+	files := gen.Response().GetFile()
+
+	tests := []struct {
+		name    string
+		dialect gensql.Dialect
+		output  string
+	}{
+		{
+			name: "mysql",
+			output: `page_number=2
+gender=FEMALE
+genders=[0]
+friend={}
+friends=[{"name":"a"}]
+labels={"x":1}
+friend_by_name={"b":{"name":"b"}}
+tags=[]`,
+		},
+		{name: "postgres", dialect: gensql.DialectPostgreSQL},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			write := func(name, content string) {
+				p := filepath.Join(dir, filepath.FromSlash(name))
+				if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+					t.Fatal(err)
+				}
+				if err := ioutil.WriteFile(p, []byte(content), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			write("go.mod", "module example.com\n\ngo 1.18\n\nrequire google.golang.org/protobuf v1.27.0\n")
+			write("main.go", goHelperTestMain)
+			for _, gf := range files {
+				write(gf.GetName(), gf.GetContent())
+			}
+			helperFiles := genGoHelper(dep.AnalyzeDependency(req, f), f, gensql.Options{Dialect: tt.dialect})
+			if len(helperFiles) != 1 || helperFiles[0].GetName() != "userpb/userpbsql/user_sqlhelper.go" {
+				t.Fatalf("helper files = %v", helperFiles)
+			}
+			write(helperFiles[0].GetName(), helperFiles[0].GetContent())
+
+			cmd := exec.Command(goCmd, "run", ".")
+			cmd.Dir = dir
+			cmd.Env = append(os.Environ(), "GOFLAGS=-mod=mod", "GOPROXY=off", "GOSUMDB=off")
+			out, err := cmd.CombinedOutput()
+			if err != nil {
+				t.Fatalf("failed to run the helper: %v\n%s\n%s", err, out, helperFiles[0].GetContent())
+			}
+			if tt.output == "" {
+				return
+			}
+			// the second user has no optional field set
+			for _, line := range strings.Split(tt.output, "\n") {
+				if !strings.Contains(string(out), line+"\n") {
+					t.Errorf("output\n%s\nmisses %q", out, line)
+				}
+			}
+			if !strings.Contains(string(out), "page_number=<nil>\n") {
+				t.Errorf("output\n%s\nmisses NULL of unset optional field", out)
+			}
+			if !strings.Contains(string(out), "labels={}\n") {
+				t.Errorf("output\n%s\nmisses empty map", out)
+			}
+			if !strings.Contains(string(out), "unknown enum: unknown value 7 of enum Foo.Gender\n") {
+				t.Errorf("output\n%s\nmisses panic of unknown enum value", out)
+			}
+		})
+	}
+}
@@ -2,10 +2,12 @@ package helper
 
 import (
 	"fmt"
+	"strconv"
 	"strings"
 
 	"github.com/Mojashi/proto-mysql/dep"
 	"github.com/Mojashi/proto-mysql/gensql"
+	"github.com/golang/glog"
 	"github.com/golang/protobuf/protoc-gen-go/descriptor"
 	plugin "github.com/golang/protobuf/protoc-gen-go/plugin"
 	"google.golang.org/protobuf/proto"
@@ -36,61 +38,261 @@ func genEnumDicts(dep dep.INameSpace, namespace string) []string {
 	return cur
 }
 
-func genMethods(dep dep.INameSpace, mdesc *descriptor.DescriptorProto) string {
+// DB-API paramstyle of the usual driver for each dialect
+func pythonPlaceholder(d gensql.Dialect) string {
+	switch d {
+	case gensql.DialectSQLite:
+		return "?"
+	default:
+		return "%s"
+	}
+}
+
+func genInsertSQL(tableName gensql.TableName, columnNames []string, opts gensql.Options) string {
+	if opts.Dialect == gensql.DialectClickHouse {
+		// clickhouse-driver takes rows separately from the statement
+		return fmt.Sprintf("INSERT INTO %s (%s) VALUES",
+			tableName.Format(opts.Dialect), strings.Join(quoteIdents(opts.Dialect, columnNames), ","))
+	}
+	placeholders := make([]string, 0, len(columnNames))
+	quoted := make([]string, 0, len(columnNames))
+	for _, name := range columnNames {
+		placeholders = append(placeholders, pythonPlaceholder(opts.Dialect))
+		quoted = append(quoted, gensql.QuoteIdent(opts.Dialect, name))
+	}
+	return fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s)",
+		tableName.Format(opts.Dialect), strings.Join(quoted, ","), strings.Join(placeholders, ","))
+}
+
+func quoteIdents(d gensql.Dialect, names []string) []string {
+	ret := make([]string, 0, len(names))
+	for _, name := range names {
+		ret = append(ret, gensql.QuoteIdent(d, name))
+	}
+	return ret
+}
+
+func getEnumDictRef(fdesc *descriptor.FieldDescriptorProto) string {
+	terms := strings.Split(fdesc.GetTypeName(), ".")
+	return getEnumDictName(strings.Join(terms[:len(terms)-1], "_"), terms[len(terms)-1])
+}
+
+// ClickHouse takes native values. Array as list, Tuple as tuple, Nullable as None.
+// optional messages are not Nullable, so unset ones are passed as tuples of default values
+func genClickHouseElem(dep dep.INameSpace, fdesc *descriptor.FieldDescriptorProto, name string) string {
+	single := func(v string) string {
+		switch fdesc.GetType() {
+		case descriptor.FieldDescriptorProto_TYPE_ENUM:
+			return fmt.Sprintf("%s[%s]", getEnumDictRef(fdesc), v)
+		case descriptor.FieldDescriptorProto_TYPE_MESSAGE, descriptor.FieldDescriptorProto_TYPE_GROUP:
+			msg, ok := dep.GetMessage(strings.Split(fdesc.GetTypeName(), "."))
+			if !ok {
+				return v
+			}
+			elems := []string{}
+			for _, f := range msg.GetDescriptor().GetField() {
+				elems = append(elems, genClickHouseElem(dep, f, v+"."+f.GetName()))
+			}
+			return fmt.Sprintf("(%s,)", strings.Join(elems, ","))
+		default:
+			return v
+		}
+	}
+
+	if fdesc.GetLabel() == descriptor.FieldDescriptorProto_LABEL_REPEATED {
+		return fmt.Sprintf("[%s for v in %s]", single("v"), name)
+	}
+	if fdesc.GetProto3Optional() && fdesc.GetType() != descriptor.FieldDescriptorProto_TYPE_MESSAGE {
+		owner := name[:strings.LastIndex(name, ".")]
+		return fmt.Sprintf(`(%s if %s.HasField("%s") else None)`, single(name), owner, fdesc.GetName())
+	}
+	return single(name)
+}
+
+// key of the field in json_format. protoc fills json_name, and it is derived in the same way otherwise
+func jsonName(fdesc *descriptor.FieldDescriptorProto) string {
+	if fdesc.JsonName != nil {
+		return fdesc.GetJsonName()
+	}
+	var b []byte
+	upper := false
+	for i := 0; i < len(fdesc.GetName()); i++ {
+		c := fdesc.GetName()[i]
+		switch {
+		case c == '_':
+			upper = true
+		case upper && isASCIILower(c):
+			b = append(b, c-('a'-'A'))
+			upper = false
+		default:
+			b = append(b, c)
+			upper = false
+		}
+	}
+	return string(b)
+}
+
+func genElem(dep dep.INameSpace, fdesc *descriptor.FieldDescriptorProto, name string) string {
+	elem := ""
+
+	switch gensql.GetConversion(dep, fdesc) {
+	case gensql.ConversionJSONArray:
+		elem = fmt.Sprintf("json.dumps(list(%s))", name)
+	case gensql.ConversionJSONMessageArray:
+		elem = fmt.Sprintf(`"["+",".join(map(lambda v: json_format.MessageToJson(v), list(%s)))+"]"`, name)
+	case gensql.ConversionJSONMessage:
+		elem = fmt.Sprintf("json_format.MessageToJson(%s)", name)
+	case gensql.ConversionJSONMap:
+		elem = fmt.Sprintf("json.dumps(json_format.MessageToDict(%s).get(%s, {}))", strings.TrimSuffix(name, "."+fdesc.GetName()), strconv.Quote(jsonName(fdesc)))
+	case gensql.ConversionEnumName:
+		elem = fmt.Sprintf("%s[%s]", getEnumDictRef(fdesc), name)
+	default:
+		elem = name
+	}
+
+	if fdesc.GetProto3Optional() {
+		elem = fmt.Sprintf(`%s if value.HasField("%s") else None`, elem, fdesc.GetName())
+	}
+	return elem
+}
+
+func genMethods(dep dep.INameSpace, f *descriptor.FileDescriptorProto, mdesc *descriptor.DescriptorProto, opts gensql.Options) string {
 	tableName := mdesc.GetName()
 	elems := []string{}
 	columns := []string{}
+	columnNames := []string{}
+	fieldElems := map[string]string{}
 
 	for _, fdesc := range mdesc.Field {
-		columns = append(columns, "\""+fdesc.GetName()+"\"")
 		name := "value." + fdesc.GetName()
-		ptype := fdesc.GetType()
 		elem := ""
-
-		switch t, _ := gensql.GenMySQLDataType(dep, fdesc); t.GetType() {
-		case gensql.JSON:
-			if fdesc.GetLabel() == descriptor.FieldDescriptorProto_LABEL_REPEATED {
-				if ptype != descriptor.FieldDescriptorProto_TYPE_MESSAGE {
-					elem = fmt.Sprintf("json.dumps(list(%s))", name)
-				} else {
-					elem = fmt.Sprintf(`"["+",".join(map(lambda v: json_format.MessageToJson(v), list(%s)))+"]"`, name)
-				}
-			} else {
-				elem = fmt.Sprintf("json_format.MessageToJson(%s)", name)
-			}
-		case gensql.ENUM:
-			terms := strings.Split(fdesc.GetTypeName(), ".")
-			elem = fmt.Sprintf("%s[%s]",
-				getEnumDictName(strings.Join(terms[:len(terms)-1], "_"), terms[len(terms)-1]),
-				name)
-		default:
-			elem = name
+		if opts.Dialect == gensql.DialectClickHouse {
+			elem = genClickHouseElem(dep, fdesc, name)
+		} else {
+			elem = genElem(dep, fdesc, name)
 		}
-
-		if fdesc.GetProto3Optional() {
-			elem = fmt.Sprintf(`%s if value.HasField("%s") else None`, elem, fdesc.GetName())
+		fieldElems[fdesc.GetName()] = elem
+		// filled by the database
+		if gensql.IsAutoIncrement(fdesc) {
+			continue
 		}
+		columns = append(columns, "\""+fdesc.GetName()+"\"")
+		columnNames = append(columnNames, fdesc.GetName())
 		elems = append(elems, elem)
 	}
 
 	columns = append(columns, "\"PROTO_BINARY\"")
+	columnNames = append(columnNames, "PROTO_BINARY")
 	elems = append(elems, "value.SerializeToString()")
 
+	// audit columns are filled by MySQL, so they are kept out of the INSERT tuple
+	auditColumns := []string{}
+	if audit, ok := gensql.GetAuditColumns(mdesc); ok {
+		for _, name := range audit.Names() {
+			auditColumns = append(auditColumns, "\""+name+"\"")
+		}
+	}
+
+	// ClickHouse tables are append-only replicas
+	softDeleteMethods := ""
+	if sd, ok := gensql.GetSoftDelete(mdesc); ok && opts.Dialect != gensql.DialectClickHouse {
+		softDeleteMethods = genSoftDeleteMethods(mdesc, gensql.GetTableName(f, mdesc, opts), sd, fieldElems, opts)
+	}
+
+	fingerprintMethods := ""
+	if opts.Fingerprint {
+		fingerprintMethods = genFingerprintMethods(dep, mdesc, gensql.GetTableName(f, mdesc, opts))
+	}
+
 	return fmt.Sprintf(`
+def get%sTableName() -> str:
+	return %s
+
 def get%sColumnNames() -> List[str]:
 	return [%s,]
+
+def get%sInsertSQL() -> str:
+	return %s
+
+def get%sAuditColumnNames() -> List[str]:
+	return [%s]
 	
 # convert proto message class variable to INSERT-ready dictionary
 def conv%sProtoClassToData(value) -> Tuple:
 	return (%s)
-		`, tableName, strings.Join(columns, ","), tableName, strings.Join(elems, ","))
+%s%s		`, tableName, strconv.Quote(gensql.GetTableName(f, mdesc, opts).Format(opts.Dialect)),
+		tableName, strings.Join(columns, ","),
+		tableName, strconv.Quote(genInsertSQL(gensql.GetTableName(f, mdesc, opts), columnNames, opts)),
+		tableName, strings.Join(auditColumns, ","),
+		tableName, strings.Join(elems, ","),
+		softDeleteMethods, fingerprintMethods)
+}
+
+// compare them at startup, so that the application refuses a database migrated from another descriptor
+func genFingerprintMethods(dep dep.INameSpace, mdesc *descriptor.DescriptorProto, tableName gensql.TableName) string {
+	fingerprint, err := gensql.MessageFingerprint(dep, mdesc)
+	if err != nil {
+		glog.Error(err)
+		return ""
+	}
+	return fmt.Sprintf(`
+# fingerprint of the message descriptor, which the database records in %[2]s
+def get%[1]sSchemaFingerprint() -> str:
+	return %[3]s
+
+def get%[1]sSchemaFingerprintSQL() -> str:
+	return %[4]s
+`, mdesc.GetName(), gensql.FingerprintTable, strconv.Quote(fingerprint), strconv.Quote(gensql.FingerprintSelectSQL(tableName)))
+}
+
+// rows are identified by primary key, or by PROTO_BINARY if the table has none.
+// PROTO_BINARY is also used when a key column is not converted from a field, e.g. a generated column.
+func genSoftDeleteMethods(mdesc *descriptor.DescriptorProto, tableName gensql.TableName, sd gensql.SoftDelete, fieldElems map[string]string, opts gensql.Options) string {
+	q := func(name string) string { return gensql.QuoteIdent(opts.Dialect, name) }
+	conds := []string{}
+	keyElems := []string{}
+
+	tableOpts, _ := gensql.CheckTableOptions(mdesc)
+	for _, column := range tableOpts.GetPrimaryKey() {
+		elem, ok := fieldElems[column]
+		if !ok {
+			glog.Warningf("primary key column %s of %s is not a field, so soft-deleted rows are identified by PROTO_BINARY", column, mdesc.GetName())
+			conds, keyElems = nil, nil
+			break
+		}
+		conds = append(conds, q(column)+" = "+pythonPlaceholder(opts.Dialect))
+		keyElems = append(keyElems, elem)
+	}
+	if len(conds) == 0 {
+		conds = append(conds, q("PROTO_BINARY")+" = "+pythonPlaceholder(opts.Dialect))
+		keyElems = append(keyElems, "value.SerializeToString()")
+	}
+	where := strings.Join(conds, " AND ")
+
+	softDeleteSQL := fmt.Sprintf("UPDATE %[1]s SET %[2]s = %[4]s WHERE %[3]s AND %[2]s IS NULL",
+		tableName.Format(opts.Dialect), q(sd.Column), where, gensql.CurrentTimestamp(opts.Dialect))
+	restoreSQL := fmt.Sprintf("UPDATE %[1]s SET %[2]s = NULL WHERE %[3]s AND %[2]s IS NOT NULL",
+		tableName.Format(opts.Dialect), q(sd.Column), where)
+
+	return fmt.Sprintf(`
+# key tuple for get%[1]sSoftDeleteSQL and get%[1]sRestoreSQL
+def conv%[1]sProtoClassToKey(value) -> Tuple:
+	return (%[2]s,)
+
+# UPDATE statement which marks the row as deleted instead of DELETE
+def get%[1]sSoftDeleteSQL() -> str:
+	return %[3]s
+
+def get%[1]sRestoreSQL() -> str:
+	return %[4]s
+`, mdesc.GetName(), strings.Join(keyElems, ","), strconv.Quote(softDeleteSQL), strconv.Quote(restoreSQL))
 }
 
-func genPythonHelper(dep dep.INameSpace, f *descriptor.FileDescriptorProto) []*plugin.CodeGeneratorResponse_File {
+func genPythonHelper(dep dep.INameSpace, f *descriptor.FileDescriptorProto, opts gensql.Options) []*plugin.CodeGeneratorResponse_File {
 	methods := []string{}
 
 	for _, mdesc := range f.MessageType {
-		methods = append(methods, genMethods(dep, mdesc))
+		methods = append(methods, genMethods(dep, f, mdesc, opts))
 	}
 
 	return []*plugin.CodeGeneratorResponse_File{
@@ -0,0 +1,60 @@
+package helper
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/Mojashi/proto-mysql/gensql"
+	"github.com/golang/protobuf/protoc-gen-go/descriptor"
+	"google.golang.org/protobuf/encoding/prototext"
+)
+
+// message descriptor written in text format. options of mySQLOptions.proto are written as extensions
+func parseMessage(t *testing.T, text string) *descriptor.DescriptorProto {
+	t.Helper()
+	m := &descriptor.DescriptorProto{}
+	if err := prototext.Unmarshal([]byte(text), m); err != nil {
+		t.Fatalf("failed to parse message descriptor: %v", err)
+	}
+	return m
+}
+
+func TestGenSoftDeleteMethods(t *testing.T) {
+	tests := []struct {
+		name    string
+		options string
+		key     string
+		where   string
+	}{
+		{
+			name:    "primary key",
+			options: `primaryKey: ["id", "tenant_id"]`,
+			key:     "return (value.id,value.tenant_id,)",
+			where:   "WHERE id = %s AND tenant_id = %s",
+		},
+		{
+			name:  "no primary key",
+			key:   "return (value.SerializeToString(),)",
+			where: "WHERE PROTO_BINARY = %s",
+		},
+		{
+			name:    "primary key column which is not a field",
+			options: `primaryKey: ["id", "created_at"]`,
+			key:     "return (value.SerializeToString(),)",
+			where:   "WHERE PROTO_BINARY = %s",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := parseMessage(t, `name: "Event" options { [mySQLTable] { `+tt.options+` } }`)
+			fieldElems := map[string]string{"id": "value.id", "tenant_id": "value.tenant_id"}
+			code := genSoftDeleteMethods(m, gensql.TableName{Name: "Event"}, gensql.SoftDelete{Column: "deleted_at"}, fieldElems, gensql.Options{})
+			if !strings.Contains(code, tt.key) {
+				t.Errorf("key of\n%s\nis not %q", code, tt.key)
+			}
+			if !strings.Contains(code, tt.where) {
+				t.Errorf("condition of\n%s\nis not %q", code, tt.where)
+			}
+		})
+	}
+}
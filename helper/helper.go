@@ -2,14 +2,16 @@ package helper
 
 import (
 	"github.com/Mojashi/proto-mysql/dep"
+	"github.com/Mojashi/proto-mysql/gensql"
 	"github.com/golang/protobuf/protoc-gen-go/descriptor"
 	plugin "github.com/golang/protobuf/protoc-gen-go/plugin"
 )
 
-type Helper = func(dep.INameSpace, *descriptor.FileDescriptorProto) []*plugin.CodeGeneratorResponse_File
+type Helper = func(dep.INameSpace, *descriptor.FileDescriptorProto, gensql.Options) []*plugin.CodeGeneratorResponse_File
 
 var helpers = map[string]Helper{
 	"python": genPythonHelper,
+	"go":     genGoHelper,
 }
 
 func GetHelperGen(name string) (Helper, bool) {
@@ -1,10 +1,16 @@
 package main
 
 import (
+	"fmt"
 	"io"
 	"io/ioutil"
 	"log"
 	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
 
 	"github.com/Mojashi/proto-mysql/dep"
 	"github.com/Mojashi/proto-mysql/gensql"
@@ -29,28 +35,379 @@ func parseReq(r io.Reader) (*plugin.CodeGeneratorRequest, error) {
 	return &req, nil
 }
 
+// parameter is given as --mysql_out=key=value,key=value:outdir
+func parseParameter(parameter string) (gensql.Options, error) {
+	var opts gensql.Options
+	var err error
+	if parameter == "" {
+		return opts, nil
+	}
+	for _, kv := range strings.Split(parameter, ",") {
+		terms := strings.SplitN(kv, "=", 2)
+		if len(terms) != 2 {
+			return opts, fmt.Errorf("invalid parameter %s", kv)
+		}
+		switch key, value := terms[0], terms[1]; key {
+		case "package":
+			switch m := gensql.PackageMapping(value); m {
+			case gensql.PackageMappingDatabase, gensql.PackageMappingPrefix:
+				opts.PackageMapping = m
+			default:
+				return opts, fmt.Errorf("unknown package mapping %s", value)
+			}
+		case "dialect":
+			if opts.Dialect, err = gensql.ParseDialect(value); err != nil {
+				return opts, err
+			}
+		case "bigquery":
+			if opts.BigQuerySchema, err = strconv.ParseBool(value); err != nil {
+				return opts, fmt.Errorf("invalid bigquery parameter %s", value)
+			}
+		case "manifest":
+			if opts.Manifest, err = strconv.ParseBool(value); err != nil {
+				return opts, fmt.Errorf("invalid manifest parameter %s", value)
+			}
+		case "baseline":
+			opts.Baseline = value
+		case "breaking":
+			if opts.CheckBreaking, err = strconv.ParseBool(value); err != nil {
+				return opts, fmt.Errorf("invalid breaking parameter %s", value)
+			}
+		case "reserved":
+			switch p := gensql.ReservedPolicy(value); p {
+			case gensql.ReservedDrop, gensql.ReservedKeep:
+				opts.ReservedPolicy = p
+			default:
+				return opts, fmt.Errorf("unknown reserved policy %s", value)
+			}
+		case "if_not_exists":
+			if opts.IfNotExists, err = strconv.ParseBool(value); err != nil {
+				return opts, fmt.Errorf("invalid if_not_exists parameter %s", value)
+			}
+		case "drop_tables":
+			if opts.DropTables, err = strconv.ParseBool(value); err != nil {
+				return opts, fmt.Errorf("invalid drop_tables parameter %s", value)
+			}
+		case "fk_checks_guard":
+			if opts.ForeignKeyChecksGuard, err = strconv.ParseBool(value); err != nil {
+				return opts, fmt.Errorf("invalid fk_checks_guard parameter %s", value)
+			}
+		case "migration_format":
+			if opts.MigrationFormat, err = gensql.ParseMigrationFormat(value); err != nil {
+				return opts, err
+			}
+		case "migration_dir":
+			opts.MigrationDir = value
+		case "migration_name":
+			opts.MigrationName = value
+		case "out_dir":
+			opts.OutDir = value
+		case "algorithm":
+			if opts.AlterAlgorithm, err = strconv.ParseBool(value); err != nil {
+				return opts, fmt.Errorf("invalid algorithm parameter %s", value)
+			}
+		case "osc":
+			if opts.OnlineSchemaChange, err = strconv.ParseBool(value); err != nil {
+				return opts, fmt.Errorf("invalid osc parameter %s", value)
+			}
+		case "helper":
+			if _, ok := helper.GetHelperGen(value); !ok {
+				return opts, fmt.Errorf("unknown helper %s", value)
+			}
+			opts.Helpers = append(opts.Helpers, value)
+		case "fingerprint":
+			if opts.Fingerprint, err = strconv.ParseBool(value); err != nil {
+				return opts, fmt.Errorf("invalid fingerprint parameter %s", value)
+			}
+		case "mysql_version":
+			if opts.MySQLVersion, err = gensql.ParseMySQLVersion(value); err != nil {
+				return opts, err
+			}
+		default:
+			return opts, fmt.Errorf("unknown parameter %s", key)
+		}
+	}
+	if !opts.MySQLVersion.IsZero() && opts.Dialect != gensql.DialectMySQL && opts.Dialect != "" {
+		return opts, fmt.Errorf("mysql_version cannot be used with dialect %s", opts.Dialect)
+	}
+	if opts.CheckBreaking && opts.Baseline == "" {
+		return opts, fmt.Errorf("breaking requires baseline")
+	}
+	if (opts.AlterAlgorithm || opts.OnlineSchemaChange) && opts.Baseline == "" && opts.MigrationFormat == "" {
+		return opts, fmt.Errorf("algorithm and osc require baseline or migration_format")
+	}
+	// MariaDB accepts other algorithms such as NOCOPY
+	if opts.AlterAlgorithm && opts.Dialect != gensql.DialectMySQL && opts.Dialect != "" {
+		return opts, fmt.Errorf("algorithm cannot be used with dialect %s", opts.Dialect)
+	}
+	if (opts.MigrationDir != "" || opts.MigrationName != "" || opts.OutDir != "") && opts.MigrationFormat == "" {
+		return opts, fmt.Errorf("migration_dir, migration_name and out_dir require migration_format")
+	}
+	switch opts.Dialect {
+	case gensql.DialectMySQL, gensql.DialectMariaDB, "":
+	default:
+		if opts.Manifest {
+			return opts, fmt.Errorf("manifest cannot be used with dialect %s", opts.Dialect)
+		}
+		if opts.Baseline != "" {
+			return opts, fmt.Errorf("baseline cannot be used with dialect %s", opts.Dialect)
+		}
+		if opts.MigrationFormat != "" {
+			return opts, fmt.Errorf("migration_format cannot be used with dialect %s", opts.Dialect)
+		}
+		if opts.Fingerprint {
+			return opts, fmt.Errorf("fingerprint cannot be used with dialect %s", opts.Dialect)
+		}
+		if opts.IfNotExists || opts.DropTables || opts.ForeignKeyChecksGuard {
+			return opts, fmt.Errorf("if_not_exists, drop_tables and fk_checks_guard cannot be used with dialect %s", opts.Dialect)
+		}
+	}
+	return opts, nil
+}
+
+func readBaseline(path string) (*plugin.CodeGeneratorRequest, error) {
+	buf, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var set descriptor.FileDescriptorSet
+	if err := proto.Unmarshal(buf, &set); err != nil {
+		return nil, fmt.Errorf("failed to parse baseline %s: %v", path, err)
+	}
+	// files are analyzed in the same way as a request
+	return &plugin.CodeGeneratorRequest{ProtoFile: set.File}, nil
+}
+
+// the file is compared with the file of the same name in baseline. nil if there is none
+func findBaseline(baseline *plugin.CodeGeneratorRequest, f *descriptor.FileDescriptorProto) (dep.INameSpace, *descriptor.FileDescriptorProto) {
+	if baseline == nil {
+		return nil, nil
+	}
+	for _, bf := range baseline.ProtoFile {
+		if bf.GetName() == f.GetName() {
+			return dep.AnalyzeDependency(baseline, bf), bf
+		}
+	}
+	return nil, nil
+}
+
+// files of baseline which are gone from the request. files only imported by other baseline files are skipped,
+// as they are not generated by themselves
+func removedBaselineFiles(baseline *plugin.CodeGeneratorRequest, files map[string]*descriptor.FileDescriptorProto) []*descriptor.FileDescriptorProto {
+	if baseline == nil {
+		return nil
+	}
+	imported := make(map[string]bool)
+	for _, bf := range baseline.ProtoFile {
+		for _, d := range bf.GetDependency() {
+			imported[d] = true
+		}
+	}
+	ret := []*descriptor.FileDescriptorProto{}
+	for _, bf := range baseline.ProtoFile {
+		if _, ok := files[bf.GetName()]; !ok && !imported[bf.GetName()] {
+			ret = append(ret, bf)
+		}
+	}
+	return ret
+}
+
+// numbered migration files, versioned next to the files in the migration directory.
+// protoc does not tell the plugin where the output goes, so the directory is scanned under OutDir. nil if nothing changes
+func genMigrationFiles(m *gensql.Migration, opts gensql.Options) ([]*plugin.CodeGeneratorResponse_File, error) {
+	if m.Up == "" {
+		return nil, nil
+	}
+	existing := []string{}
+	entries, err := ioutil.ReadDir(filepath.Join(opts.OutDir, opts.MigrationDir))
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	for _, e := range entries {
+		existing = append(existing, e.Name())
+	}
+	version := gensql.NextMigrationVersion(opts.MigrationFormat, existing)
+	// without baseline every migration creates the whole schema, which fails on top of the existing ones
+	if opts.Baseline == "" && version != gensql.NextMigrationVersion(opts.MigrationFormat, nil) {
+		return nil, fmt.Errorf("migration_dir %s has migrations, so baseline is required to generate the next one", filepath.Join(opts.OutDir, opts.MigrationDir))
+	}
+
+	files := gensql.GenMigrationFiles(opts.MigrationFormat, version, opts.MigrationName, m)
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	ret := make([]*plugin.CodeGeneratorResponse_File, 0, len(files))
+	for _, name := range names {
+		ret = append(ret, &plugin.CodeGeneratorResponse_File{
+			Name:    proto.String(path.Join(opts.MigrationDir, name)),
+			Content: proto.String(files[name]),
+		})
+	}
+	return ret, nil
+}
+
 func processReq(req *plugin.CodeGeneratorRequest) *plugin.CodeGeneratorResponse {
 	files := make(map[string]*descriptor.FileDescriptorProto)
 	for _, f := range req.ProtoFile {
 		files[f.GetName()] = f
 	}
 	var resp plugin.CodeGeneratorResponse
+	var SupportedFeatures = uint64(plugin.CodeGeneratorResponse_FEATURE_PROTO3_OPTIONAL)
+	resp.SupportedFeatures = proto.Uint64(SupportedFeatures)
+
+	opts, err := parseParameter(req.GetParameter())
+	if err != nil {
+		resp.Error = proto.String(err.Error())
+		return &resp
+	}
+
+	var baseline *plugin.CodeGeneratorRequest
+	if opts.Baseline != "" {
+		if baseline, err = readBaseline(opts.Baseline); err != nil {
+			resp.Error = proto.String(err.Error())
+			return &resp
+		}
+	}
+
+	toGenerate := make([]*descriptor.FileDescriptorProto, 0, len(req.FileToGenerate))
 	for _, fname := range req.FileToGenerate {
-		f := files[fname]
+		toGenerate = append(toGenerate, files[fname])
+	}
+	if err := gensql.CheckTableNameCollision(toGenerate, opts); err != nil {
+		resp.Error = proto.String(err.Error())
+		return &resp
+	}
+
+	deps := make([]dep.INameSpace, 0, len(toGenerate))
+	for _, f := range toGenerate {
+		deps = append(deps, dep.AnalyzeDependency(req, f))
+	}
+	// tables are ordered across the files, so all files are generated at once
+	sqls, err := gensql.GenSQLFiles(deps, toGenerate, opts)
+	if err != nil {
+		resp.Error = proto.String(err.Error())
+		return &resp
+	}
+
+	helperNames := opts.Helpers
+	if len(helperNames) == 0 {
+		helperNames = []string{"python"}
+	}
 
-		out := fname + ".sql"
-		dep := dep.AnalyzeDependency(req, f)
-		pycon, _ := helper.GetHelperGen("python")
+	breakingChanges := []string{}
+	migrations := []*gensql.Migration{}
+	// name is the proto file the outputs are named after. f is nil when the file is removed
+	migrate := func(name string, baselineDep dep.INameSpace, baselineFile *descriptor.FileDescriptorProto, dep dep.INameSpace, f *descriptor.FileDescriptorProto) error {
+		if opts.CheckBreaking {
+			breakings, err := gensql.CheckBreakingChanges(baselineDep, baselineFile, dep, f, opts)
+			if err != nil {
+				return err
+			}
+			for _, b := range breakings {
+				breakingChanges = append(breakingChanges, b.String())
+			}
+		}
+		migration, err := gensql.GenMigrationPair(baselineDep, baselineFile, dep, f, opts)
+		if err != nil {
+			return err
+		}
+		if opts.MigrationFormat != "" {
+			migrations = append(migrations, migration)
+		} else {
+			resp.File = append(resp.File, &plugin.CodeGeneratorResponse_File{
+				Name:    proto.String(name + ".migration.sql"),
+				Content: proto.String(migration.Up),
+			})
+		}
+		if opts.OnlineSchemaChange {
+			osc, err := gensql.GenOnlineSchemaChangesJSON(migration.Changes)
+			if err != nil {
+				return err
+			}
+			resp.File = append(resp.File, &plugin.CodeGeneratorResponse_File{
+				Name:    proto.String(name + ".osc.json"),
+				Content: proto.String(osc),
+			})
+		}
+		return nil
+	}
+	for i, f := range toGenerate {
+		out := f.GetName() + ".sql"
+		dep := deps[i]
 
 		resp.File = append(resp.File, &plugin.CodeGeneratorResponse_File{
 			Name:    proto.String(out),
-			Content: proto.String(gensql.GenSQL(dep, f)),
+			Content: proto.String(sqls[i]),
 		})
-		resp.File = append(resp.File, pycon(dep, f)...)
+		for _, name := range helperNames {
+			gen, _ := helper.GetHelperGen(name)
+			resp.File = append(resp.File, gen(dep, f, opts)...)
+		}
+
+		if baseline != nil || opts.MigrationFormat != "" {
+			baselineDep, baselineFile := findBaseline(baseline, f)
+			if err := migrate(f.GetName(), baselineDep, baselineFile, dep, f); err != nil {
+				resp.Error = proto.String(err.Error())
+				return &resp
+			}
+		}
+
+		if opts.Manifest {
+			manifest, err := gensql.GenManifestJSON(dep, f, opts)
+			if err != nil {
+				resp.Error = proto.String(err.Error())
+				return &resp
+			}
+			resp.File = append(resp.File, &plugin.CodeGeneratorResponse_File{
+				Name:    proto.String(f.GetName() + ".manifest.json"),
+				Content: proto.String(manifest),
+			})
+		}
+
+		if opts.BigQuerySchema {
+			schemas, err := gensql.GenBigQuerySchemas(dep, f, opts)
+			if err != nil {
+				resp.Error = proto.String(err.Error())
+				return &resp
+			}
+			names := make([]string, 0, len(schemas))
+			for name := range schemas {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			for _, name := range names {
+				resp.File = append(resp.File, &plugin.CodeGeneratorResponse_File{
+					Name:    proto.String(name),
+					Content: proto.String(schemas[name]),
+				})
+			}
+		}
+	}
+
+	// tables of files removed since the baseline are dropped
+	for _, bf := range removedBaselineFiles(baseline, files) {
+		if err := migrate(bf.GetName(), dep.AnalyzeDependency(baseline, bf), bf, nil, nil); err != nil {
+			resp.Error = proto.String(err.Error())
+			return &resp
+		}
+	}
+
+	if opts.MigrationFormat != "" {
+		migrationFiles, err := genMigrationFiles(gensql.MergeMigrations(migrations), opts)
+		if err != nil {
+			resp.Error = proto.String(err.Error())
+			return &resp
+		}
+		resp.File = append(resp.File, migrationFiles...)
+	}
+
+	if len(breakingChanges) > 0 {
+		resp.File = nil
+		resp.Error = proto.String("breaking changes from baseline:\n" + strings.Join(breakingChanges, "\n"))
 	}
 
-	var SupportedFeatures = uint64(plugin.CodeGeneratorResponse_FEATURE_PROTO3_OPTIONAL)
-	resp.SupportedFeatures = proto.Uint64(SupportedFeatures)
 	return &resp
 }
 
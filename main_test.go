@@ -0,0 +1,144 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/Mojashi/proto-mysql/gensql"
+	descriptor "github.com/golang/protobuf/protoc-gen-go/descriptor"
+	plugin "github.com/golang/protobuf/protoc-gen-go/plugin"
+	"google.golang.org/protobuf/encoding/prototext"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestGenMigrationFiles(t *testing.T) {
+	tests := []struct {
+		name     string
+		existing []string
+		params   string
+		files    []string
+		err      string
+	}{
+		{
+			name:   "first version",
+			params: "migration_format=golang-migrate,migration_dir=migrations",
+			files:  []string{"migrations/000001_schema.down.sql", "migrations/000001_schema.up.sql"},
+		},
+		{
+			name:     "next to the files in the output directory",
+			existing: []string{"migrations/000003_init.up.sql", "migrations/000003_init.down.sql"},
+			params:   "migration_format=golang-migrate,migration_dir=migrations,migration_name=add locale,baseline=schema.pb",
+			files:    []string{"migrations/000004_add_locale.down.sql", "migrations/000004_add_locale.up.sql"},
+		},
+		{
+			name:     "files outside migration_dir are ignored",
+			existing: []string{"V7__init.sql"},
+			params:   "migration_format=flyway,migration_dir=migrations",
+			files:    []string{"migrations/U1__schema.sql", "migrations/V1__schema.sql"},
+		},
+		{
+			name:     "output directory itself",
+			existing: []string{"00009_init.sql"},
+			params:   "migration_format=goose,baseline=schema.pb",
+			files:    []string{"00010_schema.sql"},
+		},
+		{
+			name:     "second run without baseline",
+			existing: []string{"migrations/000001_schema.up.sql", "migrations/000001_schema.down.sql"},
+			params:   "migration_format=golang-migrate,migration_dir=migrations",
+			err:      "baseline is required",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out := t.TempDir()
+			for _, name := range tt.existing {
+				p := filepath.Join(out, name)
+				if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+					t.Fatal(err)
+				}
+				if err := ioutil.WriteFile(p, nil, 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			opts, err := parseParameter(tt.params + ",out_dir=" + out)
+			if err != nil {
+				t.Fatal(err)
+			}
+			files, err := genMigrationFiles(&gensql.Migration{Up: "DROP TABLE A;", Down: "CREATE TABLE A (id INT);"}, opts)
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Errorf("err = %v, want %s", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			names := []string{}
+			for _, f := range files {
+				names = append(names, f.GetName())
+			}
+			if !reflect.DeepEqual(names, tt.files) {
+				t.Errorf("files = %v, want %v", names, tt.files)
+			}
+		})
+	}
+}
+
+func TestParseParameterOutDir(t *testing.T) {
+	if _, err := parseParameter("out_dir=db"); err == nil {
+		t.Error("out_dir without migration_format is accepted")
+	}
+}
+
+func parseFileDescriptor(t *testing.T, text string) *descriptor.FileDescriptorProto {
+	t.Helper()
+	f := &descriptor.FileDescriptorProto{}
+	if err := prototext.Unmarshal([]byte(text), f); err != nil {
+		t.Fatal(err)
+	}
+	return f
+}
+
+func TestProcessReqRemovedFile(t *testing.T) {
+	user := parseFileDescriptor(t, `name: "user.proto" package: "Foo" syntax: "proto3" message_type { name: "User" field { name: "id" number: 1 type: TYPE_INT64 label: LABEL_OPTIONAL } }`)
+	item := parseFileDescriptor(t, `name: "item.proto" package: "Foo" syntax: "proto3" message_type { name: "Item" field { name: "id" number: 1 type: TYPE_INT64 label: LABEL_OPTIONAL } }`)
+	buf, err := proto.Marshal(&descriptor.FileDescriptorSet{File: []*descriptor.FileDescriptorProto{user, item}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := t.TempDir()
+	baseline := filepath.Join(out, "schema.pb")
+	if err := ioutil.WriteFile(baseline, buf, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	resp := processReq(&plugin.CodeGeneratorRequest{
+		FileToGenerate: []string{"user.proto"},
+		Parameter:      proto.String("migration_format=golang-migrate,baseline=" + baseline + ",out_dir=" + out),
+		ProtoFile:      []*descriptor.FileDescriptorProto{user},
+	})
+	if resp.Error != nil {
+		t.Fatal(resp.GetError())
+	}
+	up, down := "", ""
+	for _, f := range resp.File {
+		switch f.GetName() {
+		case "000001_schema.up.sql":
+			up = f.GetContent()
+		case "000001_schema.down.sql":
+			down = f.GetContent()
+		}
+	}
+	if !strings.Contains(up, "DROP TABLE") || !strings.Contains(up, "Item") || strings.Contains(up, "User") {
+		t.Errorf("up migration does not drop only the table of the removed file:\n%s", up)
+	}
+	if !strings.Contains(down, "CREATE TABLE") || !strings.Contains(down, "Item") {
+		t.Errorf("down migration does not create the table of the removed file:\n%s", down)
+	}
+}
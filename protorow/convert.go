@@ -0,0 +1,308 @@
+package protorow
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/Mojashi/proto-mysql/gensql"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// conversion between a column and the field, decided when the plan is built
+type column struct {
+	name string
+	// nil for PROTO_BINARY
+	field  protoreflect.FieldDescriptor
+	encode func(msg protoreflect.Message) (interface{}, error)
+	// v is a value of database/sql driver, which is not nil
+	decode func(msg protoreflect.Message, v interface{}) error
+}
+
+func newProtoBinaryColumn() *column {
+	return &column{
+		name: gensql.ProtoBinaryColumn,
+		encode: func(msg protoreflect.Message) (interface{}, error) {
+			return proto.Marshal(msg.Interface())
+		},
+		decode: func(msg protoreflect.Message, v interface{}) error {
+			b, ok := v.([]byte)
+			if !ok {
+				return fmt.Errorf("%s is %T, not bytes", gensql.ProtoBinaryColumn, v)
+			}
+			return proto.Unmarshal(b, msg.Interface())
+		},
+	}
+}
+
+// conversions are the same as GetConversion of helpers
+func newFieldColumn(c *gensql.Column, fd protoreflect.FieldDescriptor) (*column, error) {
+	col := &column{name: c.Name, field: fd}
+	switch {
+	case c.Type.GetType() == gensql.JSON && (fd.IsList() || fd.Message() != nil):
+		col.encode = jsonEncoder(fd)
+		col.decode = jsonDecoder(fd)
+	case c.Type.GetType() == gensql.ENUM && fd.Enum() != nil:
+		col.encode = enumNameEncoder(fd)
+		col.decode = enumNameDecoder(fd)
+	default:
+		col.encode = scalarEncoder(fd)
+		col.decode = scalarDecoder(fd)
+	}
+	if c.Nullable {
+		encode := col.encode
+		col.encode = func(msg protoreflect.Message) (interface{}, error) {
+			if !msg.Has(fd) {
+				return nil, nil
+			}
+			return encode(msg)
+		}
+	}
+	return col, nil
+}
+
+func scalarEncoder(fd protoreflect.FieldDescriptor) func(protoreflect.Message) (interface{}, error) {
+	return func(msg protoreflect.Message) (interface{}, error) {
+		v := msg.Get(fd)
+		switch fd.Kind() {
+		case protoreflect.EnumKind:
+			return int64(v.Enum()), nil
+		case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind,
+			protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind:
+			return v.Int(), nil
+		case protoreflect.Uint32Kind, protoreflect.Fixed32Kind, protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+			return v.Uint(), nil
+		case protoreflect.FloatKind, protoreflect.DoubleKind:
+			return v.Float(), nil
+		default:
+			return v.Interface(), nil
+		}
+	}
+}
+
+func enumNameEncoder(fd protoreflect.FieldDescriptor) func(protoreflect.Message) (interface{}, error) {
+	return func(msg protoreflect.Message) (interface{}, error) {
+		n := msg.Get(fd).Enum()
+		value := fd.Enum().Values().ByNumber(n)
+		if value == nil {
+			return nil, fmt.Errorf("value %d of %s is not defined in %s", n, fd.FullName(), fd.Enum().FullName())
+		}
+		return string(value.Name()), nil
+	}
+}
+
+// messages are written by protojson, and lists as JSON arrays. nil list is an empty array, as the column is NOT NULL
+func jsonEncoder(fd protoreflect.FieldDescriptor) func(protoreflect.Message) (interface{}, error) {
+	// maps are written as protojson writes them in the message, which is an empty object if nil
+	if fd.IsMap() {
+		return func(msg protoreflect.Message) (interface{}, error) {
+			if !msg.Has(fd) {
+				return []byte("{}"), nil
+			}
+			only := msg.New()
+			only.Set(fd, msg.Get(fd))
+			buf, err := protojson.Marshal(only.Interface())
+			if err != nil {
+				return nil, err
+			}
+			var object map[string]json.RawMessage
+			if err := json.Unmarshal(buf, &object); err != nil {
+				return nil, err
+			}
+			return []byte(object[fd.JSONName()]), nil
+		}
+	}
+	if !fd.IsList() {
+		return func(msg protoreflect.Message) (interface{}, error) {
+			return protojson.Marshal(msg.Get(fd).Message().Interface())
+		}
+	}
+	if fd.Message() != nil {
+		return func(msg protoreflect.Message) (interface{}, error) {
+			list := msg.Get(fd).List()
+			elems := make([][]byte, 0, list.Len())
+			for i := 0; i < list.Len(); i++ {
+				elem, err := protojson.Marshal(list.Get(i).Message().Interface())
+				if err != nil {
+					return nil, err
+				}
+				elems = append(elems, elem)
+			}
+			return append(append([]byte("["), bytes.Join(elems, []byte(","))...), ']'), nil
+		}
+	}
+	// scalars are written as encoding/json does, e.g. enums as numbers and bytes as base64
+	return func(msg protoreflect.Message) (interface{}, error) {
+		list := msg.Get(fd).List()
+		elems := make([]interface{}, 0, list.Len())
+		for i := 0; i < list.Len(); i++ {
+			if fd.Kind() == protoreflect.EnumKind {
+				elems = append(elems, int32(list.Get(i).Enum()))
+			} else {
+				elems = append(elems, list.Get(i).Interface())
+			}
+		}
+		return json.Marshal(elems)
+	}
+}
+
+// the column is read as the field of a JSON object by protojson, which accepts both JSON written by
+// jsonEncoder and by helpers
+func jsonDecoder(fd protoreflect.FieldDescriptor) func(protoreflect.Message, interface{}) error {
+	return func(msg protoreflect.Message, v interface{}) error {
+		b, ok := text(v)
+		if !ok {
+			return fmt.Errorf("column of %s is %T, not JSON", fd.FullName(), v)
+		}
+		var object bytes.Buffer
+		object.WriteString(`{"`)
+		object.WriteString(string(fd.Name()))
+		object.WriteString(`":`)
+		object.WriteString(b)
+		object.WriteString("}")
+		tmp := msg.New()
+		if err := (protojson.UnmarshalOptions{DiscardUnknown: true}).Unmarshal(object.Bytes(), tmp.Interface()); err != nil {
+			return fmt.Errorf("failed to read column of %s: %w", fd.FullName(), err)
+		}
+		if tmp.Has(fd) {
+			msg.Set(fd, tmp.Get(fd))
+		}
+		return nil
+	}
+}
+
+func enumNameDecoder(fd protoreflect.FieldDescriptor) func(protoreflect.Message, interface{}) error {
+	return func(msg protoreflect.Message, v interface{}) error {
+		name, ok := text(v)
+		if !ok {
+			return fmt.Errorf("column of %s is %T, not ENUM", fd.FullName(), v)
+		}
+		value := fd.Enum().Values().ByName(protoreflect.Name(name))
+		if value == nil {
+			return fmt.Errorf("value %s of %s is not defined in %s", name, fd.FullName(), fd.Enum().FullName())
+		}
+		msg.Set(fd, protoreflect.ValueOfEnum(value.Number()))
+		return nil
+	}
+}
+
+// drivers return numbers as int64, float64 or their text in []byte
+func scalarDecoder(fd protoreflect.FieldDescriptor) func(protoreflect.Message, interface{}) error {
+	return func(msg protoreflect.Message, v interface{}) error {
+		value, err := scalarValue(fd, v)
+		if err != nil {
+			return fmt.Errorf("failed to read column of %s: %w", fd.FullName(), err)
+		}
+		msg.Set(fd, value)
+		return nil
+	}
+}
+
+func scalarValue(fd protoreflect.FieldDescriptor, v interface{}) (protoreflect.Value, error) {
+	s, isText := text(v)
+	switch fd.Kind() {
+	case protoreflect.StringKind:
+		if t, ok := v.(time.Time); ok {
+			return protoreflect.ValueOfString(t.Format(time.RFC3339Nano)), nil
+		}
+		if !isText {
+			s = fmt.Sprint(v)
+		}
+		return protoreflect.ValueOfString(s), nil
+	case protoreflect.BytesKind:
+		if b, ok := v.([]byte); ok {
+			return protoreflect.ValueOfBytes(b), nil
+		}
+		if isText {
+			return protoreflect.ValueOfBytes([]byte(s)), nil
+		}
+	case protoreflect.BoolKind:
+		switch b := v.(type) {
+		case bool:
+			return protoreflect.ValueOfBool(b), nil
+		case int64:
+			return protoreflect.ValueOfBool(b != 0), nil
+		}
+		if isText {
+			b, err := strconv.ParseBool(s)
+			return protoreflect.ValueOfBool(b), err
+		}
+	case protoreflect.FloatKind, protoreflect.DoubleKind:
+		var f float64
+		var err error
+		switch n := v.(type) {
+		case float64:
+			f = n
+		case float32:
+			f = float64(n)
+		case int64:
+			f = float64(n)
+		default:
+			if !isText {
+				return protoreflect.Value{}, fmt.Errorf("%T cannot be read as %s", v, fd.Kind())
+			}
+			f, err = strconv.ParseFloat(s, 64)
+		}
+		if fd.Kind() == protoreflect.FloatKind {
+			return protoreflect.ValueOfFloat32(float32(f)), err
+		}
+		return protoreflect.ValueOfFloat64(f), err
+	case protoreflect.Uint32Kind, protoreflect.Fixed32Kind, protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		var n uint64
+		var err error
+		switch i := v.(type) {
+		case int64:
+			n = uint64(i)
+		case uint64:
+			n = i
+		default:
+			if !isText {
+				return protoreflect.Value{}, fmt.Errorf("%T cannot be read as %s", v, fd.Kind())
+			}
+			n, err = strconv.ParseUint(s, 10, 64)
+		}
+		if fd.Kind() == protoreflect.Uint32Kind || fd.Kind() == protoreflect.Fixed32Kind {
+			return protoreflect.ValueOfUint32(uint32(n)), err
+		}
+		return protoreflect.ValueOfUint64(n), err
+	default:
+		var n int64
+		var err error
+		switch i := v.(type) {
+		case int64:
+			n = i
+		case bool:
+			if i {
+				n = 1
+			}
+		default:
+			if !isText {
+				return protoreflect.Value{}, fmt.Errorf("%T cannot be read as %s", v, fd.Kind())
+			}
+			n, err = strconv.ParseInt(s, 10, 64)
+		}
+		switch fd.Kind() {
+		case protoreflect.EnumKind:
+			return protoreflect.ValueOfEnum(protoreflect.EnumNumber(n)), err
+		case protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind:
+			return protoreflect.ValueOfInt64(n), err
+		default:
+			return protoreflect.ValueOfInt32(int32(n)), err
+		}
+	}
+	return protoreflect.Value{}, fmt.Errorf("%T cannot be read as %s", v, fd.Kind())
+}
+
+func text(v interface{}) (string, bool) {
+	switch s := v.(type) {
+	case []byte:
+		return string(s), true
+	case string:
+		return s, true
+	}
+	return "", false
+}
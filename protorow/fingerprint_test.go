@@ -0,0 +1,65 @@
+package protorow
+
+import (
+	"testing"
+
+	"github.com/Mojashi/proto-mysql/dep"
+	"github.com/Mojashi/proto-mysql/gensql"
+	"github.com/golang/protobuf/protoc-gen-go/descriptor"
+	plugin "github.com/golang/protobuf/protoc-gen-go/plugin"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+// file descriptor as protoc passes it to the plugin, which has been through the wire
+func wireFile(t *testing.T, fd protoreflect.FileDescriptor) *descriptor.FileDescriptorProto {
+	t.Helper()
+	buf, err := proto.Marshal(protodesc.ToFileDescriptorProto(fd))
+	if err != nil {
+		t.Fatal(err)
+	}
+	f := &descriptor.FileDescriptorProto{}
+	if err := proto.Unmarshal(buf, f); err != nil {
+		t.Fatal(err)
+	}
+	return f
+}
+
+// MySQLTable of mySQLOptions.proto refers to messages and enums, and is compiled into gensql
+func TestFingerprintSameAsPlugin(t *testing.T) {
+	msgs := []proto.Message{&gensql.MySQLTable{}, &gensql.MySQLPartition{}, &gensql.MySQLColumn{}}
+	f := wireFile(t, gensql.File_mySQLOptions_proto)
+	req := &plugin.CodeGeneratorRequest{
+		FileToGenerate: []string{f.GetName()},
+		ProtoFile:      []*descriptor.FileDescriptorProto{wireFile(t, descriptorpb.File_google_protobuf_descriptor_proto), f},
+	}
+	opts := gensql.Options{Fingerprint: true}
+	s, err := gensql.BuildSchema(dep.AnalyzeDependency(req, f), f, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m, err := NewMapper(opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, msg := range msgs {
+		name := string(msg.ProtoReflect().Descriptor().FullName())
+		t.Run(name, func(t *testing.T) {
+			fingerprint, err := m.Fingerprint(msg)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, table := range s.Tables {
+				if table.Message == name {
+					if table.Fingerprint != fingerprint {
+						t.Errorf("fingerprint = %s, want %s of the plugin", fingerprint, table.Fingerprint)
+					}
+					return
+				}
+			}
+			t.Fatalf("table of %s is not generated", name)
+		})
+	}
+}
@@ -0,0 +1,276 @@
+// Package protorow inserts proto messages into the tables generated by protoc-gen-mysql, and scans rows back,
+// through protoreflect without generated code. columns are mapped in the same way as gensql.
+package protorow
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/Mojashi/proto-mysql/gensql"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// MySQL rejects a statement with more placeholders
+const maxPlaceholders = 65535
+
+// *sql.DB, *sql.Tx and *sql.Conn
+type Execer interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+}
+
+type Queryer interface {
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+// how a message is written to and read from its table. built once for each message type
+type plan struct {
+	table *gensql.Table
+	// columns of INSERT, which are the columns of fields and PROTO_BINARY.
+	// auto-increment, audit and soft-delete columns are filled by the database
+	insert []*column
+	// columns which Scan reads, by lower-case name
+	scan      map[string]*column
+	insertSQL string
+}
+
+// Mapper caches the plan of each message type. options must be the same as those given to protoc-gen-mysql.
+// it is safe for concurrent use
+type Mapper struct {
+	opts  gensql.Options
+	plans sync.Map
+}
+
+// dialect mysql and mariadb are supported, as placeholders and conversions are those of MySQL
+func NewMapper(opts gensql.Options) (*Mapper, error) {
+	switch opts.Dialect {
+	case gensql.DialectMySQL, gensql.DialectMariaDB, "":
+		return &Mapper{opts: opts}, nil
+	default:
+		return nil, fmt.Errorf("protorow does not support dialect %s", opts.Dialect)
+	}
+}
+
+func (m *Mapper) plan(md protoreflect.MessageDescriptor) (*plan, error) {
+	if p, ok := m.plans.Load(md.FullName()); ok {
+		return p.(*plan), nil
+	}
+	table, err := gensql.BuildTableFromMessageDescriptor(md, m.opts)
+	if err != nil {
+		return nil, err
+	}
+	p := &plan{table: table, scan: map[string]*column{}}
+	for _, c := range table.Columns {
+		var col *column
+		switch {
+		case c.Field != nil:
+			fd := md.Fields().ByNumber(protoreflect.FieldNumber(c.Field.Number))
+			if fd == nil {
+				return nil, fmt.Errorf("field %s of column %s is not found", c.Field.FullName, c.Name)
+			}
+			if col, err = newFieldColumn(c, fd); err != nil {
+				return nil, err
+			}
+		case c.Name == gensql.ProtoBinaryColumn:
+			col = newProtoBinaryColumn()
+		default:
+			continue
+		}
+		p.scan[strings.ToLower(c.Name)] = col
+		if !c.AutoIncrement {
+			p.insert = append(p.insert, col)
+		}
+	}
+	p.insertSQL = fmt.Sprintf("INSERT INTO %s (%s) VALUES ", table.Name, strings.Join(p.columnNames(), ","))
+
+	actual, _ := m.plans.LoadOrStore(md.FullName(), p)
+	return actual.(*plan), nil
+}
+
+func (p *plan) columnNames() []string {
+	names := make([]string, 0, len(p.insert))
+	for _, c := range p.insert {
+		names = append(names, c.name)
+	}
+	return names
+}
+
+// table of the message
+func (m *Mapper) Table(msg proto.Message) (*gensql.Table, error) {
+	p, err := m.plan(msg.ProtoReflect().Descriptor())
+	if err != nil {
+		return nil, err
+	}
+	return p.table, nil
+}
+
+// columns of Row
+func (m *Mapper) Columns(msg proto.Message) ([]string, error) {
+	p, err := m.plan(msg.ProtoReflect().Descriptor())
+	if err != nil {
+		return nil, err
+	}
+	return p.columnNames(), nil
+}
+
+// INSERT statement of rows messages
+func (m *Mapper) InsertSQL(msg proto.Message, rows int) (string, error) {
+	p, err := m.plan(msg.ProtoReflect().Descriptor())
+	if err != nil {
+		return "", err
+	}
+	return p.genInsertSQL(rows), nil
+}
+
+func (p *plan) genInsertSQL(rows int) string {
+	row := "(" + strings.Repeat("?,", len(p.insert)-1) + "?)"
+	var b strings.Builder
+	b.Grow(len(p.insertSQL) + rows*(len(row)+1))
+	b.WriteString(p.insertSQL)
+	for i := 0; i < rows; i++ {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		b.WriteString(row)
+	}
+	return b.String()
+}
+
+// values of Columns. unset optional fields are NULL
+func (m *Mapper) Row(msg proto.Message) ([]interface{}, error) {
+	p, err := m.plan(msg.ProtoReflect().Descriptor())
+	if err != nil {
+		return nil, err
+	}
+	return p.appendRow(nil, msg.ProtoReflect())
+}
+
+func (p *plan) appendRow(row []interface{}, msg protoreflect.Message) ([]interface{}, error) {
+	for _, c := range p.insert {
+		v, err := c.encode(msg)
+		if err != nil {
+			return nil, err
+		}
+		row = append(row, v)
+	}
+	return row, nil
+}
+
+// insert messages of the same type with multi-row INSERT.
+// statements are split so that each has at most 65535 placeholders, and they are not run in a transaction
+func (m *Mapper) Insert(ctx context.Context, db Execer, msgs ...proto.Message) error {
+	if len(msgs) == 0 {
+		return nil
+	}
+	md := msgs[0].ProtoReflect().Descriptor()
+	p, err := m.plan(md)
+	if err != nil {
+		return err
+	}
+	batch := maxPlaceholders / len(p.insert)
+	for start := 0; start < len(msgs); start += batch {
+		end := start + batch
+		if end > len(msgs) {
+			end = len(msgs)
+		}
+		args := make([]interface{}, 0, (end-start)*len(p.insert))
+		for _, msg := range msgs[start:end] {
+			r := msg.ProtoReflect()
+			if r.Descriptor().FullName() != md.FullName() {
+				return fmt.Errorf("message %s is inserted with %s", r.Descriptor().FullName(), md.FullName())
+			}
+			if args, err = p.appendRow(args, r); err != nil {
+				return err
+			}
+		}
+		if _, err := db.ExecContext(ctx, p.genInsertSQL(end-start), args...); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SELECT of the table without condition. only PROTO_BINARY if binary, or the columns of fields
+func (m *Mapper) SelectSQL(msg proto.Message, binary bool) (string, error) {
+	p, err := m.plan(msg.ProtoReflect().Descriptor())
+	if err != nil {
+		return "", err
+	}
+	if binary {
+		return fmt.Sprintf("SELECT %s FROM %s", gensql.ProtoBinaryColumn, p.table.Name), nil
+	}
+	names := []string{}
+	for _, c := range p.table.Columns {
+		if c.Field != nil {
+			names = append(names, c.Name)
+		}
+	}
+	return fmt.Sprintf("SELECT %s FROM %s", strings.Join(names, ","), p.table.Name), nil
+}
+
+// read the current row into msg. PROTO_BINARY is used if it is selected and not NULL,
+// otherwise the message is built from the columns of fields. other columns are ignored
+func (m *Mapper) Scan(rows *sql.Rows, msg proto.Message) error {
+	p, err := m.plan(msg.ProtoReflect().Descriptor())
+	if err != nil {
+		return err
+	}
+	names, err := rows.Columns()
+	if err != nil {
+		return err
+	}
+	values := make([]interface{}, len(names))
+	dest := make([]interface{}, len(names))
+	for i := range values {
+		dest[i] = &values[i]
+	}
+	if err := rows.Scan(dest...); err != nil {
+		return err
+	}
+
+	proto.Reset(msg)
+	r := msg.ProtoReflect()
+	for i, name := range names {
+		if strings.EqualFold(name, gensql.ProtoBinaryColumn) && values[i] != nil {
+			return p.scan[strings.ToLower(name)].decode(r, values[i])
+		}
+	}
+	for i, name := range names {
+		c, ok := p.scan[strings.ToLower(name)]
+		if !ok || c.field == nil || values[i] == nil {
+			continue
+		}
+		if err := c.decode(r, values[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// same as the fingerprint which protoc-gen-mysql records with fingerprint=true
+func (m *Mapper) Fingerprint(msg proto.Message) (string, error) {
+	p, err := m.plan(msg.ProtoReflect().Descriptor())
+	if err != nil {
+		return "", err
+	}
+	return p.table.Fingerprint, nil
+}
+
+// return error unless the database records the fingerprint of msg, e.g. at startup
+func (m *Mapper) CheckFingerprint(ctx context.Context, db Queryer, msg proto.Message) error {
+	p, err := m.plan(msg.ProtoReflect().Descriptor())
+	if err != nil {
+		return err
+	}
+	var recorded string
+	if err := db.QueryRowContext(ctx, gensql.FingerprintSelectSQL(p.table.Name)).Scan(&recorded); err != nil {
+		return fmt.Errorf("failed to read fingerprint of table %s: %w", p.table.Name, err)
+	}
+	if recorded != p.table.Fingerprint {
+		return fmt.Errorf("table %s was migrated from another descriptor of %s", p.table.Name, p.table.Message)
+	}
+	return nil
+}
@@ -0,0 +1,422 @@
+package protorow
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Mojashi/proto-mysql/gensql"
+	"google.golang.org/protobuf/encoding/prototext"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+// messages are built with dynamicpb, so that protorow is tested without generated code as in applications
+const testFile = `
+	name: "item.proto"
+	package: "Foo"
+	syntax: "proto3"
+	message_type {
+		name: "Item"
+		field { name: "id" number: 1 type: TYPE_INT64 label: LABEL_OPTIONAL json_name: "id" }
+		field { name: "name" number: 2 type: TYPE_STRING label: LABEL_OPTIONAL json_name: "name" }
+		field { name: "data" number: 3 type: TYPE_BYTES label: LABEL_OPTIONAL json_name: "data" }
+		field { name: "price" number: 4 type: TYPE_DOUBLE label: LABEL_OPTIONAL json_name: "price" }
+		field { name: "stock" number: 5 type: TYPE_UINT32 label: LABEL_OPTIONAL json_name: "stock" }
+		field { name: "sold" number: 6 type: TYPE_BOOL label: LABEL_OPTIONAL json_name: "sold" }
+		field { name: "kind" number: 7 type: TYPE_ENUM type_name: ".Foo.Kind" label: LABEL_OPTIONAL json_name: "kind" }
+		field { name: "tags" number: 8 type: TYPE_STRING label: LABEL_REPEATED json_name: "tags" }
+		field { name: "maker" number: 9 type: TYPE_MESSAGE type_name: ".Foo.Maker" label: LABEL_OPTIONAL json_name: "maker" }
+		field { name: "labels" number: 10 type: TYPE_MESSAGE type_name: ".Foo.Item.LabelsEntry" label: LABEL_REPEATED json_name: "labels" }
+		field { name: "released_at" number: 11 type: TYPE_STRING label: LABEL_OPTIONAL json_name: "releasedAt" options { [mySQLType] { typeName: "DATETIME" args: ["6"] } } }
+		field { name: "discount" number: 12 type: TYPE_INT32 label: LABEL_OPTIONAL json_name: "discount" proto3_optional: true oneof_index: 0 }
+		nested_type {
+			name: "LabelsEntry"
+			field { name: "key" number: 1 type: TYPE_STRING label: LABEL_OPTIONAL json_name: "key" }
+			field { name: "value" number: 2 type: TYPE_INT32 label: LABEL_OPTIONAL json_name: "value" }
+			options { map_entry: true }
+		}
+		oneof_decl { name: "_discount" }
+		options { [mySQLTable] { primaryKey: ["id"] } }
+	}
+	message_type {
+		name: "Maker"
+		field { name: "name" number: 1 type: TYPE_STRING label: LABEL_OPTIONAL json_name: "name" }
+	}
+	enum_type { name: "Kind" value { name: "BOOK" number: 0 } value { name: "GAME" number: 1 } }`
+
+var (
+	testFileOnce       sync.Once
+	testFileDescriptor protoreflect.FileDescriptor
+	testFileErr        error
+)
+
+// the file is built once, as mappers cache plans by message name like compiled-in messages
+func testMessage(t *testing.T, name protoreflect.Name) protoreflect.MessageDescriptor {
+	t.Helper()
+	testFileOnce.Do(func() {
+		fdp := &descriptorpb.FileDescriptorProto{}
+		if testFileErr = prototext.Unmarshal([]byte(testFile), fdp); testFileErr != nil {
+			return
+		}
+		testFileDescriptor, testFileErr = protodesc.NewFile(fdp, nil)
+	})
+	if testFileErr != nil {
+		t.Fatal(testFileErr)
+	}
+	return testFileDescriptor.Messages().ByName(name)
+}
+
+func testItem(t *testing.T, text string) *dynamicpb.Message {
+	t.Helper()
+	msg := dynamicpb.NewMessage(testMessage(t, "Item"))
+	if err := prototext.Unmarshal([]byte(text), msg); err != nil {
+		t.Fatal(err)
+	}
+	return msg
+}
+
+// database of the fake driver. statements are recorded, and queries return rows
+type fakeDB struct {
+	mu      sync.Mutex
+	execs   []fakeExec
+	columns []string
+	rows    [][]driver.Value
+}
+
+type fakeExec struct {
+	query string
+	args  []driver.Value
+}
+
+var fakeDBs sync.Map
+
+type fakeDriver struct{}
+
+func (fakeDriver) Open(name string) (driver.Conn, error) {
+	db, ok := fakeDBs.Load(name)
+	if !ok {
+		return nil, fmt.Errorf("database %s is not found", name)
+	}
+	return &fakeConn{db.(*fakeDB)}, nil
+}
+
+type fakeConn struct{ db *fakeDB }
+
+func (c *fakeConn) Prepare(query string) (driver.Stmt, error) { return &fakeStmt{c.db, query}, nil }
+func (c *fakeConn) Close() error                              { return nil }
+func (c *fakeConn) Begin() (driver.Tx, error) {
+	return nil, fmt.Errorf("transactions are not supported")
+}
+
+type fakeStmt struct {
+	db    *fakeDB
+	query string
+}
+
+func (s *fakeStmt) Close() error  { return nil }
+func (s *fakeStmt) NumInput() int { return -1 }
+
+func (s *fakeStmt) Exec(args []driver.Value) (driver.Result, error) {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+	s.db.execs = append(s.db.execs, fakeExec{s.query, args})
+	return driver.RowsAffected(0), nil
+}
+
+func (s *fakeStmt) Query(args []driver.Value) (driver.Rows, error) {
+	return &fakeRows{columns: s.db.columns, rows: s.db.rows}, nil
+}
+
+type fakeRows struct {
+	columns []string
+	rows    [][]driver.Value
+}
+
+func (r *fakeRows) Columns() []string { return r.columns }
+func (r *fakeRows) Close() error      { return nil }
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if len(r.rows) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.rows[0])
+	r.rows = r.rows[1:]
+	return nil
+}
+
+func init() {
+	sql.Register("protorow-fake", fakeDriver{})
+}
+
+func openFakeDB(t *testing.T, db *fakeDB) *sql.DB {
+	t.Helper()
+	fakeDBs.Store(t.Name(), db)
+	conn, err := sql.Open("protorow-fake", t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		conn.Close()
+		fakeDBs.Delete(t.Name())
+	})
+	return conn
+}
+
+func TestNewMapper(t *testing.T) {
+	tests := []struct {
+		dialect gensql.Dialect
+		err     bool
+	}{
+		{dialect: ""},
+		{dialect: gensql.DialectMySQL},
+		{dialect: gensql.DialectMariaDB},
+		{dialect: gensql.DialectPostgreSQL, err: true},
+		{dialect: gensql.DialectSQLite, err: true},
+		{dialect: gensql.DialectClickHouse, err: true},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.dialect), func(t *testing.T) {
+			if _, err := NewMapper(gensql.Options{Dialect: tt.dialect}); (err != nil) != tt.err {
+				t.Errorf("error = %v, want error %v", err, tt.err)
+			}
+		})
+	}
+}
+
+func TestRow(t *testing.T) {
+	m, err := NewMapper(gensql.Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	msg := testItem(t, `id: 1 name: "a" kind: GAME tags: ["x", "y"] maker { name: "m" } labels { key: "k" value: 2 } discount: 0`)
+	columns, err := m.Columns(msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	row, err := m.Row(msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{
+		"id":          "1",
+		"name":        "a",
+		"kind":        "GAME",
+		"tags":        `["x","y"]`,
+		"maker":       `{"name":"m"}`,
+		"labels":      `{"k":2}`,
+		"released_at": "",
+		"discount":    "0",
+	}
+	for i, name := range columns {
+		w, ok := want[name]
+		if !ok {
+			continue
+		}
+		got := fmt.Sprint(row[i])
+		if b, ok := row[i].([]byte); ok {
+			got = strings.Join(strings.Fields(string(b)), "")
+		}
+		if got != w {
+			t.Errorf("%s = %s, want %s", name, got, w)
+		}
+	}
+	// unset optional field is NULL
+	msg = testItem(t, `id: 1`)
+	if row, err = m.Row(msg); err != nil {
+		t.Fatal(err)
+	}
+	for i, name := range columns {
+		if name == "discount" && row[i] != nil {
+			t.Errorf("discount = %v, want NULL", row[i])
+		}
+		if name == "labels" && string(row[i].([]byte)) != "{}" {
+			t.Errorf("labels = %s, want {}", row[i])
+		}
+	}
+}
+
+// value as MySQL drivers return it. the text protocol returns every value as []byte,
+// and the binary protocol returns integers as int64. DATETIME is time.Time with parseTime
+func driverValue(v interface{}, textProtocol bool, column string) driver.Value {
+	if column == "released_at" {
+		s := v.(string)
+		if textProtocol {
+			return []byte(s)
+		}
+		t, err := time.Parse(time.RFC3339Nano, s)
+		if err != nil {
+			panic(err)
+		}
+		return t
+	}
+	switch v := v.(type) {
+	case nil:
+		return nil
+	case string:
+		return []byte(v)
+	case []byte:
+		return v
+	case bool:
+		if v {
+			return driverValue(int64(1), textProtocol, column)
+		}
+		return driverValue(int64(0), textProtocol, column)
+	case int64:
+		if textProtocol {
+			return []byte(strconv.FormatInt(v, 10))
+		}
+		return v
+	case uint64:
+		if textProtocol {
+			return []byte(strconv.FormatUint(v, 10))
+		}
+		return int64(v)
+	case float64:
+		if textProtocol {
+			return []byte(strconv.FormatFloat(v, 'g', -1, 64))
+		}
+		return v
+	}
+	panic(fmt.Sprintf("unexpected value %T", v))
+}
+
+func TestScan(t *testing.T) {
+	const item = `id: 7 name: "a" data: "\x00\xff" price: 1.5 stock: 3 sold: true kind: GAME tags: ["x"]
+		maker { name: "m" } labels { key: "k" value: 2 } released_at: "2024-01-02T03:04:05.123456Z" discount: 10`
+	tests := []struct {
+		name         string
+		item         string
+		textProtocol bool
+		binary       bool
+	}{
+		{name: "binary protocol", item: item},
+		{name: "text protocol", item: item, textProtocol: true},
+		// message columns are NOT NULL, so an unset message is read as an empty one
+		{name: "unset fields", item: `id: 7 maker {} released_at: "2024-01-02T03:04:05Z"`},
+		{name: "PROTO_BINARY", item: item, binary: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, err := NewMapper(gensql.Options{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			msg := testItem(t, tt.item)
+			columns, err := m.Columns(msg)
+			if err != nil {
+				t.Fatal(err)
+			}
+			row, err := m.Row(msg)
+			if err != nil {
+				t.Fatal(err)
+			}
+			db := &fakeDB{}
+			values := []driver.Value{}
+			for i, name := range columns {
+				if (name == gensql.ProtoBinaryColumn) != tt.binary {
+					continue
+				}
+				db.columns = append(db.columns, name)
+				values = append(values, driverValue(row[i], tt.textProtocol, name))
+			}
+			db.rows = [][]driver.Value{values}
+
+			rows, err := openFakeDB(t, db).QueryContext(context.Background(), "SELECT")
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer rows.Close()
+			if !rows.Next() {
+				t.Fatal("no row")
+			}
+			got := dynamicpb.NewMessage(msg.Descriptor())
+			if err := m.Scan(rows, got); err != nil {
+				t.Fatal(err)
+			}
+			if !proto.Equal(got, msg) {
+				t.Errorf("scanned %v, want %v", got, msg)
+			}
+		})
+	}
+}
+
+func TestInsert(t *testing.T) {
+	m, err := NewMapper(gensql.Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	maker := testMessage(t, "Maker")
+	columns, err := m.Columns(dynamicpb.NewMessage(maker))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// rows of a statement
+	batch := maxPlaceholders / len(columns)
+	tests := []struct {
+		name       string
+		messages   int
+		statements []int
+	}{
+		{name: "none", messages: 0, statements: []int{}},
+		{name: "one", messages: 1, statements: []int{1}},
+		{name: "full statement", messages: batch, statements: []int{batch}},
+		{name: "split", messages: 2*batch + 5, statements: []int{batch, batch, 5}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			msgs := make([]proto.Message, 0, tt.messages)
+			for i := 0; i < tt.messages; i++ {
+				msg := dynamicpb.NewMessage(maker)
+				msg.Set(maker.Fields().ByName("name"), protoreflect.ValueOfString(strconv.Itoa(i)))
+				msgs = append(msgs, msg)
+			}
+			db := &fakeDB{}
+			if err := m.Insert(context.Background(), openFakeDB(t, db), msgs...); err != nil {
+				t.Fatal(err)
+			}
+			if len(db.execs) != len(tt.statements) {
+				t.Fatalf("statements = %d, want %d", len(db.execs), len(tt.statements))
+			}
+			inserted := 0
+			for i, e := range db.execs {
+				if rows := len(e.args) / len(columns); rows != tt.statements[i] {
+					t.Errorf("rows of statement %d = %d, want %d", i, rows, tt.statements[i])
+				}
+				if placeholders := strings.Count(e.query, "?"); placeholders != len(e.args) || placeholders > maxPlaceholders {
+					t.Errorf("placeholders of statement %d = %d, args %d", i, placeholders, len(e.args))
+				}
+				// rows keep the order of messages
+				if name := e.args[0].(string); name != strconv.Itoa(inserted) {
+					t.Errorf("first row of statement %d is %s, want %d", i, name, inserted)
+				}
+				inserted += len(e.args) / len(columns)
+			}
+		})
+	}
+}
+
+func TestInsertOtherMessage(t *testing.T) {
+	m, err := NewMapper(gensql.Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	db := &fakeDB{}
+	err = m.Insert(context.Background(), openFakeDB(t, db), dynamicpb.NewMessage(testMessage(t, "Maker")), testItem(t, `id: 1`))
+	if err == nil || !strings.Contains(err.Error(), "message Foo.Item is inserted with Foo.Maker") {
+		t.Errorf("error = %v", err)
+	}
+	if len(db.execs) != 0 {
+		t.Errorf("%d statements are run", len(db.execs))
+	}
+}